@@ -1,7 +1,9 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"os"
 
 	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/config"
 	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/handler"
@@ -33,21 +35,59 @@ func main() {
 	// репо
 	userRepo := repository.NewUserRepo(dbConn, logger.Sugar)
 	teamRepo := repository.NewTeamRepo(dbConn, logger.Sugar)
-	prRepo := repository.NewPullRequestRepo(dbConn)
+	prRepo := repository.NewPullRequestRepo(dbConn, logger.Sugar)
+	ownershipRepo := repository.NewOwnershipRepo(dbConn, logger.Sugar)
+	absenceRepo := repository.NewAbsenceRepo(dbConn, logger.Sugar)
+	exclusionRepo := repository.NewExclusionRepo(dbConn, logger.Sugar)
+	snapshotRepo := repository.NewSnapshotRepo(dbConn, logger.Sugar)
+	identityRepo := repository.NewIdentityRepo(dbConn, logger.Sugar)
+	deliveryRepo := repository.NewDeliveryRepo(dbConn, logger.Sugar)
+
+	// стратегии выбора ревьюверов
+	selectors, err := service.NewReviewerSelectors(cfg.ReviewerStrategy, cfg.ReviewerSeed, prRepo)
+	if err != nil {
+		logger.Sugar.Fatalf("failed to init reviewer selectors: %v", err)
+	}
 
 	// сервисы
 	userService := service.NewUserService(userRepo, logger.Sugar)
 	teamService := service.NewTeamService(teamRepo, dbConn, logger.Sugar)
-	prService := service.NewPullRequestService(prRepo, userService, logger.Sugar)
+	ownershipService := service.NewOwnershipService(ownershipRepo, logger.Sugar)
+	prService := service.NewPullRequestService(prRepo, userService, teamService, ownershipService, exclusionRepo, selectors, logger.Sugar)
+	absenceService := service.NewAbsenceService(absenceRepo, userService, prService, logger.Sugar)
+	exclusionService := service.NewExclusionService(exclusionRepo, userService, prService, logger.Sugar)
+	snapshotService := service.NewSnapshotService(snapshotRepo, logger.Sugar)
+	githubService := service.NewGitHubService(identityRepo, deliveryRepo, userService, prService, cfg.GitHubWebhookSecret, logger.Sugar)
+	slaService := service.NewReviewSLAService(prRepo, prService, logger.Sugar)
+	lifecycleService := service.NewUserLifecycleService(dbConn, userRepo, prRepo, teamService, prService, logger.Sugar)
+
+	// подкоманды обслуживания (app snapshot, app restore) выполняются без запуска сервера
+	if len(os.Args) > 1 {
+		if err := runCommand(os.Args[1], os.Args[2:], snapshotService); err != nil {
+			logger.Sugar.Fatalf("command %s failed: %v", os.Args[1], err)
+		}
+		return
+	}
+
+	// фоновые проверки: начавшиеся отсутствия и просроченные ревью
+	go absenceService.Run(context.Background(), cfg.AbsenceCheckInterval)
+	go slaService.Run(context.Background(), cfg.SLACheckInterval)
 
 	// хэндлеры
 	userHandler := handler.NewUserHandler(userService, logger.Sugar)
 	teamHandler := handler.NewTeamHandler(teamService, prService, userService, logger.Sugar)
 	prHandler := handler.NewPullRequestHandler(prService, logger.Sugar)
 	statsHandler := handler.NewStatsHandler(prService, userService, teamService, logger.Sugar)
+	ownershipHandler := handler.NewOwnershipHandler(ownershipService, logger.Sugar)
+	absenceHandler := handler.NewAbsenceHandler(absenceService, logger.Sugar)
+	reviewHandler := handler.NewReviewHandler(slaService, logger.Sugar)
+	lifecycleHandler := handler.NewUserLifecycleHandler(lifecycleService, logger.Sugar)
+	exclusionHandler := handler.NewExclusionHandler(exclusionService, logger.Sugar)
+	snapshotHandler := handler.NewSnapshotHandler(snapshotService, logger.Sugar)
+	githubHandler := handler.NewGitHubHandler(githubService, logger.Sugar)
 
 	// роутер
-	router := router.NewRouter(userHandler, teamHandler, prHandler, statsHandler)
+	router := router.NewRouter(userHandler, teamHandler, prHandler, statsHandler, ownershipHandler, absenceHandler, reviewHandler, lifecycleHandler, exclusionHandler, snapshotHandler, githubHandler)
 
 	// запуск сервера
 	addr := fmt.Sprintf(":%s", cfg.Port)
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/domain"
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/service"
+)
+
+// runCommand выполняет подкоманду обслуживания вместо запуска сервера:
+//
+//	app snapshot [-o snapshot.json]  - выгрузить снимок (по умолчанию в stdout)
+//	app restore [-i snapshot.json]   - восстановить снимок (по умолчанию из stdin)
+func runCommand(name string, args []string, snapshots *service.SnapshotService) error {
+	switch name {
+	case "snapshot":
+		flags := flag.NewFlagSet("snapshot", flag.ContinueOnError)
+		output := flags.String("o", "-", "файл снимка, - для stdout")
+		if err := flags.Parse(args); err != nil {
+			return err
+		}
+		return exportSnapshot(*output, snapshots)
+
+	case "restore":
+		flags := flag.NewFlagSet("restore", flag.ContinueOnError)
+		input := flags.String("i", "-", "файл снимка, - для stdin")
+		if err := flags.Parse(args); err != nil {
+			return err
+		}
+		return restoreSnapshot(*input, snapshots)
+	}
+	return fmt.Errorf("unknown command %q, expected snapshot or restore", name)
+}
+
+// exportSnapshot пишет снимок в файл или stdout; ошибка закрытия файла тоже возвращается,
+// иначе недописанный снимок выглядел бы успешным
+func exportSnapshot(path string, snapshots *service.SnapshotService) (err error) {
+	snapshot, err := snapshots.Export()
+	if err != nil {
+		return err
+	}
+
+	out := io.Writer(os.Stdout)
+	if path != "-" {
+		file, createErr := os.Create(path)
+		if createErr != nil {
+			return createErr
+		}
+		defer func() {
+			if cerr := file.Close(); err == nil {
+				err = cerr
+			}
+		}()
+		out = file
+	}
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(snapshot)
+}
+
+// restoreSnapshot читает снимок из файла или stdin и восстанавливает его
+func restoreSnapshot(path string, snapshots *service.SnapshotService) error {
+	in := io.Reader(os.Stdin)
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		in = file
+	}
+
+	var snapshot domain.Snapshot
+	if err := json.NewDecoder(in).Decode(&snapshot); err != nil {
+		return fmt.Errorf("failed to decode snapshot: %w", err)
+	}
+	result, err := snapshots.Restore(&snapshot)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "restored %d teams, %d users, %d pull requests, %d reviewers, %d users retired\n",
+		result.Teams, result.Users, result.PullRequests, result.Reviewers, result.RetiredUsers)
+	return nil
+}
@@ -0,0 +1,142 @@
+package codeowners
+
+import (
+	"bufio"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/domain"
+)
+
+// Parse разбирает файл в формате CODEOWNERS: `паттерн @user @org/team ...`.
+// Владелец `@user` трактуется как user_id, `@org/team` - как имя команды team.
+func Parse(content string) ([]*domain.CodeOwnerRule, error) {
+	rules := []*domain.CodeOwnerRule{}
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+
+		// отбрасываем комментарии и пустые строки
+		line := scanner.Text()
+		if idx := strings.Index(line, "#"); idx >= 0 {
+			line = line[:idx]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		rule := &domain.CodeOwnerRule{
+			Pattern: fields[0],
+			Users:   []string{},
+			Teams:   []string{},
+		}
+		matcher, err := compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid pattern %q", lineNum, rule.Pattern)
+		}
+		rule.Matcher = matcher
+
+		for _, owner := range fields[1:] {
+			if !strings.HasPrefix(owner, "@") || len(owner) == 1 {
+				return nil, fmt.Errorf("line %d: unsupported owner %q", lineNum, owner)
+			}
+			owner = owner[1:]
+			if idx := strings.LastIndex(owner, "/"); idx >= 0 {
+				rule.Teams = append(rule.Teams, owner[idx+1:])
+			} else {
+				rule.Users = append(rule.Users, owner)
+			}
+		}
+		rules = append(rules, rule)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// Compile компилирует паттерны правил, прочитанных не через Parse (например, из БД),
+// чтобы Match не разбирал их на каждом пути; правила с некорректным паттерном пропускаются
+func Compile(rules []*domain.CodeOwnerRule) {
+	for _, rule := range rules {
+		if rule.Matcher != nil {
+			continue
+		}
+		if matcher, err := compile(rule.Pattern); err == nil {
+			rule.Matcher = matcher
+		}
+	}
+}
+
+// Match возвращает последнее подходящее под путь правило или nil, как в CODEOWNERS;
+// нескомпилированный паттерн разбирается на месте
+func Match(rules []*domain.CodeOwnerRule, path string) *domain.CodeOwnerRule {
+	path = strings.TrimPrefix(path, "/")
+	for i := len(rules) - 1; i >= 0; i-- {
+		re := rules[i].Matcher
+		if re == nil {
+			var err error
+			if re, err = compile(rules[i].Pattern); err != nil {
+				continue
+			}
+		}
+		if re.MatchString(path) {
+			return rules[i]
+		}
+	}
+	return nil
+}
+
+// compile переводит glob паттерн CODEOWNERS в регулярное выражение:
+// `/` в начале привязывает к корню, паттерн без `/` ищется на любой глубине,
+// `*` не переходит через `/`, `**` - любое число каталогов,
+// паттерн каталога совпадает со всем его содержимым
+func compile(pattern string) (*regexp.Regexp, error) {
+	anchored := strings.HasPrefix(pattern, "/")
+	body := strings.TrimPrefix(pattern, "/")
+	dirOnly := strings.HasSuffix(body, "/")
+	body = strings.TrimSuffix(body, "/")
+	if body == "" {
+		return nil, fmt.Errorf("empty pattern")
+	}
+
+	var sb strings.Builder
+	sb.WriteString("^")
+	if !anchored && !strings.Contains(body, "/") {
+		sb.WriteString("(.*/)?")
+	}
+
+	for i := 0; i < len(body); i++ {
+		switch {
+		case strings.HasPrefix(body[i:], "**/"):
+			sb.WriteString("(.*/)?")
+			i += 2
+		case strings.HasPrefix(body[i:], "**"):
+			sb.WriteString(".*")
+			i++
+		case body[i] == '*':
+			sb.WriteString("[^/]*")
+		case body[i] == '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(body[i])))
+		}
+	}
+
+	// каталог совпадает со всем содержимым, `dir/*` - только с файлами на первом уровне
+	lastSegment := body[strings.LastIndex(body, "/")+1:]
+	switch {
+	case dirOnly:
+		sb.WriteString("/.*")
+	case !strings.Contains(lastSegment, "*"):
+		sb.WriteString("(/.*)?")
+	}
+	sb.WriteString("$")
+
+	return regexp.Compile(sb.String())
+}
@@ -0,0 +1,152 @@
+package codeowners
+
+import (
+	"slices"
+	"testing"
+)
+
+// тестируем сопоставление пути с паттерном CODEOWNERS
+func TestMatchPattern(t *testing.T) {
+	cases := []struct {
+		name    string
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"корень_привязывает", "/docs", "docs/readme.md", true},
+		{"корень_не_ищет_вглубь", "/docs", "api/docs/readme.md", false},
+		{"без_слеша_на_любой_глубине", "docs", "api/docs/readme.md", true},
+		{"со_слешем_от_корня", "api/docs", "api/docs/readme.md", true},
+		{"со_слешем_не_ищет_вглубь", "api/docs", "x/api/docs/readme.md", false},
+		{"звездочка_в_имени", "*.go", "internal/service/a.go", true},
+		{"звездочка_не_переходит_каталог", "/cmd/*.go", "cmd/app/main.go", false},
+		{"звездочка_на_первом_уровне", "/cmd/*", "cmd/main.go", true},
+		{"звездочка_не_берет_вложенные", "/cmd/*", "cmd/app/main.go", false},
+		{"вопрос_один_символ", "/a?.txt", "ab.txt", true},
+		{"вопрос_не_слеш", "/a?.txt", "a/.txt", false},
+		{"двойная_звездочка_в_начале", "**/migrations", "db/sql/migrations/001.sql", true},
+		{"двойная_звездочка_ноль_каталогов", "**/migrations", "migrations/001.sql", true},
+		{"двойная_звездочка_в_середине", "/api/**/handler.go", "api/v1/users/handler.go", true},
+		{"двойная_звездочка_в_середине_без_каталогов", "/api/**/handler.go", "api/handler.go", true},
+		{"двойная_звездочка_в_конце", "/api/**", "api/v1/users/handler.go", true},
+		{"каталог_совпадает_с_содержимым", "/internal/", "internal/service/a.go", true},
+		{"каталог_не_совпадает_с_файлом", "/internal/", "internal", false},
+		{"файл_совпадает_как_каталог", "/internal", "internal", true},
+		{"точка_экранируется", "/a.go", "abgo", false},
+		{"префикс_имени_не_совпадает", "/doc", "docs/readme.md", false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			re, err := compile(c.pattern)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := re.MatchString(c.path); got != c.want {
+				t.Fatalf("pattern %q, path %q: expected %v, got %v (regexp %s)", c.pattern, c.path, c.want, got, re)
+			}
+		})
+	}
+}
+
+// тестируем выбор правила: побеждает последнее подходящее
+func TestMatch(t *testing.T) {
+	rules, err := Parse(`
+# общий владелец
+*            @u1
+/internal/   @org/backend
+*.md         @u2
+/internal/legacy/ # без владельцев
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cases := []struct {
+		name string
+		path string
+		want string
+	}{
+		{"общее_правило", "cmd/app/main.go", "*"},
+		{"каталог_перекрывает_общее", "internal/service/a.go", "/internal/"},
+		{"последнее_побеждает", "internal/README.md", "*.md"},
+		{"ведущий_слеш_пути", "/internal/service/a.go", "/internal/"},
+		{"правило_без_владельцев", "internal/legacy/a.go", "/internal/legacy/"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rule := Match(rules, c.path)
+			if rule == nil {
+				t.Fatalf("expected rule %q, got nil", c.want)
+			}
+			if rule.Pattern != c.want {
+				t.Fatalf("expected rule %q, got %q", c.want, rule.Pattern)
+			}
+		})
+	}
+
+	if rule := Match(rules[1:2], "cmd/main.go"); rule != nil {
+		t.Fatalf("expected no rule, got %q", rule.Pattern)
+	}
+}
+
+// тестируем сопоставление правил, прочитанных без Parse
+func TestCompile(t *testing.T) {
+	rules, err := Parse("/docs/ @u1\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rules[0].Matcher == nil {
+		t.Fatalf("expected Parse to compile pattern")
+	}
+
+	rules[0].Matcher = nil
+	if rule := Match(rules, "docs/a.md"); rule == nil {
+		t.Fatalf("expected uncompiled rule to match")
+	}
+	Compile(rules)
+	if rules[0].Matcher == nil {
+		t.Fatalf("expected Compile to compile pattern")
+	}
+}
+
+// тестируем разбор владельцев и ошибки формата
+func TestParse(t *testing.T) {
+	cases := []struct {
+		name    string
+		content string
+		users   []string
+		teams   []string
+		wantErr bool
+	}{
+		{"пользователи_и_команды", "/api/ @u1 @org/backend @u2", []string{"u1", "u2"}, []string{"backend"}, false},
+		{"вложенная_команда", "/api/ @org/platform/backend", []string{}, []string{"backend"}, false},
+		{"без_владельцев", "/api/", []string{}, []string{}, false},
+		{"комментарий_после_паттерна", "/api/ @u1 # @u2", []string{"u1"}, []string{}, false},
+		{"владелец_без_собачки", "/api/ u1", nil, nil, true},
+		{"пустой_владелец", "/api/ @", nil, nil, true},
+		{"пустой_паттерн", "/ @u1", nil, nil, true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rules, err := Parse(c.content)
+			if c.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got rules %v", rules)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(rules) != 1 {
+				t.Fatalf("expected 1 rule, got %d", len(rules))
+			}
+			if !slices.Equal(rules[0].Users, c.users) || !slices.Equal(rules[0].Teams, c.teams) {
+				t.Fatalf("expected users %v teams %v, got users %v teams %v", c.users, c.teams, rules[0].Users, rules[0].Teams)
+			}
+		})
+	}
+}
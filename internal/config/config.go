@@ -1,6 +1,10 @@
 package config
 
-import "os"
+import (
+	"os"
+	"strconv"
+	"time"
+)
 
 type Config struct {
 	Port       string
@@ -8,6 +12,19 @@ type Config struct {
 	DBUser     string
 	DBPassword string
 	DBName     string
+
+	// выбор ревьюверов
+	ReviewerStrategy string // first_n / random / round_robin / least_loaded
+	ReviewerSeed     int64  // seed для random, 0 - от текущего времени
+
+	// как часто проверять начавшиеся отсутствия
+	AbsenceCheckInterval time.Duration
+
+	// как часто искать ревью с нарушенным SLA
+	SLACheckInterval time.Duration
+
+	// секрет подписи вебхуков GitHub, пустой - прием вебхуков выключен
+	GitHubWebhookSecret string
 }
 
 // получение переменной с возвратом дефолтного значения
@@ -18,6 +35,24 @@ func getEnv(key, dflt string) string {
 	return dflt
 }
 
+// получение числовой переменной с возвратом дефолтного значения
+func getEnvInt64(key string, dflt int64) int64 {
+	value, err := strconv.ParseInt(os.Getenv(key), 10, 64)
+	if err != nil {
+		return dflt
+	}
+	return value
+}
+
+// получение длительности (например 30s, 5m) с возвратом дефолтного значения
+func getEnvDuration(key string, dflt time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return dflt
+	}
+	return value
+}
+
 // подгрузка конфигов
 func LoadConfig() *Config {
 	return &Config{
@@ -26,5 +61,13 @@ func LoadConfig() *Config {
 		DBUser:     getEnv("DB_USER", "pruser"),
 		DBPassword: getEnv("DB_PASSWORD", "prpass"),
 		DBName:     getEnv("DB_NAME", "prdb"),
+
+		ReviewerStrategy: getEnv("REVIEWER_STRATEGY", "least_loaded"),
+		ReviewerSeed:     getEnvInt64("REVIEWER_SEED", 0),
+
+		AbsenceCheckInterval: getEnvDuration("ABSENCE_CHECK_INTERVAL", time.Minute),
+		SLACheckInterval:     getEnvDuration("SLA_CHECK_INTERVAL", 5*time.Minute),
+
+		GitHubWebhookSecret: getEnv("GITHUB_WEBHOOK_SECRET", ""),
 	}
 }
@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+// период отсутствия пользователя, в течение которого он не назначается ревьювером
+type Absence struct {
+	ID        int64     `json:"absence_id"`
+	UserID    string    `json:"user_id"`
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+
+	ReviewsReassigned bool `json:"reviews_reassigned"` // открытые ревью уже переданы другим
+}
+
+// IsActiveAt проверяет, что отсутствие действует в момент t
+func (a *Absence) IsActiveAt(t time.Time) bool {
+	return !t.Before(a.StartsAt) && t.Before(a.EndsAt)
+}
@@ -0,0 +1,17 @@
+package domain
+
+import "regexp"
+
+// правило владения путями из CODEOWNERS
+type CodeOwnerRule struct {
+	Pattern string   `json:"pattern"`
+	Users   []string `json:"users"` // user_id владельцев
+	Teams   []string `json:"teams"` // команды владельцев
+
+	Matcher *regexp.Regexp `json:"-"` // скомпилированный паттерн, заполняет пакет codeowners
+}
+
+// HasOwners проверяет, что у правила есть владельцы
+func (r *CodeOwnerRule) HasOwners() bool {
+	return len(r.Users) > 0 || len(r.Teams) > 0
+}
@@ -0,0 +1,38 @@
+package domain
+
+import "time"
+
+// типы событий истории PR и пользователей
+const (
+	EventPRCreated          = "PR_CREATED"
+	EventReviewerAssigned   = "REVIEWER_ASSIGNED"
+	EventReviewerReassigned = "REVIEWER_REASSIGNED"
+	EventReviewerRemoved    = "REVIEWER_REMOVED"
+	EventReviewSubmitted    = "REVIEW_SUBMITTED"
+	EventReviewOverdue      = "REVIEW_OVERDUE"
+	EventLabelsChanged      = "LABELS_CHANGED"
+	EventPRReady            = "PR_READY"
+	EventPRMerged           = "PR_MERGED"
+	EventPRClosed           = "PR_CLOSED"
+	EventPRReopened         = "PR_REOPENED"
+	EventUserActivated      = "USER_ACTIVATED"
+	EventUserDeactivated    = "USER_DEACTIVATED"
+	EventUserTeamChanged    = "USER_TEAM_CHANGED"
+	EventUserCreated        = "USER_CREATED"
+	EventUserDeleted        = "USER_DELETED"
+	EventAuthorChanged      = "AUTHOR_CHANGED"
+	EventPROrphaned         = "PR_ORPHANED"
+	EventUserRoleChanged    = "USER_ROLE_CHANGED"
+	EventSnapshotRestored   = "SNAPSHOT_RESTORED"
+)
+
+// запись журнала pr_events
+type PREvent struct {
+	ID        int64          `json:"event_id"`
+	Type      string         `json:"event_type"`
+	PRID      string         `json:"pull_request_id,omitempty"`
+	UserID    string         `json:"user_id,omitempty"`     // ревьювер или пользователь, которого касается событие
+	OldUserID string         `json:"old_user_id,omitempty"` // прежний ревьювер при переназначении
+	Details   map[string]any `json:"details,omitempty"`     // вердикт, метки, ревьюверы на момент merge, смена команды
+	CreatedAt time.Time      `json:"created_at"`
+}
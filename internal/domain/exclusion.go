@@ -0,0 +1,11 @@
+package domain
+
+import "time"
+
+// правило конфликта интересов: ReviewerID никогда не назначается ревьювером PR автора AuthorID
+type ReviewExclusion struct {
+	ReviewerID string    `json:"reviewer_id"`
+	AuthorID   string    `json:"author_id"`
+	Reason     string    `json:"reason"` // например, руководитель и подчиненный
+	CreatedAt  time.Time `json:"created_at"`
+}
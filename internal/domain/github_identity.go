@@ -0,0 +1,10 @@
+package domain
+
+import "time"
+
+// соответствие логина GitHub пользователю сервиса, по нему вебхуки находят автора PR
+type GitHubIdentity struct {
+	GitHubLogin string    `json:"github_login"` // хранится в нижнем регистре
+	UserID      string    `json:"user_id"`
+	CreatedAt   time.Time `json:"created_at"`
+}
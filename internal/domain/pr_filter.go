@@ -0,0 +1,25 @@
+package domain
+
+import "time"
+
+// фильтр списка PR, пустые поля не ограничивают выборку
+type PRFilter struct {
+	Status     string // OPEN / MERGED / CLOSED
+	AuthorID   string
+	TeamName   string // команда PR
+	ReviewerID string
+
+	CreatedFrom *time.Time // включительно
+	CreatedTo   *time.Time // не включительно
+	MergedFrom  *time.Time
+	MergedTo    *time.Time
+
+	After *PRCursor // продолжить после этого PR
+	Limit int
+}
+
+// позиция в списке PR, отсортированном по created_at и pull_request_id по убыванию
+type PRCursor struct {
+	CreatedAt time.Time
+	PRID      string
+}
@@ -2,15 +2,34 @@ package domain
 
 import "time"
 
+// статусы пулл реквеста
+const (
+	StatusOpen   = "OPEN"
+	StatusMerged = "MERGED"
+	StatusClosed = "CLOSED"
+)
+
+// предупреждение: ревьюверов меньше нужного, потому что кандидаты достигли лимита открытых ревью
+const WarningReviewersAtCapacity = "REVIEWERS_AT_CAPACITY"
+
 // пулл реквест
 type PullRequest struct {
-	PRID            string     `json:"pull_request_id" db:"pull_request_id"`
-	PRName          string     `json:"pull_request_name" db:"pull_request_name"`
-	AuthorID        string     `json:"author_id" db:"author_id"`
-	Status          string     `json:"status" db:"status"`           // OPEN / MERGED
-	AssignReviewers []*User    `json:"assigned_reviewers,omitempty"` // назначенные ревьюеры
-	CreatedAt       time.Time  `json:"createdAt" db:"created_at"`
-	MergedAt        *time.Time `json:"mergedAt,omitempty" db:"merged_at"`
+	PRID              string     `json:"pull_request_id" db:"pull_request_id"`
+	PRName            string     `json:"pull_request_name" db:"pull_request_name"`
+	AuthorID          string     `json:"author_id" db:"author_id"`
+	TeamName          string     `json:"team_name,omitempty" db:"team_name"` // команда автора, из которой выбираются ревьюеры
+	Status            string     `json:"status" db:"status"`                 // OPEN / MERGED / CLOSED
+	IsDraft           bool       `json:"is_draft" db:"is_draft"`             // черновик без ревьюверов
+	Orphaned          bool       `json:"orphaned,omitempty" db:"orphaned"`   // автор удален, а новый владелец не назначен
+	Repository        string     `json:"repository,omitempty" db:"repository"`
+	ChangedFiles      []string   `json:"changed_files,omitempty"`      // измененные пути для CODEOWNERS
+	Labels            []string   `json:"labels,omitempty"`             // метки для подбора ревьюверов по тегам
+	AssignReviewers   []*User    `json:"assigned_reviewers,omitempty"` // назначенные ревьюеры
+	FallbackReviewers []string   `json:"fallback_reviewers,omitempty"` // ревьюеры из резервных команд
+	Warnings          []string   `json:"warnings,omitempty"`           // предупреждения о неполном назначении
+	CreatedAt         time.Time  `json:"createdAt" db:"created_at"`
+	MergedAt          *time.Time `json:"mergedAt,omitempty" db:"merged_at"`
+	ClosedAt          *time.Time `json:"closedAt,omitempty" db:"closed_at"`
 }
 
 // сокращенный PR (dto)
@@ -0,0 +1,49 @@
+package domain
+
+import "time"
+
+// вердикты ревьювера
+const (
+	VerdictApproved         = "APPROVED"
+	VerdictChangesRequested = "CHANGES_REQUESTED"
+	VerdictCommented        = "COMMENTED"
+)
+
+// ревью назначенного ревьювера
+type Review struct {
+	PRID       string     `json:"pull_request_id"`
+	ReviewerID string     `json:"reviewer_id"`
+	Verdict    string     `json:"verdict,omitempty"` // пусто, пока ревьювер не ответил
+	ReviewedAt *time.Time `json:"reviewed_at,omitempty"`
+}
+
+// назначение, по которому ревьювер не ответил в срок SLA команды PR
+type OverdueReview struct {
+	PRID       string     `json:"pull_request_id"`
+	PRName     string     `json:"pull_request_name"`
+	AuthorID   string     `json:"author_id"`
+	ReviewerID string     `json:"reviewer_id"`
+	AssignedAt time.Time  `json:"assigned_at"`
+	DueAt      time.Time  `json:"due_at"`
+	OverdueAt  *time.Time `json:"overdue_at,omitempty"` // когда просрочка была зафиксирована
+
+	AutoReassign bool `json:"-"` // команда просит переназначать просроченные ревью
+}
+
+// пара автор-ревьювер за окно памяти ротации
+type ReviewPair struct {
+	AuthorID       string    `json:"author_id"`
+	ReviewerID     string    `json:"reviewer_id"`
+	Reviews        int       `json:"reviews"`          // назначений на PR автора за окно
+	LastAssignedAt time.Time `json:"last_assigned_at"` // последнее назначение
+	Penalty        float64   `json:"penalty"`          // сумма весов назначений, вес убывает от 1 до 0 за окно
+}
+
+// IsValidVerdict проверяет, что вердикт известен
+func IsValidVerdict(verdict string) bool {
+	switch verdict {
+	case VerdictApproved, VerdictChangesRequested, VerdictCommented:
+		return true
+	}
+	return false
+}
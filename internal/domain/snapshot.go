@@ -0,0 +1,75 @@
+package domain
+
+import "time"
+
+// версия формата снимка, восстановление принимает только ее
+const SnapshotVersion = 1
+
+// снимок состояния сервиса: команды, пользователи, PR и назначения ревьюверов.
+// История pr_events, отсутствия, теги, CODEOWNERS и правила исключения в снимок не входят
+type Snapshot struct {
+	Version      int             `json:"version"`
+	CreatedAt    time.Time       `json:"created_at"`
+	Teams        []*SnapshotTeam `json:"teams"`
+	Users        []*SnapshotUser `json:"users"`
+	PullRequests []*SnapshotPR   `json:"pull_requests"`
+}
+
+// команда снимка с настройками и участниками
+type SnapshotTeam struct {
+	TeamName string            `json:"team_name"`
+	Settings *TeamSettings     `json:"settings,omitempty"` // nil - настройки не сохранялись
+	Members  []*SnapshotMember `json:"members"`
+}
+
+// участие пользователя в команде
+type SnapshotMember struct {
+	UserID string `json:"user_id"`
+	Role   string `json:"role"`
+}
+
+// пользователь снимка, включая удаленных: на них ссылаются PR и ревью
+type SnapshotUser struct {
+	UserID         string     `json:"user_id"`
+	Username       string     `json:"username"`
+	TeamName       string     `json:"team_name"` // основная команда, пусто - без команды
+	IsActive       bool       `json:"is_active"`
+	MaxOpenReviews *int       `json:"max_open_reviews,omitempty"`
+	DeletedAt      *time.Time `json:"deleted_at,omitempty"`
+}
+
+// PR снимка вместе с файлами, метками и ревьюверами
+type SnapshotPR struct {
+	PRID         string              `json:"pull_request_id"`
+	PRName       string              `json:"pull_request_name"`
+	AuthorID     string              `json:"author_id"`
+	TeamName     string              `json:"team_name"`
+	Status       string              `json:"status"`
+	IsDraft      bool                `json:"is_draft"`
+	Orphaned     bool                `json:"orphaned"`
+	Repository   string              `json:"repository"`
+	ChangedFiles []string            `json:"changed_files"`
+	Labels       []string            `json:"labels"`
+	CreatedAt    time.Time           `json:"created_at"`
+	MergedAt     *time.Time          `json:"merged_at,omitempty"`
+	ClosedAt     *time.Time          `json:"closed_at,omitempty"`
+	Reviewers    []*SnapshotReviewer `json:"reviewers"`
+}
+
+// назначение ревьювера с вердиктом и сроками
+type SnapshotReviewer struct {
+	UserID     string     `json:"user_id"`
+	Verdict    string     `json:"verdict,omitempty"`
+	AssignedAt time.Time  `json:"assigned_at"`
+	ReviewedAt *time.Time `json:"reviewed_at,omitempty"`
+	OverdueAt  *time.Time `json:"overdue_at,omitempty"`
+}
+
+// итог восстановления снимка
+type SnapshotRestore struct {
+	Teams        int `json:"teams"`
+	Users        int `json:"users"`
+	PullRequests int `json:"pull_requests"`
+	Reviewers    int `json:"reviewers"`
+	RetiredUsers int `json:"retired_users"` // пользователи не из снимка помечены удаленными
+}
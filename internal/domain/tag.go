@@ -0,0 +1,30 @@
+package domain
+
+import "strings"
+
+// NormalizeTags приводит теги к нижнему регистру, убирает пустые и повторы
+func NormalizeTags(tags []string) []string {
+	result := []string{}
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		result = append(result, tag)
+	}
+	return result
+}
+
+// HasAnyTag проверяет, что у пользователя есть хотя бы один из тегов
+func HasAnyTag(tags, wanted []string) bool {
+	for _, tag := range tags {
+		for _, w := range wanted {
+			if tag == w {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -1,7 +1,23 @@
 package domain
 
+// роли участника команды
+const (
+	RoleMember     = "member"
+	RoleLead       = "lead"
+	RoleMaintainer = "maintainer"
+)
+
 // команда пользователей
 type Team struct {
 	TeamName string  `json:"team_name"`
 	Members  []*User `json:"members"`
 }
+
+// IsValidRole проверяет, что роль известна; пустая роль означает member
+func IsValidRole(role string) bool {
+	switch role {
+	case "", RoleMember, RoleLead, RoleMaintainer:
+		return true
+	}
+	return false
+}
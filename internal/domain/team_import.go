@@ -0,0 +1,18 @@
+package domain
+
+// перевод пользователя в другую основную команду при импорте
+type UserMove struct {
+	UserID string `json:"user_id"`
+	From   string `json:"from"` // пусто - пользователь был без команды
+	To     string `json:"to"`
+}
+
+// итог импорта команд; при dry_run изменения только посчитаны, но не сохранены
+type TeamImportReport struct {
+	DryRun       bool        `json:"dry_run"`
+	TeamsCreated []string    `json:"teams_created"`
+	UsersCreated []string    `json:"users_created"`
+	UsersUpdated []string    `json:"users_updated"` // изменились имя или активность
+	UsersMoved   []*UserMove `json:"users_moved"`
+	Unchanged    int         `json:"unchanged"`
+}
@@ -0,0 +1,34 @@
+package domain
+
+// число ревьюверов по умолчанию
+const DefaultReviewersCount = 2
+
+// настройки назначения ревьюверов команды
+type TeamSettings struct {
+	TeamName       string   `json:"team_name"`
+	ReviewersCount int      `json:"reviewers_count"`  // сколько ревьюверов назначать
+	MaxReviewers   int      `json:"max_reviewers"`    // предел ревьюверов при ручном добавлении, 0 - общий предел сервиса
+	MinReviewers   int      `json:"min_reviewers"`    // минимум, без которого PR не создается
+	Strategy       string   `json:"strategy"`         // стратегия выбора, пусто - по умолчанию сервиса
+	AllowCrossTeam bool     `json:"allow_cross_team"` // можно ли брать ревьюверов из других команд
+	FallbackTeams  []string `json:"fallback_teams"`   // резервные команды в порядке приоритета
+
+	ApprovalsRequired int `json:"approvals_required"` // сколько APPROVED нужно для merge
+	MaxOpenReviews    int `json:"max_open_reviews"`   // лимит открытых ревью участника, 0 - без ограничения
+
+	ReviewSLAHours      int  `json:"review_sla_hours"`      // срок ответа ревьювера, 0 - не отслеживается
+	AutoReassignOverdue bool `json:"auto_reassign_overdue"` // переназначать просроченные ревью
+
+	RequireLead bool `json:"require_lead"` // среди ревьюверов нужен лид, а для merge - его одобрение
+
+	RotationWindowDays int `json:"rotation_window_days"` // окно памяти пар автор-ревьювер, 0 - не учитывается
+}
+
+// DefaultTeamSettings возвращает настройки для команды без сохраненных настроек
+func DefaultTeamSettings(teamName string) *TeamSettings {
+	return &TeamSettings{
+		TeamName:       teamName,
+		ReviewersCount: DefaultReviewersCount,
+		FallbackTeams:  []string{},
+	}
+}
@@ -4,6 +4,14 @@ package domain
 type User struct {
 	UserID   string `json:"user_id"`
 	Username string `json:"username"`
-	TeamName string `json:"team_name,omitempty"`
+	TeamName string `json:"team_name,omitempty"` // основная команда
 	IsActive bool   `json:"is_active"`
+	Deleted  bool   `json:"deleted,omitempty"` // удален через /users/delete, хранится ради истории
+
+	Teams []string `json:"teams,omitempty"` // все команды пользователя, включая основную
+	Role  string   `json:"role,omitempty"`  // роль в команде, участники которой запрошены
+
+	Tags []string `json:"tags,omitempty"` // навыки ревьювера, например go или db
+
+	MaxOpenReviews *int `json:"max_open_reviews,omitempty"` // личный лимит открытых ревью, nil - лимит команды
 }
@@ -0,0 +1,25 @@
+package domain
+
+// передача открытого ревью удаляемого пользователя
+type ReviewHandoff struct {
+	PRID       string
+	FromUserID string
+	ToUserID   string // пусто - замены нет, ревью снимается
+	ToLimit    int    // лимит открытых ревью получателя, 0 - без лимита
+}
+
+// итог удаления пользователя
+type UserDeletion struct {
+	UserID            string `json:"user_id"`
+	ReassignedReviews int    `json:"reassigned_reviews"` // ревью переданы другим участникам
+	UnassignedReviews int    `json:"unassigned_reviews"` // ревью сняты без замены
+	TransferredPRs    int64  `json:"transferred_prs"`    // незавершенные PR переданы новому автору
+	OrphanedPRs       int64  `json:"orphaned_prs"`       // незавершенные PR помечены orphaned
+}
+
+// итог изменения пользователя
+type UserUpdate struct {
+	User              *User
+	ReassignedReviews int   // ревью в PR прежней основной команды переданы ее участникам
+	ReassignErr       error // переназначение прервалось, изменение пользователя при этом сохранено
+}
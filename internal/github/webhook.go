@@ -0,0 +1,97 @@
+package github
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// заголовки доставки вебхука
+const (
+	HeaderEvent     = "X-GitHub-Event"
+	HeaderSignature = "X-Hub-Signature-256"
+	HeaderDelivery  = "X-GitHub-Delivery"
+)
+
+// события и действия, которые обрабатывает сервис
+const (
+	EventPing        = "ping"
+	EventPullRequest = "pull_request"
+
+	ActionOpened         = "opened"
+	ActionClosed         = "closed"
+	ActionReopened       = "reopened"
+	ActionReadyForReview = "ready_for_review"
+)
+
+const signaturePrefix = "sha256="
+
+// PullRequestEvent - нужная сервису часть события pull_request
+type PullRequestEvent struct {
+	Action      string      `json:"action"`
+	Number      int         `json:"number"`
+	PullRequest PullRequest `json:"pull_request"`
+	Repository  Repository  `json:"repository"`
+}
+
+type PullRequest struct {
+	Number int     `json:"number"`
+	Title  string  `json:"title"`
+	Draft  bool    `json:"draft"`
+	Merged bool    `json:"merged"`
+	User   Account `json:"user"`
+	Labels []Label `json:"labels"`
+}
+
+type Repository struct {
+	FullName string `json:"full_name"` // owner/repo
+}
+
+type Account struct {
+	Login string `json:"login"`
+}
+
+type Label struct {
+	Name string `json:"name"`
+}
+
+// PRID - идентификатор PR в сервисе: `owner/repo#номер`
+func (e *PullRequestEvent) PRID() string {
+	number := e.PullRequest.Number
+	if number == 0 {
+		number = e.Number
+	}
+	return fmt.Sprintf("%s#%d", e.Repository.FullName, number)
+}
+
+// LabelNames возвращает имена меток PR
+func (e *PullRequestEvent) LabelNames() []string {
+	names := make([]string, 0, len(e.PullRequest.Labels))
+	for _, l := range e.PullRequest.Labels {
+		names = append(names, l.Name)
+	}
+	return names
+}
+
+// Sign считает значение X-Hub-Signature-256 для тела запроса
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature проверяет X-Hub-Signature-256: HMAC-SHA256 тела с общим секретом,
+// сравнение выполняется за постоянное время
+func VerifySignature(secret, body []byte, signature string) bool {
+	if len(secret) == 0 || !strings.HasPrefix(signature, signaturePrefix) {
+		return false
+	}
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+// NormalizeLogin приводит логин к виду, в котором он хранится: GitHub не различает регистр
+func NormalizeLogin(login string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(login), "@"))
+}
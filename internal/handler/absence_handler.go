@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/domain"
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/service"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type AbsenceHandler struct {
+	absenceService *service.AbsenceService
+	logger         *zap.SugaredLogger
+}
+
+func NewAbsenceHandler(absenceService *service.AbsenceService, logger *zap.SugaredLogger) *AbsenceHandler {
+	return &AbsenceHandler{
+		absenceService: absenceService,
+		logger:         logger,
+	}
+}
+
+/*
+	 регистрация отсутствия пользователя (отпуск, больничный)
+		POST /users/absence
+		Body:
+			{
+				"user_id": "u2",
+				"starts_at": "2025-11-01T00:00:00Z", (необязательно, по умолчанию - сейчас)
+				"ends_at": "2025-11-14T00:00:00Z",
+				"reason": "vacation"
+			}
+		Success 201:
+			{ "absence": Absence }
+			на время отсутствия пользователь не выбирается ревьювером, его открытые ревью
+			переназначаются в момент начала, после ends_at он снова доступен
+		Errors:
+			400 INVALID_INPUT - некорректное тело или ends_at не позже starts_at и текущего момента
+			404 NOT_FOUND - пользователь не найден
+*/
+func (h *AbsenceHandler) CreateAbsence(ctx *gin.Context) {
+	var req struct {
+		UserID   string     `json:"user_id"`
+		StartsAt *time.Time `json:"starts_at"`
+		EndsAt   time.Time  `json:"ends_at"`
+		Reason   string     `json:"reason"`
+	}
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": gin.H{"code": CodeInvalidInput, "message": err.Error()},
+		})
+		return
+	}
+
+	absence := &domain.Absence{
+		UserID:   req.UserID,
+		StartsAt: time.Now(),
+		EndsAt:   req.EndsAt,
+		Reason:   req.Reason,
+	}
+	if req.StartsAt != nil {
+		absence.StartsAt = *req.StartsAt
+	}
+
+	if err := h.absenceService.Create(absence); err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidAbsence):
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": gin.H{"code": CodeInvalidInput, "message": "ends_at must be after starts_at and now"},
+			})
+		case errors.Is(err, service.ErrUserNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"error": gin.H{"code": CodeNotFound, "message": "user not found"},
+			})
+		default:
+			h.logger.Warnf("failed to create absence for user %s: %v", req.UserID, err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error": gin.H{"code": CodeUnknownError, "message": err.Error()},
+			})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"absence": absence})
+}
+
+/*
+	 текущие и будущие отсутствия пользователя
+		GET /users/absence?user_id=u2
+		Success 200:
+			{ "user_id": "u2", "absences": [ Absence ] }
+		Errors:
+			404 NOT_FOUND - пользователь не найден
+*/
+func (h *AbsenceHandler) ListAbsences(ctx *gin.Context) {
+	userID := ctx.Query("user_id")
+
+	absences, err := h.absenceService.ListByUser(userID)
+	if err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"error": gin.H{"code": CodeNotFound, "message": "user not found"},
+			})
+			return
+		}
+		h.logger.Warnf("failed to list absences of user %s: %v", userID, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": gin.H{"code": CodeUnknownError, "message": err.Error()},
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"user_id":  userID,
+		"absences": absences,
+	})
+}
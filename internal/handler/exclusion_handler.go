@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/domain"
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/service"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+var (
+	CodeExclusionNotFound = "NOT_FOUND"
+)
+
+type ExclusionHandler struct {
+	exclusionService *service.ExclusionService
+	logger           *zap.SugaredLogger
+}
+
+func NewExclusionHandler(exclusionService *service.ExclusionService, logger *zap.SugaredLogger) *ExclusionHandler {
+	return &ExclusionHandler{
+		exclusionService: exclusionService,
+		logger:           logger,
+	}
+}
+
+/*
+	 правило конфликта интересов: reviewer_id никогда не ревьюит PR author_id,
+	 уже назначенные ему ревью PR автора переназначаются, а без замены снимаются
+		POST /rules/exclusions
+		Body:
+			{ "reviewer_id": "user2", "author_id": "user1", "reason": "manager", "mutual": false }
+			(mutual - запретить и обратную пару)
+		Response:
+			201 { "exclusions": [ { exclusion object } ], "reassigned_reviews": 1, "unassigned_reviews": 0 }
+				(exclusions - созданные правила, при mutual уже существовавшая пара не повторяется)
+			200 { "exclusions": [], "reassigned_reviews": 1, "unassigned_reviews": 0 }
+				(правила уже есть, нарушающие их ревью все равно переназначаются)
+			400 INVALID_INPUT - не заданы или совпадают reviewer_id и author_id
+			404 NOT_FOUND - пользователь не найден
+*/
+func (h *ExclusionHandler) CreateExclusion(ctx *gin.Context) {
+	var req struct {
+		ReviewerID string `json:"reviewer_id"`
+		AuthorID   string `json:"author_id"`
+		Reason     string `json:"reason"`
+		Mutual     bool   `json:"mutual"`
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": CodeInvalidInput, "message": err.Error()}})
+		return
+	}
+
+	rule := &domain.ReviewExclusion{ReviewerID: req.ReviewerID, AuthorID: req.AuthorID, Reason: req.Reason}
+	result, err := h.exclusionService.Create(rule, req.Mutual)
+	if err != nil {
+		h.writeError(ctx, err)
+		return
+	}
+	if len(result.Exclusions) == 0 {
+		ctx.JSON(http.StatusOK, result)
+		return
+	}
+	ctx.JSON(http.StatusCreated, result)
+}
+
+/*
+	 список правил исключения
+		GET /rules/exclusions?user_id=user1
+		Parameters:
+			user_id (string, optional) - правила, где пользователь ревьювер или автор; без него - все
+		Response:
+			200 { "exclusions": [ { "reviewer_id": "user2", "author_id": "user1", "reason": "manager", "created_at": "..." } ] }
+*/
+func (h *ExclusionHandler) ListExclusions(ctx *gin.Context) {
+	rules, err := h.exclusionService.List(ctx.Query("user_id"))
+	if err != nil {
+		h.writeError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"exclusions": rules})
+}
+
+/*
+	 удаление правила исключения
+		POST /rules/exclusions/delete
+		Body:
+			{ "reviewer_id": "user2", "author_id": "user1", "mutual": false }
+		Response:
+			200 { "reviewer_id": "user2", "author_id": "user1" }
+			400 INVALID_INPUT - не заданы или совпадают reviewer_id и author_id
+			404 NOT_FOUND - правила нет
+*/
+func (h *ExclusionHandler) DeleteExclusion(ctx *gin.Context) {
+	var req struct {
+		ReviewerID string `json:"reviewer_id"`
+		AuthorID   string `json:"author_id"`
+		Mutual     bool   `json:"mutual"`
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": CodeInvalidInput, "message": err.Error()}})
+		return
+	}
+
+	if err := h.exclusionService.Delete(req.ReviewerID, req.AuthorID, req.Mutual); err != nil {
+		h.writeError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"reviewer_id": req.ReviewerID, "author_id": req.AuthorID})
+}
+
+// writeError отвечает ошибкой сервиса правил исключения
+func (h *ExclusionHandler) writeError(ctx *gin.Context, err error) {
+	switch err {
+	case service.ErrInvalidExclusion:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": CodeInvalidInput, "message": "reviewer_id and author_id must be set and differ"}})
+	case service.ErrUserNotFound:
+		ctx.JSON(http.StatusNotFound, gin.H{"error": gin.H{"code": CodeUserNotFound, "message": "user not found"}})
+	case service.ErrExclusionNotFound:
+		ctx.JSON(http.StatusNotFound, gin.H{"error": gin.H{"code": CodeExclusionNotFound, "message": "exclusion not found"}})
+	default:
+		h.logger.Warnf("exclusion operation failed: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": gin.H{"code": CodeUnknownError, "message": err.Error()}})
+	}
+}
@@ -0,0 +1,220 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/github"
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/service"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// GitHub ограничивает тело доставки 25 МБ
+const maxWebhookBody = 25 << 20
+
+var (
+	CodePayloadTooLarge    = "PAYLOAD_TOO_LARGE"
+	CodeWebhookDisabled    = "WEBHOOK_DISABLED"
+	CodeInvalidSignature   = "INVALID_SIGNATURE"
+	CodeUnknownGitHubLogin = "UNKNOWN_GITHUB_LOGIN"
+	CodeIdentityNotFound   = "NOT_FOUND"
+)
+
+// отказы PullRequestService, которые возвращаются GitHub как 409 с кодом ошибки
+var webhookConflicts = []error{
+	service.ErrPRMerged,
+	service.ErrPRClosed,
+	service.ErrPRDraft,
+	service.ErrAuthorNotInTeam,
+	service.ErrNotEnoughReviewers,
+	service.ErrReviewersAtCapacity,
+	service.ErrLeadUnavailable,
+	service.ErrNoCandidate,
+}
+
+type GitHubHandler struct {
+	githubService *service.GitHubService
+	logger        *zap.SugaredLogger
+}
+
+func NewGitHubHandler(githubService *service.GitHubService, logger *zap.SugaredLogger) *GitHubHandler {
+	return &GitHubHandler{
+		githubService: githubService,
+		logger:        logger,
+	}
+}
+
+/*
+	 прием вебхуков GitHub: события pull_request ведут PR сервиса без ручных вызовов
+		POST /webhooks/github
+		Headers:
+			X-GitHub-Event: pull_request
+			X-GitHub-Delivery: <GUID доставки> (повтор доставки с тем же GUID не применяется)
+			X-Hub-Signature-256: sha256=<HMAC-SHA256 тела с секретом GITHUB_WEBHOOK_SECRET>
+		Body:
+			событие GitHub как есть; PR получает id `owner/repo#номер`, автор ищется по привязке логина
+			opened -> создание PR (draft - черновик, labels - метки), closed + merged -> отметка слияния
+			без проверки кворума, closed -> закрытие, reopened -> переоткрытие, ready_for_review -> готов к ревью
+		Response:
+			200 { "event": "pull_request", "action": "opened", "result": "created", "pr": PullRequest }
+				(result: created / merged / closed / reopened / ready / duplicate; ping -> pong;
+				redelivered - доставка с этим X-GitHub-Delivery уже обработана)
+			202 { "event": "push", "result": "ignored" } - событие или действие не обрабатывается,
+				либо PR открыт до подключения вебхука
+			400 INVALID_INPUT - тело не разбирается
+			401 INVALID_SIGNATURE - подпись отсутствует или не совпадает
+			404 NOT_FOUND - привязанный к логину пользователь не найден
+			409 PR_MERGED, NOT_ENOUGH_REVIEWERS, ... - сервис отказал в действии
+			413 PAYLOAD_TOO_LARGE - тело больше 25 МБ
+			422 UNKNOWN_GITHUB_LOGIN - логин автора не привязан к пользователю
+			503 WEBHOOK_DISABLED - секрет не задан
+*/
+func (h *GitHubHandler) Webhook(ctx *gin.Context) {
+	body, err := io.ReadAll(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxWebhookBody))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": gin.H{"code": CodePayloadTooLarge, "message": err.Error()}})
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": CodeInvalidInput, "message": err.Error()}})
+		return
+	}
+
+	result, err := h.githubService.HandleWebhook(
+		ctx.GetHeader(github.HeaderEvent), ctx.GetHeader(github.HeaderDelivery), ctx.GetHeader(github.HeaderSignature), body)
+	if err != nil {
+		h.writeWebhookError(ctx, err)
+		return
+	}
+
+	resp := gin.H{"event": result.Event, "result": result.Result}
+	if result.Action != "" {
+		resp["action"] = result.Action
+	}
+	if result.PR != nil {
+		resp["pr"] = serializePR(result.PR)
+	}
+	if result.Result == service.WebhookIgnored {
+		ctx.JSON(http.StatusAccepted, resp)
+		return
+	}
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// writeWebhookError отвечает ошибкой обработки доставки
+func (h *GitHubHandler) writeWebhookError(ctx *gin.Context, err error) {
+	if writeExcludedByRules(ctx, err) {
+		return
+	}
+	for _, conflict := range webhookConflicts {
+		if errors.Is(err, conflict) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": gin.H{"code": conflict.Error(), "message": err.Error()}})
+			return
+		}
+	}
+
+	switch {
+	case errors.Is(err, service.ErrWebhookDisabled):
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": gin.H{"code": CodeWebhookDisabled, "message": "github webhook secret is not configured"}})
+	case errors.Is(err, service.ErrInvalidSignature):
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": gin.H{"code": CodeInvalidSignature, "message": "signature does not match payload"}})
+	case errors.Is(err, service.ErrInvalidPayload):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": CodeInvalidInput, "message": err.Error()}})
+	case errors.Is(err, service.ErrUnknownGitHubLogin):
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": gin.H{"code": CodeUnknownGitHubLogin, "message": err.Error()}})
+	case errors.Is(err, service.ErrAuthorNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": gin.H{"code": CodeNotFound, "message": "author not found"}})
+	default:
+		h.logger.Warnf("github webhook failed: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": gin.H{"code": CodeUnknownError, "message": err.Error()}})
+	}
+}
+
+/*
+	 привязка логина GitHub к пользователю, повторная привязка логина переносит его
+		POST /identities/github
+		Body:
+			{ "github_login": "octocat", "user_id": "user1" }
+		Response:
+			200 { "github_login": "octocat", "user_id": "user1", "created_at": "..." }
+			400 INVALID_INPUT - не заданы github_login или user_id
+			404 NOT_FOUND - пользователь не найден
+*/
+func (h *GitHubHandler) LinkIdentity(ctx *gin.Context) {
+	var req struct {
+		GitHubLogin string `json:"github_login"`
+		UserID      string `json:"user_id"`
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": CodeInvalidInput, "message": err.Error()}})
+		return
+	}
+
+	identity, err := h.githubService.LinkIdentity(req.GitHubLogin, req.UserID)
+	if err != nil {
+		h.writeIdentityError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, identity)
+}
+
+/*
+	 список привязок логинов GitHub
+		GET /identities/github?user_id=user1
+		Parameters:
+			user_id (string, optional) - привязки пользователя; без него - все
+		Response:
+			200 { "identities": [ { "github_login": "octocat", "user_id": "user1", "created_at": "..." } ] }
+*/
+func (h *GitHubHandler) ListIdentities(ctx *gin.Context) {
+	identities, err := h.githubService.ListIdentities(ctx.Query("user_id"))
+	if err != nil {
+		h.writeIdentityError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"identities": identities})
+}
+
+/*
+	 удаление привязки логина GitHub
+		POST /identities/github/delete
+		Body:
+			{ "github_login": "octocat" }
+		Response:
+			200 { "github_login": "octocat" }
+			400 INVALID_INPUT - не задан github_login
+			404 NOT_FOUND - логин не привязан
+*/
+func (h *GitHubHandler) UnlinkIdentity(ctx *gin.Context) {
+	var req struct {
+		GitHubLogin string `json:"github_login"`
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": CodeInvalidInput, "message": err.Error()}})
+		return
+	}
+
+	if err := h.githubService.UnlinkIdentity(req.GitHubLogin); err != nil {
+		h.writeIdentityError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"github_login": github.NormalizeLogin(req.GitHubLogin)})
+}
+
+// writeIdentityError отвечает ошибкой управления привязками
+func (h *GitHubHandler) writeIdentityError(ctx *gin.Context, err error) {
+	switch err {
+	case service.ErrInvalidIdentity:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": CodeInvalidInput, "message": "github_login and user_id are required"}})
+	case service.ErrUserNotFound:
+		ctx.JSON(http.StatusNotFound, gin.H{"error": gin.H{"code": CodeUserNotFound, "message": "user not found"}})
+	case service.ErrIdentityNotFound:
+		ctx.JSON(http.StatusNotFound, gin.H{"error": gin.H{"code": CodeIdentityNotFound, "message": "github login is not linked"}})
+	default:
+		h.logger.Warnf("identity operation failed: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": gin.H{"code": CodeUnknownError, "message": err.Error()}})
+	}
+}
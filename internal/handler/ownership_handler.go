@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/domain"
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/service"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type OwnershipHandler struct {
+	ownershipService *service.OwnershipService
+	logger           *zap.SugaredLogger
+}
+
+func NewOwnershipHandler(ownershipService *service.OwnershipService, logger *zap.SugaredLogger) *OwnershipHandler {
+	return &OwnershipHandler{
+		ownershipService: ownershipService,
+		logger:           logger,
+	}
+}
+
+/*
+	 загрузка CODEOWNERS файла репозитория (заменяет прежние правила)
+		POST /ownership/upload
+		Body:
+			{
+				"repository": "monorepo",
+				"content": "*.go @org/backend\n/docs/ @u2\n"
+			}
+		Response:
+			200 { "repository": "monorepo", "rules": [ { "pattern": "*.go", "users": [], "teams": ["backend"] } ] }
+			400 INVALID_INPUT - пустой репозиторий или ошибка разбора файла
+*/
+func (h *OwnershipHandler) Upload(ctx *gin.Context) {
+	var req struct {
+		Repository string `json:"repository"`
+		Content    string `json:"content"`
+	}
+
+	if err := ctx.ShouldBindJSON(&req); err != nil || req.Repository == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": gin.H{"code": CodeInvalidInput, "message": "repository is empty or body is invalid"},
+		})
+		return
+	}
+
+	rules, err := h.ownershipService.Upload(req.Repository, req.Content)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCodeOwners) {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": gin.H{"code": CodeInvalidInput, "message": err.Error()},
+			})
+			return
+		}
+
+		h.logger.Warnf("failed to upload code owners for %s: %v", req.Repository, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": gin.H{"code": CodeUnknownError, "message": err.Error()},
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"repository": req.Repository,
+		"rules":      rules,
+	})
+}
+
+/*
+	 получение правил владения репозитория
+		GET /ownership/get?repository=monorepo
+		Response:
+			200 { "repository": "monorepo", "rules": [ ... ] }
+			400 INVALID_INPUT - не указан репозиторий
+*/
+func (h *OwnershipHandler) GetRules(ctx *gin.Context) {
+	repository := ctx.Query("repository")
+	if repository == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": gin.H{"code": CodeInvalidInput, "message": "repository is empty"},
+		})
+		return
+	}
+
+	rules, err := h.ownershipService.GetRules(repository)
+	if err != nil {
+		h.logger.Warnf("failed to get code owners for %s: %v", repository, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": gin.H{"code": CodeUnknownError, "message": err.Error()},
+		})
+		return
+	}
+	if rules == nil {
+		rules = []*domain.CodeOwnerRule{}
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"repository": repository,
+		"rules":      rules,
+	})
+}
@@ -1,7 +1,9 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/domain"
@@ -14,9 +16,30 @@ var (
 	CodeNotFound    = "NOT_FOUND"
 	CodePRExists    = "PR_EXISTS"
 	CodePRMerged    = "PR_MERGED"
+	CodePRClosed    = "PR_CLOSED"
+	CodePRDraft     = "PR_DRAFT"
 	CodeNotAssigned = "NOT_ASSIGNED"
 	CodeNoCandidate = "NO_CANDIDATE"
 	CodeBadRequest  = "BAD_REQUEST"
+
+	CodeNotEnoughReviewers = "NOT_ENOUGH_REVIEWERS"
+	CodeQuorumNotMet       = "QUORUM_NOT_MET"
+	CodeChangesRequested   = "CHANGES_REQUESTED"
+	CodeAtCapacity         = "REVIEWERS_AT_CAPACITY"
+
+	CodeReviewerIsAuthor = "REVIEWER_IS_AUTHOR"
+	CodeAlreadyAssigned  = "ALREADY_ASSIGNED"
+	CodeReviewerInactive = "REVIEWER_INACTIVE"
+	CodeTeamNotAllowed   = "TEAM_NOT_ALLOWED"
+	CodeTooManyReviewers = "TOO_MANY_REVIEWERS"
+
+	CodeAuthorNotInTeam = "AUTHOR_NOT_IN_TEAM"
+
+	CodeLeadUnavailable      = "LEAD_UNAVAILABLE"
+	CodeLeadApprovalRequired = "LEAD_APPROVAL_REQUIRED"
+
+	CodeReviewerExcluded = "REVIEWER_EXCLUDED"
+	ReasonExclusionRules = "EXCLUSION_RULES"
 )
 
 type PullRequestHandler struct {
@@ -38,13 +61,28 @@ func NewPullRequestHandler(prService *service.PullRequestService, logger *zap.Su
 			{
 				"pull_request_id": "pr-1",
 				"pull_request_name": "Add new",
-				"author_id": "user1"
+				"author_id": "user1",
+				"team_name": "backend",
+				"is_draft": false,
+				"repository": "monorepo",
+				"changed_files": ["api/handler.go", "docs/readme.md"],
+				"labels": ["go", "db"]
 			}
+		team_name - одна из команд автора, из которой выбираются ревьюверы (по умолчанию основная)
 		Success
-			201: { "pr": PullRequest }
+			201: { "pr": PullRequest } (fallback_reviewers - ревьюеры из резервных команд,
+				черновик создается без ревьюверов, среди ревьюверов есть владелец
+				каждого измененного пути по CODEOWNERS репозитория, если он доступен,
+				остальные места в первую очередь получают участники с тегами из labels)
 		Errors:
 			404 NOT_FOUND - автор или команда не найдены
 			409 PR_EXISTS - PR с этим id уже есть
+			409 AUTHOR_NOT_IN_TEAM - автор не состоит в команде team_name
+			409 NOT_ENOUGH_REVIEWERS - в команде меньше кандидатов, чем min_reviewers
+			409 REVIEWERS_AT_CAPACITY - min_reviewers не набирается из-за лимитов открытых ревью
+				(если min_reviewers набран, PR создается с warnings: ["REVIEWERS_AT_CAPACITY"])
+			409 LEAD_UNAVAILABLE - команда требует лида среди ревьюверов, а свободного лида нет
+			409 NO_CANDIDATE (reason: EXCLUSION_RULES) - кандидатов не остается из-за правил исключения
 			400 INVALID_INPUT - некорректное тело запроса
 */
 func (h *PullRequestHandler) CreatePR(c *gin.Context) {
@@ -52,6 +90,12 @@ func (h *PullRequestHandler) CreatePR(c *gin.Context) {
 		ID       string `json:"pull_request_id"`
 		Name     string `json:"pull_request_name"`
 		AuthorID string `json:"author_id"`
+		TeamName string `json:"team_name"`
+		IsDraft  bool   `json:"is_draft"`
+
+		Repository   string   `json:"repository"`
+		ChangedFiles []string `json:"changed_files"`
+		Labels       []string `json:"labels"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -66,11 +110,20 @@ func (h *PullRequestHandler) CreatePR(c *gin.Context) {
 		PRID:     req.ID,
 		PRName:   req.Name,
 		AuthorID: req.AuthorID,
-		Status:   "OPEN",
+		TeamName: req.TeamName,
+		Status:   domain.StatusOpen,
+		IsDraft:  req.IsDraft,
+
+		Repository:   req.Repository,
+		ChangedFiles: req.ChangedFiles,
+		Labels:       req.Labels,
 	}
 
 	err := h.prService.CreatePR(pr)
 	if err != nil {
+		if writeExcludedByRules(c, err) {
+			return
+		}
 		switch err {
 		case service.ErrPRExists:
 			c.JSON(http.StatusConflict, gin.H{
@@ -83,6 +136,30 @@ func (h *PullRequestHandler) CreatePR(c *gin.Context) {
 				"error": gin.H{"code": CodeNotFound, "message": "author not found"},
 			})
 			return
+
+		case service.ErrAuthorNotInTeam:
+			c.JSON(http.StatusConflict, gin.H{
+				"error": gin.H{"code": CodeAuthorNotInTeam, "message": "author is not a member of team_name"},
+			})
+			return
+
+		case service.ErrNotEnoughReviewers:
+			c.JSON(http.StatusConflict, gin.H{
+				"error": gin.H{"code": CodeNotEnoughReviewers, "message": "not enough active reviewers in team"},
+			})
+			return
+
+		case service.ErrReviewersAtCapacity:
+			c.JSON(http.StatusConflict, gin.H{
+				"error": gin.H{"code": CodeAtCapacity, "message": "not enough reviewers below open review limit"},
+			})
+			return
+
+		case service.ErrLeadUnavailable:
+			c.JSON(http.StatusConflict, gin.H{
+				"error": gin.H{"code": CodeLeadUnavailable, "message": "no available team lead to review"},
+			})
+			return
 		}
 
 		// unknown
@@ -107,6 +184,11 @@ func (h *PullRequestHandler) CreatePR(c *gin.Context) {
 			{ "pr": PullRequest (MERGED) }
 		Errors:
 			404 NOT_FOUND - PR не найден
+			409 PR_CLOSED - PR закрыт без слияния
+			409 PR_DRAFT - PR еще черновик
+			409 QUORUM_NOT_MET - одобрений меньше, чем approvals_required команды PR
+			409 CHANGES_REQUESTED - есть ревьювер с вердиктом CHANGES_REQUESTED
+			409 LEAD_APPROVAL_REQUIRED - команда требует одобрения лида, а его нет
 			400 BAD_REQUEST - некорректное тело запроса
 */
 func (h *PullRequestHandler) MergePR(c *gin.Context) {
@@ -140,6 +222,57 @@ func (h *PullRequestHandler) MergePR(c *gin.Context) {
 			return
 		}
 
+		// закрытый PR нельзя слить
+		if err == service.ErrPRClosed {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": gin.H{
+					"code":    CodePRClosed,
+					"message": "cannot merge closed PR",
+				},
+			})
+			return
+		}
+
+		// черновик сначала нужно перевести в ready
+		if err == service.ErrPRDraft {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": gin.H{
+					"code":    CodePRDraft,
+					"message": "cannot merge draft PR",
+				},
+			})
+			return
+		}
+
+		// merge заблокирован ревью
+		if err == service.ErrQuorumNotMet {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": gin.H{
+					"code":    CodeQuorumNotMet,
+					"message": "approval quorum is not met",
+				},
+			})
+			return
+		}
+		if err == service.ErrChangesRequested {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": gin.H{
+					"code":    CodeChangesRequested,
+					"message": "changes requested by reviewer",
+				},
+			})
+			return
+		}
+		if err == service.ErrLeadApprovalRequired {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": gin.H{
+					"code":    CodeLeadApprovalRequired,
+					"message": "approval of team lead is required",
+				},
+			})
+			return
+		}
+
 		// unexpected
 		h.logger.Warnf("failed to merge PR %s: %v", req.ID, err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -161,23 +294,38 @@ func (h *PullRequestHandler) MergePR(c *gin.Context) {
 		Body:
 			{
 				"pull_request_id": "pr-1",
-				"old_user_id": "user2"
+				"old_user_id": "user2",
+				"new_user_id": "user7",   // необязательно, иначе замена выбирается автоматически
+				"allow_any_team": false   // разрешить ревьювера из любой команды
 			}
 		Success 200:
 			{
 			"pr": { PullRequest },
-			"replaced_by": "user5"
+			"replaced_by": "user5",
+			"from_fallback": false
 			}
 		Errors:
 			404 NOT_FOUND - PR или пользователь не найдены
 			409 PR_MERGED - нельзя переприсвоить после MERGED
+			409 PR_CLOSED - нельзя переприсвоить на закрытом PR
+			409 PR_DRAFT - у черновика нет ревьюверов
 			409 NOT_ASSIGNED - переданный пользователь не ревьюер
 			409 NO_CANDIDATE - нет активного пользователя для замены в команде
+				(reason: EXCLUSION_RULES - все кандидаты исключены правилами для автора)
+			409 REVIEWERS_AT_CAPACITY - все кандидаты достигли лимита открытых ревью
+			409 REVIEWER_IS_AUTHOR - new_user_id является автором PR
+			409 REVIEWER_EXCLUDED - new_user_id исключен правилом для автора PR
+			409 ALREADY_ASSIGNED - new_user_id уже назначен ревьювером
+			409 REVIEWER_INACTIVE - new_user_id неактивен или в отсутствии
+			409 TEAM_NOT_ALLOWED - new_user_id не из команды ревьювера и не из ее резервных команд
+			409 LEAD_UNAVAILABLE - old_user_id последний лид в PR команды с require_lead, а new_user_id не лид
 */
 func (h *PullRequestHandler) ReassignReviewer(c *gin.Context) {
 	var req struct {
-		PRID      string `json:"pull_request_id"`
-		OldUserID string `json:"old_user_id"`
+		PRID         string `json:"pull_request_id"`
+		OldUserID    string `json:"old_user_id"`
+		NewUserID    string `json:"new_user_id"`
+		AllowAnyTeam bool   `json:"allow_any_team"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -185,18 +333,61 @@ func (h *PullRequestHandler) ReassignReviewer(c *gin.Context) {
 		return
 	}
 
-	pr, newReviewerID, err := h.prService.ReassignReviewer(req.PRID, req.OldUserID)
+	var (
+		pr            *domain.PullRequest
+		newReviewerID string
+		err           error
+	)
+	if req.NewUserID != "" {
+		pr, err = h.prService.ReassignReviewerTo(req.PRID, req.OldUserID, req.NewUserID, req.AllowAnyTeam)
+		newReviewerID = req.NewUserID
+	} else {
+		pr, newReviewerID, err = h.prService.ReassignReviewer(req.PRID, req.OldUserID)
+	}
 	if err != nil {
+		if writeExcludedByRules(c, err) {
+			return
+		}
 		switch err {
+		case service.ErrReviewerNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": gin.H{"code": CodeNotFound, "message": "new reviewer not found"}})
+			return
+		case service.ErrReviewerIsAuthor:
+			c.JSON(http.StatusConflict, gin.H{"error": gin.H{"code": CodeReviewerIsAuthor, "message": "author cannot review own PR"}})
+			return
+		case service.ErrAlreadyAssigned:
+			c.JSON(http.StatusConflict, gin.H{"error": gin.H{"code": CodeAlreadyAssigned, "message": "user is already assigned to this PR"}})
+			return
+		case service.ErrReviewerExcluded:
+			c.JSON(http.StatusConflict, gin.H{"error": gin.H{"code": CodeReviewerExcluded, "message": "user is excluded from reviewing this author"}})
+			return
+		case service.ErrReviewerInactive:
+			c.JSON(http.StatusConflict, gin.H{"error": gin.H{"code": CodeReviewerInactive, "message": "user is inactive or absent"}})
+			return
+		case service.ErrTeamNotAllowed:
+			c.JSON(http.StatusConflict, gin.H{"error": gin.H{"code": CodeTeamNotAllowed, "message": "user is not in an allowed team"}})
+			return
+		case service.ErrLeadUnavailable:
+			c.JSON(http.StatusConflict, gin.H{"error": gin.H{"code": CodeLeadUnavailable, "message": "last team lead can only be replaced by a lead"}})
+			return
 		case service.ErrPRMerged:
 			c.JSON(http.StatusConflict, gin.H{"error": gin.H{"code": CodePRMerged, "message": "cannot reassign on merged PR"}})
 			return
+		case service.ErrPRClosed:
+			c.JSON(http.StatusConflict, gin.H{"error": gin.H{"code": CodePRClosed, "message": "cannot reassign on closed PR"}})
+			return
+		case service.ErrPRDraft:
+			c.JSON(http.StatusConflict, gin.H{"error": gin.H{"code": CodePRDraft, "message": "draft PR has no reviewers"}})
+			return
 		case service.ErrNotAssigned:
 			c.JSON(http.StatusConflict, gin.H{"error": gin.H{"code": CodeNotAssigned, "message": "reviewer is not assigned to this PR"}})
 			return
 		case service.ErrNoCandidate:
 			c.JSON(http.StatusConflict, gin.H{"error": gin.H{"code": CodeNoCandidate, "message": "no active replacement candidate in team"}})
 			return
+		case service.ErrReviewersAtCapacity:
+			c.JSON(http.StatusConflict, gin.H{"error": gin.H{"code": CodeAtCapacity, "message": "all candidates reached open review limit"}})
+			return
 		case service.ErrPRNotFound:
 			c.JSON(http.StatusNotFound, gin.H{"error": gin.H{"code": CodeNotFound, "message": "PR not found"}})
 			return
@@ -208,23 +399,483 @@ func (h *PullRequestHandler) ReassignReviewer(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"pr":          serializePR(pr),
-		"replaced_by": newReviewerID,
+		"pr":            serializePR(pr),
+		"replaced_by":   newReviewerID,
+		"from_fallback": len(pr.FallbackReviewers) > 0,
 	})
 }
 
+/*
+	 вердикт ревьювера
+		POST /pullRequest/review
+		Body:
+			{
+				"pull_request_id": "pr-1",
+				"reviewer_id": "user2",
+				"verdict": "APPROVED" | "CHANGES_REQUESTED" | "COMMENTED"
+			}
+		Success 200:
+			{ "review": { "pull_request_id": "pr-1", "reviewer_id": "user2", "verdict": "APPROVED", "reviewed_at": "..." } }
+		Errors:
+			400 INVALID_INPUT - некорректное тело запроса или вердикт
+			404 NOT_FOUND - PR не найден
+			409 PR_MERGED - PR уже слит
+			409 PR_CLOSED - PR закрыт
+			409 PR_DRAFT - PR еще черновик
+			409 NOT_ASSIGNED - пользователь не назначен ревьювером
+*/
+func (h *PullRequestHandler) SubmitReview(c *gin.Context) {
+	var req struct {
+		PRID       string `json:"pull_request_id"`
+		ReviewerID string `json:"reviewer_id"`
+		Verdict    string `json:"verdict"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": CodeInvalidInput, "message": err.Error()}})
+		return
+	}
+
+	review, err := h.prService.SubmitReview(req.PRID, req.ReviewerID, req.Verdict)
+	if err != nil {
+		switch err {
+		case service.ErrInvalidVerdict:
+			c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": CodeInvalidInput, "message": "unknown verdict"}})
+		case service.ErrPRNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": gin.H{"code": CodeNotFound, "message": "PR not found"}})
+		case service.ErrPRMerged:
+			c.JSON(http.StatusConflict, gin.H{"error": gin.H{"code": CodePRMerged, "message": "cannot review merged PR"}})
+		case service.ErrPRClosed:
+			c.JSON(http.StatusConflict, gin.H{"error": gin.H{"code": CodePRClosed, "message": "cannot review closed PR"}})
+		case service.ErrPRDraft:
+			c.JSON(http.StatusConflict, gin.H{"error": gin.H{"code": CodePRDraft, "message": "cannot review draft PR"}})
+		case service.ErrNotAssigned:
+			c.JSON(http.StatusConflict, gin.H{"error": gin.H{"code": CodeNotAssigned, "message": "reviewer is not assigned to this PR"}})
+		default:
+			h.logger.Warnf("failed to submit review on PR %s: %v", req.PRID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": gin.H{"code": CodeUnknownError, "message": err.Error()}})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"review": review})
+}
+
+/*
+	 замена меток PR (назначенные ревьюверы не меняются)
+		POST /pullRequest/setLabels
+		Body:
+			{ "pull_request_id": "pr-1", "labels": ["go", "db"] }
+		Success 200:
+			{ "pr": PullRequest }
+		Errors:
+			400 INVALID_INPUT - некорректное тело запроса
+			404 NOT_FOUND - PR не найден
+			409 PR_MERGED / PR_CLOSED - метки слитого или закрытого PR не меняются
+*/
+func (h *PullRequestHandler) SetLabels(c *gin.Context) {
+	var req struct {
+		ID     string   `json:"pull_request_id"`
+		Labels []string `json:"labels"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": CodeInvalidInput, "message": err.Error()}})
+		return
+	}
+
+	pr, err := h.prService.SetLabels(req.ID, req.Labels)
+	if err != nil {
+		switch err {
+		case service.ErrPRNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": gin.H{"code": CodeNotFound, "message": "PR not found"}})
+		case service.ErrPRMerged:
+			c.JSON(http.StatusConflict, gin.H{"error": gin.H{"code": CodePRMerged, "message": "cannot change labels of merged PR"}})
+		case service.ErrPRClosed:
+			c.JSON(http.StatusConflict, gin.H{"error": gin.H{"code": CodePRClosed, "message": "cannot change labels of closed PR"}})
+		default:
+			h.logger.Warnf("failed to set labels of PR %s: %v", req.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": gin.H{"code": CodeUnknownError, "message": err.Error()}})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"pr": serializePR(pr)})
+}
+
+/*
+	 ручное добавление ревьювера сверх автоматически выбранных
+		POST /pullRequest/reviewers/add
+		Body:
+			{ "pull_request_id": "pr-1", "user_id": "user7" }
+		Success 200:
+			{ "pr": PullRequest }
+		Errors:
+			400 INVALID_INPUT - некорректное тело запроса
+			404 NOT_FOUND - PR или пользователь не найдены
+			409 PR_MERGED / PR_CLOSED / PR_DRAFT - PR нельзя изменять
+			409 REVIEWER_IS_AUTHOR - пользователь является автором PR
+			409 ALREADY_ASSIGNED - пользователь уже назначен
+			409 REVIEWER_INACTIVE - пользователь неактивен или в отсутствии
+			409 REVIEWER_EXCLUDED - пользователь исключен правилом для автора PR
+			409 TOO_MANY_REVIEWERS - достигнут max_reviewers команды PR
+*/
+func (h *PullRequestHandler) AddReviewer(c *gin.Context) {
+	var req struct {
+		PRID   string `json:"pull_request_id"`
+		UserID string `json:"user_id"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil || req.PRID == "" || req.UserID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": CodeInvalidInput, "message": "pull_request_id and user_id are required"}})
+		return
+	}
+
+	pr, err := h.prService.AddReviewer(req.PRID, req.UserID)
+	if err != nil {
+		switch err {
+		case service.ErrPRNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": gin.H{"code": CodeNotFound, "message": "PR not found"}})
+		case service.ErrReviewerNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": gin.H{"code": CodeNotFound, "message": "user not found"}})
+		case service.ErrPRMerged:
+			c.JSON(http.StatusConflict, gin.H{"error": gin.H{"code": CodePRMerged, "message": "cannot change reviewers on merged PR"}})
+		case service.ErrPRClosed:
+			c.JSON(http.StatusConflict, gin.H{"error": gin.H{"code": CodePRClosed, "message": "cannot change reviewers on closed PR"}})
+		case service.ErrPRDraft:
+			c.JSON(http.StatusConflict, gin.H{"error": gin.H{"code": CodePRDraft, "message": "draft PR has no reviewers"}})
+		case service.ErrReviewerIsAuthor:
+			c.JSON(http.StatusConflict, gin.H{"error": gin.H{"code": CodeReviewerIsAuthor, "message": "author cannot review own PR"}})
+		case service.ErrAlreadyAssigned:
+			c.JSON(http.StatusConflict, gin.H{"error": gin.H{"code": CodeAlreadyAssigned, "message": "user is already assigned to this PR"}})
+		case service.ErrReviewerExcluded:
+			c.JSON(http.StatusConflict, gin.H{"error": gin.H{"code": CodeReviewerExcluded, "message": "user is excluded from reviewing this author"}})
+		case service.ErrReviewerInactive:
+			c.JSON(http.StatusConflict, gin.H{"error": gin.H{"code": CodeReviewerInactive, "message": "user is inactive or absent"}})
+		case service.ErrTooManyReviewers:
+			c.JSON(http.StatusConflict, gin.H{"error": gin.H{"code": CodeTooManyReviewers, "message": "PR already has maximum number of reviewers"}})
+		default:
+			h.logger.Warnf("failed to add reviewer %s to PR %s: %v", req.UserID, req.PRID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": gin.H{"code": CodeUnknownError, "message": err.Error()}})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"pr": serializePR(pr)})
+}
+
+/*
+	 снятие ревьювера с PR без замены
+		POST /pullRequest/reviewers/remove
+		Body:
+			{ "pull_request_id": "pr-1", "user_id": "user2" }
+		Success 200:
+			{ "pr": PullRequest }
+		Errors:
+			400 INVALID_INPUT - некорректное тело запроса
+			404 NOT_FOUND - PR не найден
+			409 PR_MERGED / PR_CLOSED / PR_DRAFT - PR нельзя изменять
+			409 NOT_ASSIGNED - пользователь не ревьювер этого PR
+			409 LEAD_UNAVAILABLE - это последний лид в PR команды с require_lead
+*/
+func (h *PullRequestHandler) RemoveReviewer(c *gin.Context) {
+	var req struct {
+		PRID   string `json:"pull_request_id"`
+		UserID string `json:"user_id"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil || req.PRID == "" || req.UserID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": CodeInvalidInput, "message": "pull_request_id and user_id are required"}})
+		return
+	}
+
+	pr, err := h.prService.RemoveReviewer(req.PRID, req.UserID)
+	if err != nil {
+		switch err {
+		case service.ErrPRNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": gin.H{"code": CodeNotFound, "message": "PR not found"}})
+		case service.ErrPRMerged:
+			c.JSON(http.StatusConflict, gin.H{"error": gin.H{"code": CodePRMerged, "message": "cannot change reviewers on merged PR"}})
+		case service.ErrPRClosed:
+			c.JSON(http.StatusConflict, gin.H{"error": gin.H{"code": CodePRClosed, "message": "cannot change reviewers on closed PR"}})
+		case service.ErrPRDraft:
+			c.JSON(http.StatusConflict, gin.H{"error": gin.H{"code": CodePRDraft, "message": "draft PR has no reviewers"}})
+		case service.ErrNotAssigned:
+			c.JSON(http.StatusConflict, gin.H{"error": gin.H{"code": CodeNotAssigned, "message": "reviewer is not assigned to this PR"}})
+		case service.ErrLeadUnavailable:
+			c.JSON(http.StatusConflict, gin.H{"error": gin.H{"code": CodeLeadUnavailable, "message": "cannot remove the last team lead from PR"}})
+		default:
+			h.logger.Warnf("failed to remove reviewer %s from PR %s: %v", req.UserID, req.PRID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": gin.H{"code": CodeUnknownError, "message": err.Error()}})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"pr": serializePR(pr)})
+}
+
+/*
+	 список PR с фильтрами, от новых к старым
+		GET /pullRequest/list?status=OPEN&author_id=u1&team_name=backend&reviewer_id=u2
+		                     &created_from=2025-11-01T00:00:00Z&created_to=...&merged_from=...&merged_to=...
+		                     &limit=50&cursor=...
+		Все параметры необязательны, даты в RFC3339, *_from включительно, *_to - нет.
+		team_name фильтрует по команде PR, limit по умолчанию 50, максимум 200.
+		Success 200:
+			{
+				"pull_requests": [ PullRequest ],
+				"next_cursor": "..." (только если есть следующая страница)
+			}
+		Errors:
+			400 INVALID_INPUT - некорректный статус, дата, limit или cursor
+*/
+func (h *PullRequestHandler) ListPRs(c *gin.Context) {
+	filter := &domain.PRFilter{
+		Status:     c.Query("status"),
+		AuthorID:   c.Query("author_id"),
+		TeamName:   c.Query("team_name"),
+		ReviewerID: c.Query("reviewer_id"),
+	}
+
+	invalid := func(message string) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": CodeInvalidInput, "message": message}})
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil {
+			invalid("limit must be an integer")
+			return
+		}
+		filter.Limit = n
+	}
+
+	dates := []struct {
+		param string
+		dst   **time.Time
+	}{
+		{"created_from", &filter.CreatedFrom},
+		{"created_to", &filter.CreatedTo},
+		{"merged_from", &filter.MergedFrom},
+		{"merged_to", &filter.MergedTo},
+	}
+	for _, d := range dates {
+		value := c.Query(d.param)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			invalid(d.param + " must be RFC3339 time")
+			return
+		}
+		t = t.UTC()
+		*d.dst = &t
+	}
+
+	prs, next, err := h.prService.ListPRs(filter, c.Query("cursor"))
+	if err != nil {
+		if err == service.ErrInvalidFilter {
+			invalid("invalid status, limit or cursor")
+			return
+		}
+		h.logger.Warnf("failed to list PRs: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": gin.H{"code": CodeUnknownError, "message": err.Error()}})
+		return
+	}
+
+	result := make([]map[string]any, 0, len(prs))
+	for _, pr := range prs {
+		result = append(result, serializePR(pr))
+	}
+
+	response := gin.H{"pull_requests": result}
+	if next != "" {
+		response["next_cursor"] = next
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+/*
+	 история PR (журнал pr_events)
+		GET /pullRequest/history?pull_request_id=pr-1
+		Success 200:
+			{
+				"pull_request_id": "pr-1",
+				"events": [
+					{ "event_id": 1, "event_type": "PR_CREATED", "pull_request_id": "pr-1", "user_id": "u1", "created_at": "..." },
+					{ "event_id": 2, "event_type": "REVIEWER_ASSIGNED", "pull_request_id": "pr-1", "user_id": "u2", "created_at": "..." },
+					{ "event_id": 5, "event_type": "PR_MERGED", "pull_request_id": "pr-1", "details": { "reviewers": ["u2", "u3"] }, "created_at": "..." }
+				]
+			}
+		Errors:
+			404 NOT_FOUND - PR не найден
+*/
+func (h *PullRequestHandler) GetHistory(c *gin.Context) {
+	prID := c.Query("pull_request_id")
+
+	events, err := h.prService.GetHistory(prID)
+	if err != nil {
+		if err == service.ErrPRNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": gin.H{"code": CodeNotFound, "message": "PR not found"}})
+			return
+		}
+		h.logger.Warnf("failed to get history of PR %s: %v", prID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": gin.H{"code": CodeUnknownError, "message": err.Error()}})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"pull_request_id": prID, "events": events})
+}
+
+/*
+	 закрытие PR без слияния
+		POST /pullRequest/close
+		Body:
+			{ "pull_request_id": "pr-1" }
+		Success 200:
+			{ "pr": PullRequest (CLOSED) }
+		Errors:
+			400 INVALID_INPUT - некорректное тело запроса
+			404 NOT_FOUND - PR не найден
+			409 PR_MERGED - слитый PR закрыть нельзя
+*/
+func (h *PullRequestHandler) ClosePR(c *gin.Context) {
+	h.changeStatus(c, h.prService.ClosePR)
+}
+
+/*
+	 повторное открытие закрытого PR, ревьюверы, ставшие недоступными или исключенными, пока он
+	 был закрыт, заменяются участниками команды PR
+		POST /pullRequest/reopen
+		Body:
+			{ "pull_request_id": "pr-1" }
+		Success 200:
+			{ "pr": PullRequest (OPEN) }
+		Errors:
+			400 INVALID_INPUT - некорректное тело запроса
+			404 NOT_FOUND - PR не найден
+			409 PR_MERGED - слитый PR открыть нельзя
+*/
+func (h *PullRequestHandler) ReopenPR(c *gin.Context) {
+	h.changeStatus(c, h.prService.ReopenPR)
+}
+
+/*
+	 перевод черновика в готовый к ревью PR с назначением ревьюверов
+		POST /pullRequest/ready
+		Body:
+			{ "pull_request_id": "pr-1" }
+		Success 200:
+			{ "pr": PullRequest (is_draft = false) }
+		Errors:
+			400 INVALID_INPUT - некорректное тело запроса
+			404 NOT_FOUND - PR не найден
+			409 PR_MERGED - PR уже слит
+			409 PR_CLOSED - PR закрыт
+			409 NOT_ENOUGH_REVIEWERS - в команде меньше кандидатов, чем min_reviewers
+			409 REVIEWERS_AT_CAPACITY - min_reviewers не набирается из-за лимитов открытых ревью
+			409 LEAD_UNAVAILABLE - команда требует лида среди ревьюверов, а свободного лида нет
+			409 NO_CANDIDATE (reason: EXCLUSION_RULES) - кандидатов не остается из-за правил исключения
+*/
+func (h *PullRequestHandler) ReadyPR(c *gin.Context) {
+	h.changeStatus(c, h.prService.ReadyPR)
+}
+
+// changeStatus общий обработчик смены статуса PR по его id
+func (h *PullRequestHandler) changeStatus(c *gin.Context, change func(prID string) (*domain.PullRequest, error)) {
+	var req struct {
+		ID string `json:"pull_request_id"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": CodeInvalidInput, "message": err.Error()}})
+		return
+	}
+
+	pr, err := change(req.ID)
+	if err != nil {
+		if writeExcludedByRules(c, err) {
+			return
+		}
+		switch err {
+		case service.ErrPRNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": gin.H{"code": CodeNotFound, "message": "PR not found"}})
+		case service.ErrPRMerged:
+			c.JSON(http.StatusConflict, gin.H{"error": gin.H{"code": CodePRMerged, "message": "PR is already merged"}})
+		case service.ErrPRClosed:
+			c.JSON(http.StatusConflict, gin.H{"error": gin.H{"code": CodePRClosed, "message": "PR is closed"}})
+		case service.ErrNotEnoughReviewers:
+			c.JSON(http.StatusConflict, gin.H{"error": gin.H{"code": CodeNotEnoughReviewers, "message": "not enough active reviewers in team"}})
+		case service.ErrReviewersAtCapacity:
+			c.JSON(http.StatusConflict, gin.H{"error": gin.H{"code": CodeAtCapacity, "message": "not enough reviewers below open review limit"}})
+		case service.ErrLeadUnavailable:
+			c.JSON(http.StatusConflict, gin.H{"error": gin.H{"code": CodeLeadUnavailable, "message": "no available team lead to review"}})
+		default:
+			h.logger.Warnf("failed to change status of PR %s: %v", req.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": gin.H{"code": CodeUnknownError, "message": err.Error()}})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"pr": serializePR(pr)})
+}
+
+// writeExcludedByRules отвечает NO_CANDIDATE с причиной, если кандидатов не осталось из-за правил исключения
+func writeExcludedByRules(c *gin.Context, err error) bool {
+	if !errors.Is(err, service.ErrExcludedByRules) {
+		return false
+	}
+	c.JSON(http.StatusConflict, gin.H{"error": gin.H{
+		"code":    CodeNoCandidate,
+		"message": "all candidates are excluded by author-reviewer rules",
+		"reason":  ReasonExclusionRules,
+	}})
+	return true
+}
+
 func serializePR(pr *domain.PullRequest) map[string]any {
 	assigned := make([]string, len(pr.AssignReviewers))
 	for i, r := range pr.AssignReviewers {
 		assigned[i] = r.UserID
 	}
-	return map[string]any{
+	result := map[string]any{
 		"pull_request_id":    pr.PRID,
 		"pull_request_name":  pr.PRName,
 		"author_id":          pr.AuthorID,
 		"status":             pr.Status,
+		"is_draft":           pr.IsDraft,
 		"assigned_reviewers": assigned,
 		"createdAt":          pr.CreatedAt,
 		"mergedAt":           pr.MergedAt,
 	}
+
+	if pr.ClosedAt != nil {
+		result["closedAt"] = pr.ClosedAt
+	}
+	if pr.TeamName != "" {
+		result["team_name"] = pr.TeamName
+	}
+	if pr.Repository != "" {
+		result["repository"] = pr.Repository
+	}
+	if len(pr.ChangedFiles) > 0 {
+		result["changed_files"] = pr.ChangedFiles
+	}
+	if len(pr.Labels) > 0 {
+		result["labels"] = pr.Labels
+	}
+	// автор удален, и PR никому не передан
+	if pr.Orphaned {
+		result["orphaned"] = true
+	}
+
+	// ревьюеры, взятые из резервных команд
+	if len(pr.FallbackReviewers) > 0 {
+		result["fallback_reviewers"] = pr.FallbackReviewers
+	}
+	if len(pr.Warnings) > 0 {
+		result["warnings"] = pr.Warnings
+	}
+	return result
 }
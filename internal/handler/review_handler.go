@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/service"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type ReviewHandler struct {
+	slaService *service.ReviewSLAService
+	logger     *zap.SugaredLogger
+}
+
+func NewReviewHandler(slaService *service.ReviewSLAService, logger *zap.SugaredLogger) *ReviewHandler {
+	return &ReviewHandler{
+		slaService: slaService,
+		logger:     logger,
+	}
+}
+
+/*
+	 просроченные ревью открытых PR (ревьювер не ответил в срок review_sla_hours команды PR)
+		GET /reviews/overdue
+		Response:
+			200 {
+				"reviews": [
+					{
+						"pull_request_id": "pr-1",
+						"pull_request_name": "Add new",
+						"author_id": "u1",
+						"reviewer_id": "u2",
+						"assigned_at": "...",
+						"due_at": "...",
+						"overdue_at": "..."
+					}
+				]
+			}
+*/
+func (h *ReviewHandler) ListOverdue(ctx *gin.Context) {
+	reviews, err := h.slaService.ListOverdue()
+	if err != nil {
+		h.logger.Warnf("failed to list overdue reviews: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": gin.H{"code": CodeUnknownError, "message": err.Error()},
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"reviews": reviews})
+}
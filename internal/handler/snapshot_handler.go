@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/domain"
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/service"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+var CodeUnsupportedSnapshot = "UNSUPPORTED_SNAPSHOT_VERSION"
+
+type SnapshotHandler struct {
+	snapshotService *service.SnapshotService
+	logger          *zap.SugaredLogger
+}
+
+func NewSnapshotHandler(snapshotService *service.SnapshotService, logger *zap.SugaredLogger) *SnapshotHandler {
+	return &SnapshotHandler{
+		snapshotService: snapshotService,
+		logger:          logger,
+	}
+}
+
+/*
+	 снимок состояния: команды с настройками и участниками, пользователи, PR и назначения ревьюверов
+		GET /admin/snapshot
+		Response:
+			200 {
+				"version": 1,
+				"created_at": "...",
+				"teams": [ { "team_name": "backend", "settings": { settings object }, "members": [ { "user_id": "u1", "role": "lead" } ] } ],
+				"users": [ { "user_id": "u1", "username": "Alice", "team_name": "backend", "is_active": true } ],
+				"pull_requests": [ { "pull_request_id": "pr-1", ..., "reviewers": [ { "user_id": "u2", "verdict": "APPROVED", "assigned_at": "..." } ] } ]
+			}
+*/
+func (h *SnapshotHandler) GetSnapshot(ctx *gin.Context) {
+	snapshot, err := h.snapshotService.Export()
+	if err != nil {
+		h.logger.Warnf("failed to export snapshot: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": gin.H{"code": CodeUnknownError, "message": err.Error()}})
+		return
+	}
+	ctx.Header("Content-Disposition", "attachment; filename=snapshot.json")
+	ctx.JSON(http.StatusOK, snapshot)
+}
+
+/*
+	 восстановление снимка: команды, PR и назначения заменяются целиком одной транзакцией,
+	 пользователи не из снимка помечаются удаленными; история pr_events сохраняется
+		POST /admin/restore
+		Body:
+			снимок из GET /admin/snapshot
+		Response:
+			200 { "restored": { "teams": 2, "users": 5, "pull_requests": 3, "reviewers": 6, "retired_users": 0 } }
+			400 INVALID_INPUT - некорректный JSON или несогласованный снимок (ошибки перечислены в message)
+			400 UNSUPPORTED_SNAPSHOT_VERSION - неизвестная версия формата
+*/
+func (h *SnapshotHandler) Restore(ctx *gin.Context) {
+	var snapshot domain.Snapshot
+	if err := ctx.ShouldBindJSON(&snapshot); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": CodeInvalidInput, "message": err.Error()}})
+		return
+	}
+
+	result, err := h.snapshotService.Restore(&snapshot)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrUnsupportedSnapshot):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": CodeUnsupportedSnapshot, "message": err.Error()}})
+		case errors.Is(err, service.ErrInvalidSnapshot):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": CodeInvalidInput, "message": err.Error()}})
+		default:
+			h.logger.Warnf("failed to restore snapshot: %v", err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": gin.H{"code": CodeUnknownError, "message": err.Error()}})
+		}
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"restored": result})
+}
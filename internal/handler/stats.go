@@ -2,6 +2,7 @@ package handler
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/service"
 	"github.com/gin-gonic/gin"
@@ -57,3 +58,60 @@ func (h *StatsHandler) GetStats(c *gin.Context) {
 
 	c.JSON(http.StatusOK, result)
 }
+
+/*
+	 матрица пар автор-ревьювер по PR команды за окно ротации
+		GET /stats/pairs?team_name=team1&window_days=30
+		Parameters:
+			team_name (string, required)
+			window_days (int, optional) - по умолчанию rotation_window_days команды, если он не задан - 30
+		Response:
+			200 {
+				"team_name": "team1",
+				"window_days": 30,
+				"pairs": [
+					{ "author_id": "user1", "reviewer_id": "user2", "reviews": 3, "last_assigned_at": "...", "penalty": 2.4 }
+				]
+			}
+			400 INVALID_INPUT - не задан team_name или некорректный window_days
+			404 NOT_FOUND - команда не найдена
+*/
+func (h *StatsHandler) GetPairs(c *gin.Context) {
+	teamName := c.Query("team_name")
+	windowDays := 0
+	if raw := c.Query("window_days"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": CodeInvalidInput, "message": "window_days must be an integer"}})
+			return
+		}
+		windowDays = parsed
+	}
+	if teamName == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": CodeInvalidInput, "message": "team_name is required"}})
+		return
+	}
+
+	exists, err := h.teamService.TeamExists(teamName)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": gin.H{"code": CodeUnknownError, "message": err.Error()}})
+		return
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": gin.H{"code": CodeTeamNotFound, "message": "team not found"}})
+		return
+	}
+
+	pairs, window, err := h.prService.ReviewPairs(teamName, windowDays)
+	if err != nil {
+		if err == service.ErrInvalidFilter {
+			c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": CodeInvalidInput, "message": "window_days is out of range"}})
+			return
+		}
+		h.logger.Warnf("failed to get review pairs of team %s: %v", teamName, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": gin.H{"code": CodeUnknownError, "message": err.Error()}})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"team_name": teamName, "window_days": window, "pairs": pairs})
+}
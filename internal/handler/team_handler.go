@@ -38,12 +38,14 @@ func NewTeamHandler(teamService *service.TeamService, prService *service.PullReq
 				"team_name": "team1",
 				"members": [
 					{ "user_id": "user1", "username": "Ilya", "is_active": true },
-					{ "user_id": "user2", "username": "AnotherIlya", "is_active": true }
+					{ "user_id": "user2", "username": "AnotherIlya", "is_active": true, "role": "lead" }
 				]
 			}
+			(role: member, lead или maintainer, по умолчанию member)
 		Response:
 			201 { "team": { team object } }
 			400 TEAM_EXISTS: { "error": { "code": "TEAM_EXISTS", "message": "team already exists" } }
+			400 INVALID_INPUT - неизвестная роль участника
 */
 func (h *TeamHandler) CreateTeam(ctx *gin.Context) {
 	var req domain.Team
@@ -73,6 +75,17 @@ func (h *TeamHandler) CreateTeam(ctx *gin.Context) {
 			return
 		}
 
+		// 400 неизвестная роль
+		if err == service.ErrInvalidRole {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": gin.H{
+					"code":    CodeInvalidInput,
+					"message": "role must be member, lead or maintainer",
+				},
+			})
+			return
+		}
+
 		// 500 остальные ошибки
 		h.logger.Warnf("failed to create team %s: %v", req.TeamName, err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{
@@ -171,6 +184,8 @@ func (h *TeamHandler) GetTeam(ctx *gin.Context) {
 		Response:
 			200 OK:
 				{ "message": "team deactivated and PR reviewers reassigned" }
+				ревью без свободной замены остаются за неактивными ревьюверами и перечисляются в
+				"unstaffed_reviews": [{ "pull_request_id": "pr-1", "user_id": "u2" }]
 			400 BAD_REQUEST:
 				{ "error": "team_name is empty" } запроса пустое или некорректное
 			404 NOT_FOUND:
@@ -214,12 +229,198 @@ func (h *TeamHandler) DeactivateTeam(c *gin.Context) {
     }
 
     // безопасное переназначение ревьюверов для всех открытых PR команды
-    if err := h.prService.ReassignReviewersForTeam(teamName); err != nil {
+    unstaffed, err := h.prService.ReassignReviewersForTeam(teamName)
+    if err != nil {
         // если ошибка при переназначении — возвращаем 500
         c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
         return
     }
 
     // успешный ответ: команда деактивирована и ревьюверы переназначены
-    c.JSON(http.StatusOK, gin.H{"message": "team deactivated and PR reviewers reassigned"})
-}
\ No newline at end of file
+    result := gin.H{"message": "team deactivated and PR reviewers reassigned"}
+    if len(unstaffed) > 0 {
+        // ревью, для которых не нашлось замены
+        reviews := make([]gin.H, 0, len(unstaffed))
+        for _, r := range unstaffed {
+            reviews = append(reviews, gin.H{"pull_request_id": r.PRID, "user_id": r.FromUserID})
+        }
+        result["unstaffed_reviews"] = reviews
+    }
+    c.JSON(http.StatusOK, result)
+}
+
+/*
+	 получение настроек назначения ревьюверов команды
+		GET /team/settings?team_name=team1
+		Response:
+			200 { "settings": { "team_name": "team1", "reviewers_count": 2, "min_reviewers": 0, "strategy": "", "allow_cross_team": false, "fallback_teams": [], "approvals_required": 0, "max_open_reviews": 0, "review_sla_hours": 0, "auto_reassign_overdue": false } }
+			400 INVALID_INPUT - не указано имя команды
+			404 NOT_FOUND - команда не найдена
+*/
+func (h *TeamHandler) GetSettings(ctx *gin.Context) {
+	teamName := ctx.Query("team_name")
+	if teamName == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": gin.H{"code": CodeInvalidInput, "message": "team name is empty"},
+		})
+		return
+	}
+
+	if !h.ensureTeamExists(ctx, teamName) {
+		return
+	}
+
+	settings, err := h.teamService.GetSettings(teamName)
+	if err != nil {
+		h.logger.Warnf("failed to get settings for team %s: %v", teamName, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": gin.H{"code": CodeUnknownError, "message": err.Error()},
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"settings": settings})
+}
+
+/*
+	 изменение настроек назначения ревьюверов команды
+		POST /team/settings
+		Body (незаданные поля остаются прежними):
+			{
+				"team_name": "team1",
+				"reviewers_count": 3,
+				"min_reviewers": 1,
+				"max_reviewers": 4, (0 - общий предел сервиса)
+				"strategy": "least_loaded",
+				"allow_cross_team": true,
+				"fallback_teams": ["team2", "team3"],
+				"approvals_required": 1, (не больше max_reviewers, а без него - reviewers_count)
+				"max_open_reviews": 5, (0 - без ограничения)
+				"review_sla_hours": 24, (0 - SLA не отслеживается)
+				"auto_reassign_overdue": true,
+				"require_lead": true, (среди ревьюверов нужен лид, для merge - его одобрение)
+				"rotation_window_days": 30 (окно памяти пар автор-ревьювер, 0 - не учитывается)
+			}
+		Response:
+			200 { "settings": { settings object } }
+			400 INVALID_INPUT - некорректные значения настроек или резервных команд
+			404 NOT_FOUND - команда не найдена
+*/
+func (h *TeamHandler) UpdateSettings(ctx *gin.Context) {
+	var req struct {
+		TeamName       string  `json:"team_name"`
+		ReviewersCount *int    `json:"reviewers_count"`
+		MinReviewers   *int    `json:"min_reviewers"`
+		MaxReviewers   *int    `json:"max_reviewers"`
+		Strategy       *string `json:"strategy"`
+		AllowCrossTeam *bool     `json:"allow_cross_team"`
+		FallbackTeams  *[]string `json:"fallback_teams"`
+
+		ApprovalsRequired *int `json:"approvals_required"`
+		MaxOpenReviews    *int `json:"max_open_reviews"`
+
+		ReviewSLAHours      *int  `json:"review_sla_hours"`
+		AutoReassignOverdue *bool `json:"auto_reassign_overdue"`
+
+		RequireLead *bool `json:"require_lead"`
+
+		RotationWindowDays *int `json:"rotation_window_days"`
+	}
+
+	if err := ctx.ShouldBindJSON(&req); err != nil || req.TeamName == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": gin.H{"code": CodeInvalidInput, "message": "team_name is empty or body is invalid"},
+		})
+		return
+	}
+
+	if !h.ensureTeamExists(ctx, req.TeamName) {
+		return
+	}
+
+	// накладываем изменения на текущие настройки
+	settings, err := h.teamService.GetSettings(req.TeamName)
+	if err != nil {
+		h.logger.Warnf("failed to get settings for team %s: %v", req.TeamName, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": gin.H{"code": CodeUnknownError, "message": err.Error()},
+		})
+		return
+	}
+	if req.ReviewersCount != nil {
+		settings.ReviewersCount = *req.ReviewersCount
+	}
+	if req.MinReviewers != nil {
+		settings.MinReviewers = *req.MinReviewers
+	}
+	if req.MaxReviewers != nil {
+		settings.MaxReviewers = *req.MaxReviewers
+	}
+	if req.Strategy != nil {
+		settings.Strategy = *req.Strategy
+	}
+	if req.AllowCrossTeam != nil {
+		settings.AllowCrossTeam = *req.AllowCrossTeam
+	}
+	if req.FallbackTeams != nil {
+		settings.FallbackTeams = *req.FallbackTeams
+	}
+	if req.ApprovalsRequired != nil {
+		settings.ApprovalsRequired = *req.ApprovalsRequired
+	}
+	if req.MaxOpenReviews != nil {
+		settings.MaxOpenReviews = *req.MaxOpenReviews
+	}
+	if req.ReviewSLAHours != nil {
+		settings.ReviewSLAHours = *req.ReviewSLAHours
+	}
+	if req.AutoReassignOverdue != nil {
+		settings.AutoReassignOverdue = *req.AutoReassignOverdue
+	}
+	if req.RequireLead != nil {
+		settings.RequireLead = *req.RequireLead
+	}
+	if req.RotationWindowDays != nil {
+		settings.RotationWindowDays = *req.RotationWindowDays
+	}
+
+	if err := h.teamService.UpdateSettings(settings); err != nil {
+		switch err {
+		case service.ErrInvalidSettings:
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": gin.H{"code": CodeInvalidInput, "message": "invalid team settings"},
+			})
+		case service.ErrTeamNotFound:
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"error": gin.H{"code": CodeTeamNotFound, "message": "team not found"},
+			})
+		default:
+			h.logger.Warnf("failed to update settings for team %s: %v", req.TeamName, err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error": gin.H{"code": CodeUnknownError, "message": err.Error()},
+			})
+		}
+		return
+	}
+
+	h.logger.Infof("settings of team %s updated", req.TeamName)
+	ctx.JSON(http.StatusOK, gin.H{"settings": settings})
+}
+
+// ensureTeamExists отвечает 404/500 и возвращает false, если команды нет
+func (h *TeamHandler) ensureTeamExists(ctx *gin.Context, teamName string) bool {
+	exists, err := h.teamService.TeamExists(teamName)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": gin.H{"code": CodeUnknownError, "message": err.Error()},
+		})
+		return false
+	}
+	if !exists {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": gin.H{"code": CodeTeamNotFound, "message": "team not found"},
+		})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/service"
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/teamfile"
+	"github.com/gin-gonic/gin"
+)
+
+// content type выгрузки по формату
+var teamFileContentTypes = map[string]string{
+	teamfile.FormatCSV:  "text/csv; charset=utf-8",
+	teamfile.FormatYAML: "application/yaml; charset=utf-8",
+}
+
+/*
+	 массовый импорт команд из файла, тело запроса - сам файл
+		POST /import/teams?format=csv&dry_run=true
+		Parameters:
+			format (csv | yaml, optional) - по умолчанию по Content-Type (text/csv, application/yaml), иначе csv
+			dry_run (bool, optional) - только посчитать изменения, ничего не сохраняя
+		Body (CSV):
+			team,user_id,username,is_active
+			backend,u1,Alice,true
+		Body (YAML):
+			teams:
+			  - team_name: backend
+			    members:
+			      - { user_id: u1, username: Alice, is_active: true }
+		Response:
+			200 { "dry_run": false, "teams_created": ["backend"], "users_created": ["u1"], "users_updated": [],
+			      "users_moved": [ { "user_id": "u2", "from": "frontend", "to": "backend" } ], "unchanged": 0 }
+				(команда в файле - основная команда пользователя, пользователи не из файла не меняются)
+			400 INVALID_INPUT - неизвестный формат или ошибки в файле (перечислены в message по строкам)
+*/
+func (h *TeamHandler) ImportTeams(ctx *gin.Context) {
+	format := ctx.Query("format")
+	if format == "" {
+		format = formatFromContentType(ctx.ContentType())
+	}
+	dryRun := false
+	if raw := ctx.Query("dry_run"); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": CodeInvalidInput, "message": "dry_run must be true or false"}})
+			return
+		}
+		dryRun = parsed
+	}
+
+	content, err := ctx.GetRawData()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": CodeInvalidInput, "message": err.Error()}})
+		return
+	}
+
+	report, err := h.teamService.ImportTeams(format, content, dryRun)
+	if err != nil {
+		h.teamFileError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, report)
+}
+
+/*
+	 выгрузка всех команд в формате импорта (повторный импорт ничего не меняет)
+		GET /export/teams?format=csv
+		Parameters:
+			format (csv | yaml, optional) - по умолчанию csv
+		Response:
+			200 - файл команд (в CSV не попадают команды без участников)
+			400 INVALID_INPUT - неизвестный формат
+*/
+func (h *TeamHandler) ExportTeams(ctx *gin.Context) {
+	format := ctx.DefaultQuery("format", teamfile.FormatCSV)
+
+	content, err := h.teamService.ExportTeams(format)
+	if err != nil {
+		h.teamFileError(ctx, err)
+		return
+	}
+	ctx.Header("Content-Disposition", "attachment; filename=teams."+format)
+	ctx.Data(http.StatusOK, teamFileContentTypes[format], content)
+}
+
+// teamFileError отвечает ошибкой импорта или экспорта команд
+func (h *TeamHandler) teamFileError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrUnknownFormat):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": CodeInvalidInput, "message": "format must be csv or yaml"}})
+	case errors.Is(err, service.ErrInvalidTeamFile):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": CodeInvalidInput, "message": err.Error()}})
+	default:
+		h.logger.Warnf("team file operation failed: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": gin.H{"code": CodeUnknownError, "message": err.Error()}})
+	}
+}
+
+// formatFromContentType определяет формат файла команд по Content-Type, по умолчанию csv
+func formatFromContentType(contentType string) string {
+	if strings.Contains(contentType, "yaml") {
+		return teamfile.FormatYAML
+	}
+	return teamfile.FormatCSV
+}
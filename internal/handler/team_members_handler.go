@@ -0,0 +1,269 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/domain"
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	CodeNotTeamMember = "NOT_TEAM_MEMBER"
+	CodeAlreadyMember = "ALREADY_MEMBER"
+	// состав команды изменен, но переназначить ревью выбывших удалось не все
+	CodeReassignmentIncomplete = "REASSIGNMENT_INCOMPLETE"
+)
+
+/*
+	 добавление участников в существующую команду, участник другой команды состоит в обеих
+		POST /team/members/add
+		Body:
+			{
+				"team_name": "team1",
+				"members": [ { "user_id": "user3", "username": "Olga", "is_active": true, "role": "lead" } ]
+			}
+			(role: member, lead или maintainer, по умолчанию member)
+		Response:
+			200 { "team": { team object } }
+			400 INVALID_INPUT - некорректное тело запроса или роль
+			404 NOT_FOUND - команда не найдена
+			409 ALREADY_MEMBER - пользователь уже в этой команде
+*/
+func (h *TeamHandler) AddMembers(ctx *gin.Context) {
+	var req domain.Team
+	if err := ctx.ShouldBindJSON(&req); err != nil || req.TeamName == "" || len(req.Members) == 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": gin.H{"code": CodeInvalidInput, "message": "team_name and members are required"},
+		})
+		return
+	}
+
+	if err := h.teamService.AddMembers(req.TeamName, req.Members); err != nil {
+		h.membershipError(ctx, err)
+		return
+	}
+
+	team, err := h.teamService.GetTeam(req.TeamName)
+	if err != nil {
+		h.membershipError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"team": team})
+}
+
+/*
+	 удаление участника из команды, его открытые ревью в PR этой команды переназначаются
+	 (все открытые ревью, если других команд у него не осталось)
+		POST /team/members/remove
+		Body:
+			{ "team_name": "team1", "user_id": "user3" }
+		Response:
+			200 { "team_name": "team1", "user_id": "user3", "reassigned_reviews": 2 }
+				(если переназначение прервалось ошибкой, участник все равно удален, а в ответе
+				warnings: ["REASSIGNMENT_INCOMPLETE"] и reassign_error)
+			400 INVALID_INPUT - некорректное тело запроса
+			404 NOT_FOUND - команда не найдена
+			409 NOT_TEAM_MEMBER - пользователь не состоит в команде
+*/
+func (h *TeamHandler) RemoveMember(ctx *gin.Context) {
+	var req struct {
+		TeamName string `json:"team_name"`
+		UserID   string `json:"user_id"`
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil || req.TeamName == "" || req.UserID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": gin.H{"code": CodeInvalidInput, "message": "team_name and user_id are required"},
+		})
+		return
+	}
+
+	if err := h.teamService.RemoveMember(req.TeamName, req.UserID); err != nil {
+		h.membershipError(ctx, err)
+		return
+	}
+
+	result := gin.H{"team_name": req.TeamName, "user_id": req.UserID}
+	h.reassignRemoved(result, req.TeamName, []string{req.UserID})
+	ctx.JSON(http.StatusOK, result)
+}
+
+/*
+	 перевод пользователя из основной команды в другую, его открытые ревью в PR прежней команды
+	 переназначаются, как при /team/members/remove
+		POST /team/members/move
+		Body:
+			{ "user_id": "user3", "team_name": "team2" }
+		Response:
+			200 { "user": { user object }, "previous_team": "team1", "reassigned_reviews": 1 }
+				(частичный отказ переназначения - как в /team/members/remove)
+			400 INVALID_INPUT - некорректное тело запроса
+			404 NOT_FOUND - команда или пользователь не найдены
+			409 ALREADY_MEMBER - пользователь уже в этой команде
+*/
+func (h *TeamHandler) MoveMember(ctx *gin.Context) {
+	var req struct {
+		UserID   string `json:"user_id"`
+		TeamName string `json:"team_name"`
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil || req.TeamName == "" || req.UserID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": gin.H{"code": CodeInvalidInput, "message": "user_id and team_name are required"},
+		})
+		return
+	}
+
+	previous, err := h.teamService.MoveMember(req.UserID, req.TeamName)
+	if err != nil {
+		h.membershipError(ctx, err)
+		return
+	}
+
+	user, err := h.userService.GetByID(req.UserID)
+	if err != nil {
+		h.membershipError(ctx, err)
+		return
+	}
+
+	result := gin.H{"user": user, "previous_team": previous, "reassigned_reviews": 0}
+	if previous != "" {
+		h.reassignRemoved(result, previous, []string{req.UserID})
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
+/*
+	 назначение роли участнику команды
+		POST /team/members/setRole
+		Body:
+			{ "team_name": "team1", "user_id": "user3", "role": "lead" }
+		Response:
+			200 { "team_name": "team1", "user_id": "user3", "role": "lead", "previous_role": "member" }
+			400 INVALID_INPUT - некорректное тело запроса или роль
+			404 NOT_FOUND - команда не найдена
+			409 NOT_TEAM_MEMBER - пользователь не состоит в команде
+*/
+func (h *TeamHandler) SetMemberRole(ctx *gin.Context) {
+	var req struct {
+		TeamName string `json:"team_name"`
+		UserID   string `json:"user_id"`
+		Role     string `json:"role"`
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil || req.TeamName == "" || req.UserID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": gin.H{"code": CodeInvalidInput, "message": "team_name, user_id and role are required"},
+		})
+		return
+	}
+
+	previous, err := h.teamService.SetMemberRole(req.TeamName, req.UserID, req.Role)
+	if err != nil {
+		h.membershipError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"team_name": req.TeamName, "user_id": req.UserID, "role": req.Role, "previous_role": previous})
+}
+
+/*
+	 переименование команды вместе с настройками и участниками
+		POST /team/rename
+		Body:
+			{ "team_name": "team1", "new_team_name": "payments" }
+		Response:
+			200 { "team": { team object } }
+			400 INVALID_INPUT - некорректное тело запроса
+			400 TEAM_EXISTS - команда с новым именем уже есть
+			404 NOT_FOUND - команда не найдена
+*/
+func (h *TeamHandler) RenameTeam(ctx *gin.Context) {
+	var req struct {
+		TeamName    string `json:"team_name"`
+		NewTeamName string `json:"new_team_name"`
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil || req.TeamName == "" || req.NewTeamName == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": gin.H{"code": CodeInvalidInput, "message": "team_name and new_team_name are required"},
+		})
+		return
+	}
+
+	if err := h.teamService.RenameTeam(req.TeamName, req.NewTeamName); err != nil {
+		h.membershipError(ctx, err)
+		return
+	}
+
+	members, err := h.userService.ListByTeam(req.NewTeamName)
+	if err != nil {
+		h.membershipError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"team": &domain.Team{TeamName: req.NewTeamName, Members: members}})
+}
+
+/*
+	 удаление команды: участники выходят из нее, как при /team/members/remove,
+	 их открытые ревью переназначаются так же
+		POST /team/delete
+		Body:
+			{ "team_name": "team1" }
+		Response:
+			200 { "team_name": "team1", "removed_users": ["user1", "user2"], "reassigned_reviews": 3 }
+				(частичный отказ переназначения - как в /team/members/remove)
+			400 INVALID_INPUT - некорректное тело запроса
+			404 NOT_FOUND - команда не найдена
+*/
+func (h *TeamHandler) DeleteTeam(ctx *gin.Context) {
+	var req struct {
+		TeamName string `json:"team_name"`
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil || req.TeamName == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": gin.H{"code": CodeInvalidInput, "message": "team_name is required"},
+		})
+		return
+	}
+
+	removed, err := h.teamService.DeleteTeam(req.TeamName)
+	if err != nil {
+		h.membershipError(ctx, err)
+		return
+	}
+
+	result := gin.H{"team_name": req.TeamName, "removed_users": removed}
+	h.reassignRemoved(result, req.TeamName, removed)
+	ctx.JSON(http.StatusOK, result)
+}
+
+// reassignRemoved переназначает открытые ревью пользователей, выведенных из команды, и дописывает
+// итог в ответ. Состав команды к этому моменту уже сохранен, поэтому ошибка переназначения
+// не превращает ответ в 500, а возвращается как частичный отказ с числом успевших ревью
+func (h *TeamHandler) reassignRemoved(result gin.H, teamName string, userIDs []string) {
+	reassigned, err := h.prService.ReassignReviewsOfRemovedUsers(teamName, userIDs)
+	result["reassigned_reviews"] = reassigned
+	if err != nil {
+		h.logger.Errorf("failed to reassign reviews of %v removed from team %s: %v", userIDs, teamName, err)
+		result["warnings"] = []string{CodeReassignmentIncomplete}
+		result["reassign_error"] = err.Error()
+	}
+}
+
+// membershipError отвечает ошибкой операции над составом команды
+func (h *TeamHandler) membershipError(ctx *gin.Context, err error) {
+	switch err {
+	case service.ErrTeamNotFound:
+		ctx.JSON(http.StatusNotFound, gin.H{"error": gin.H{"code": CodeTeamNotFound, "message": "team not found"}})
+	case service.ErrUserNotFound:
+		ctx.JSON(http.StatusNotFound, gin.H{"error": gin.H{"code": CodeUserNotFound, "message": "user not found"}})
+	case service.ErrInvalidRole:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": CodeInvalidInput, "message": "role must be member, lead or maintainer"}})
+	case service.ErrTeamExists:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": CodeTeamExists, "message": "team_name already exists"}})
+	case service.ErrNotTeamMember:
+		ctx.JSON(http.StatusConflict, gin.H{"error": gin.H{"code": CodeNotTeamMember, "message": "user is not a member of the team"}})
+	case service.ErrAlreadyTeamMember:
+		ctx.JSON(http.StatusConflict, gin.H{"error": gin.H{"code": CodeAlreadyMember, "message": "user is already a member of the team"}})
+	default:
+		h.logger.Warnf("team membership operation failed: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": gin.H{"code": CodeUnknownError, "message": err.Error()}})
+	}
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/domain"
@@ -76,6 +77,157 @@ func (h *UserHandler) SetIsActive(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, gin.H{"user": user})
 }
 
+/*
+	  замена тегов пользователя (навыки для подбора ревьюверов по меткам PR)
+		POST /users/setTags
+		Body:
+		{
+			"user_id": "user",
+			"tags": ["go", "db"]
+		}
+		Responses:
+			200: { "user": {user object with tags} }
+			404: { "error": { "code": "NOT_FOUND", "message": "user not found" } }
+			400: { "error": { "code": "INVALID_INPUT", "message": "..." } }
+*/
+func (h *UserHandler) SetTags(ctx *gin.Context) {
+	var req struct {
+		UserID string   `json:"user_id"`
+		Tags   []string `json:"tags"`
+	}
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": gin.H{
+				"code":    CodeInvalidInput,
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+
+	user, err := h.userService.SetTags(req.UserID, req.Tags)
+	if err != nil {
+		h.logger.Warnf("failed to set tags for user %s: %v", req.UserID, err)
+		if errors.Is(err, service.ErrUserNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"error": gin.H{
+					"code":    CodeUserNotFound,
+					"message": err.Error(),
+				},
+			})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": gin.H{
+				"code":    CodeUnknownError,
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"user": user})
+}
+
+/*
+	  личный лимит одновременно открытых ревью пользователя
+		POST /users/setCapacity
+		Body:
+		{
+			"user_id": "user",
+			"max_open_reviews": 3 (0 - без ограничения, null - лимит команды)
+		}
+		Responses:
+			200: { "user": {user object with max_open_reviews} }
+			404: { "error": { "code": "NOT_FOUND", "message": "user not found" } }
+			400: { "error": { "code": "INVALID_INPUT", "message": "..." } }
+*/
+func (h *UserHandler) SetCapacity(ctx *gin.Context) {
+	var req struct {
+		UserID         string `json:"user_id"`
+		MaxOpenReviews *int   `json:"max_open_reviews"`
+	}
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": gin.H{
+				"code":    CodeInvalidInput,
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+
+	user, err := h.userService.SetMaxOpenReviews(req.UserID, req.MaxOpenReviews)
+	if err != nil {
+		h.logger.Warnf("failed to set capacity for user %s: %v", req.UserID, err)
+		switch {
+		case errors.Is(err, service.ErrInvalidCapacity):
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": gin.H{
+					"code":    CodeInvalidInput,
+					"message": "max_open_reviews must not be negative",
+				},
+			})
+		case errors.Is(err, service.ErrUserNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"error": gin.H{
+					"code":    CodeUserNotFound,
+					"message": err.Error(),
+				},
+			})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error": gin.H{
+					"code":    CodeUnknownError,
+					"message": err.Error(),
+				},
+			})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"user": user})
+}
+
+/*
+	 история пользователя: назначения, переназначения, ревью и смены активности
+		GET /users/history?user_id=user1
+		Responses:
+			200: { "user_id": "user1", "events": [ { "event_id": 3, "event_type": "REVIEWER_REASSIGNED", ... } ] }
+			404: { "error": { "code": "NOT_FOUND", "message": "user not found" } }
+*/
+func (h *UserHandler) GetHistory(ctx *gin.Context) {
+	userID := ctx.Query("user_id")
+
+	events, err := h.userService.GetHistory(userID)
+	if err != nil {
+		h.logger.Warnf("failed to get history of user %s: %v", userID, err)
+		if errors.Is(err, service.ErrUserNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"error": gin.H{
+					"code":    CodeUserNotFound,
+					"message": err.Error(),
+				},
+			})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": gin.H{
+				"code":    CodeUnknownError,
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"user_id": userID,
+		"events":  events,
+	})
+}
+
 /*
 	 возвращает PR, где пользователь назначен ревьювером
 		GET /users/getReview?user_id=user1
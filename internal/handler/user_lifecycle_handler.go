@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/domain"
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/service"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+var (
+	CodeUserExists      = "USER_EXISTS"
+	CodeHandoffConflict = "HANDOFF_CONFLICT"
+)
+
+type UserLifecycleHandler struct {
+	lifecycleService *service.UserLifecycleService
+	logger           *zap.SugaredLogger
+}
+
+func NewUserLifecycleHandler(lifecycleService *service.UserLifecycleService, logger *zap.SugaredLogger) *UserLifecycleHandler {
+	return &UserLifecycleHandler{
+		lifecycleService: lifecycleService,
+		logger:           logger,
+	}
+}
+
+/*
+	 создание пользователя в существующей команде
+		POST /users/create
+		Body:
+			{ "user_id": "user9", "username": "Nina", "team_name": "team1", "is_active": true }
+		Response:
+			201 { "user": { user object } }
+			400 INVALID_INPUT - не заданы user_id, username или team_name
+			404 NOT_FOUND - команда не найдена
+			409 USER_EXISTS - пользователь уже существует (удаленного можно создать заново)
+*/
+func (h *UserLifecycleHandler) CreateUser(ctx *gin.Context) {
+	var req struct {
+		UserID   string `json:"user_id"`
+		Username string `json:"username"`
+		TeamName string `json:"team_name"`
+		IsActive *bool  `json:"is_active"`
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": CodeInvalidInput, "message": err.Error()}})
+		return
+	}
+
+	user := &domain.User{UserID: req.UserID, Username: req.Username, TeamName: req.TeamName, IsActive: true}
+	if req.IsActive != nil {
+		user.IsActive = *req.IsActive
+	}
+
+	created, err := h.lifecycleService.Create(user)
+	if err != nil {
+		h.writeError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, gin.H{"user": created})
+}
+
+/*
+	 изменение имени и команды пользователя
+		POST /users/update
+		Body (незаданные поля остаются прежними):
+			{ "user_id": "user9", "username": "Nina K.", "team_name": "team2" }
+		Response:
+			200 { "user": { user object }, "reassigned_reviews": 1 }
+				(при смене команды открытые ревью в PR прежней команды переназначаются, как в
+				/team/members/remove, включая ответ при частичном отказе)
+			400 INVALID_INPUT - пустое имя или команда
+			404 NOT_FOUND - пользователь или команда не найдены
+*/
+func (h *UserLifecycleHandler) UpdateUser(ctx *gin.Context) {
+	var req struct {
+		UserID   string  `json:"user_id"`
+		Username *string `json:"username"`
+		TeamName *string `json:"team_name"`
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil || req.UserID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": CodeInvalidInput, "message": "user_id is required"}})
+		return
+	}
+
+	update, err := h.lifecycleService.Update(req.UserID, req.Username, req.TeamName)
+	if err != nil {
+		h.writeError(ctx, err)
+		return
+	}
+
+	result := gin.H{"user": update.User, "reassigned_reviews": update.ReassignedReviews}
+	if update.ReassignErr != nil {
+		result["warnings"] = []string{CodeReassignmentIncomplete}
+		result["reassign_error"] = update.ReassignErr.Error()
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
+/*
+	 удаление пользователя: открытые ревью переназначаются или снимаются, незавершенные PR
+	 передаются transfer_to или помечаются orphaned, все в одной транзакции
+		POST /users/delete
+		Body:
+			{ "user_id": "user9", "transfer_to": "user1" } (transfer_to необязателен)
+		Response:
+			200 { "user_id": "user9", "reassigned_reviews": 2, "unassigned_reviews": 0, "transferred_prs": 1, "orphaned_prs": 0 }
+			400 INVALID_INPUT - transfer_to совпадает с удаляемым пользователем
+			404 NOT_FOUND - пользователь или transfer_to не найдены
+			409 HANDOFF_CONFLICT - ревью пользователя параллельно менялись, запрос можно повторить
+*/
+func (h *UserLifecycleHandler) DeleteUser(ctx *gin.Context) {
+	var req struct {
+		UserID     string `json:"user_id"`
+		TransferTo string `json:"transfer_to"`
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil || req.UserID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": CodeInvalidInput, "message": "user_id is required"}})
+		return
+	}
+
+	result, err := h.lifecycleService.Delete(req.UserID, req.TransferTo)
+	if err != nil {
+		h.writeError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
+// writeError отвечает ошибкой сервиса жизненного цикла пользователей
+func (h *UserLifecycleHandler) writeError(ctx *gin.Context, err error) {
+	switch err {
+	case service.ErrInvalidUser:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": CodeInvalidInput, "message": "invalid user fields"}})
+	case service.ErrUserNotFound:
+		ctx.JSON(http.StatusNotFound, gin.H{"error": gin.H{"code": CodeUserNotFound, "message": "user not found"}})
+	case service.ErrTransferTargetNotFound:
+		ctx.JSON(http.StatusNotFound, gin.H{"error": gin.H{"code": CodeUserNotFound, "message": "transfer_to user not found"}})
+	case service.ErrTeamNotFound:
+		ctx.JSON(http.StatusNotFound, gin.H{"error": gin.H{"code": CodeTeamNotFound, "message": "team not found"}})
+	case service.ErrUserExists:
+		ctx.JSON(http.StatusConflict, gin.H{"error": gin.H{"code": CodeUserExists, "message": "user already exists"}})
+	case service.ErrHandoffConflict:
+		ctx.JSON(http.StatusConflict, gin.H{"error": gin.H{"code": CodeHandoffConflict, "message": "reviews of the user changed concurrently, retry"}})
+	default:
+		h.logger.Warnf("user lifecycle operation failed: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": gin.H{"code": CodeUnknownError, "message": err.Error()}})
+	}
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/domain"
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/repository/queries"
+	"go.uber.org/zap"
+)
+
+// AbsenceRepo - репо периодов отсутствия пользователей
+type AbsenceRepo struct {
+	db     *sql.DB
+	logger *zap.SugaredLogger
+}
+
+// NewAbsenceRepo создает новое репо отсутствий
+func NewAbsenceRepo(db *sql.DB, logger *zap.SugaredLogger) *AbsenceRepo {
+	return &AbsenceRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Create сохраняет отсутствие и заполняет его id и время создания
+func (r *AbsenceRepo) Create(absence *domain.Absence) error {
+	err := r.db.QueryRow(queries.InsertAbsence, absence.UserID, absence.StartsAt, absence.EndsAt, absence.Reason).
+		Scan(&absence.ID, &absence.CreatedAt)
+	if err != nil {
+		r.logger.Errorf("SQL error: failed to create absence for user %s: %v", absence.UserID, err)
+		return err
+	}
+	return nil
+}
+
+// ListByUser возвращает отсутствия пользователя, которые еще не закончились
+func (r *AbsenceRepo) ListByUser(userID string) ([]*domain.Absence, error) {
+	rows, err := r.db.Query(queries.SelectAbsencesByUser, userID)
+	if err != nil {
+		r.logger.Errorf("SQL error: failed to list absences of user %s: %v", userID, err)
+		return nil, err
+	}
+	return r.scanAbsences(rows)
+}
+
+// ListStartedPending возвращает начавшиеся отсутствия, ревью которых еще не переназначены
+func (r *AbsenceRepo) ListStartedPending() ([]*domain.Absence, error) {
+	rows, err := r.db.Query(queries.SelectStartedPendingAbsences)
+	if err != nil {
+		r.logger.Errorf("SQL error: failed to list started absences: %v", err)
+		return nil, err
+	}
+	return r.scanAbsences(rows)
+}
+
+// MarkReassigned отмечает, что ревью отсутствующего уже переназначены
+func (r *AbsenceRepo) MarkReassigned(absenceID int64) error {
+	_, err := r.db.Exec(queries.UpdateAbsenceReassigned, absenceID)
+	return err
+}
+
+// scanAbsences читает отсутствия из sql.Rows
+func (r *AbsenceRepo) scanAbsences(rows *sql.Rows) ([]*domain.Absence, error) {
+	defer func() {
+		if err := rows.Close(); err != nil {
+			r.logger.Errorf("rows close failed: %v", err)
+		}
+	}()
+
+	absences := []*domain.Absence{}
+	for rows.Next() {
+		a := &domain.Absence{}
+		if err := rows.Scan(&a.ID, &a.UserID, &a.StartsAt, &a.EndsAt, &a.Reason, &a.ReviewsReassigned, &a.CreatedAt); err != nil {
+			r.logger.Errorf("SQL error: failed to scan absence row: %v", err)
+			return nil, err
+		}
+		absences = append(absences, a)
+	}
+	return absences, rows.Err()
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/repository/queries"
+	"go.uber.org/zap"
+)
+
+// DeliveryRepo - репо принятых доставок вебхуков GitHub
+type DeliveryRepo struct {
+	db     *sql.DB
+	logger *zap.SugaredLogger
+}
+
+// NewDeliveryRepo создает новое репо доставок
+func NewDeliveryRepo(db *sql.DB, logger *zap.SugaredLogger) *DeliveryRepo {
+	return &DeliveryRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// RecordGitHubDelivery запоминает доставку и сообщает, новая ли она; false - доставка
+// с этим id уже принята
+func (r *DeliveryRepo) RecordGitHubDelivery(deliveryID, event string) (bool, error) {
+	res, err := r.db.Exec(queries.InsertGitHubDelivery, deliveryID, event)
+	if err != nil {
+		r.logger.Errorf("failed to record github delivery %s: %v", deliveryID, err)
+		return false, err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// DeleteGitHubDelivery забывает доставку, чтобы ее повтор обработался заново
+func (r *DeliveryRepo) DeleteGitHubDelivery(deliveryID string) error {
+	if _, err := r.db.Exec(queries.DeleteGitHubDelivery, deliveryID); err != nil {
+		r.logger.Errorf("failed to delete github delivery %s: %v", deliveryID, err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/domain"
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/repository/db"
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/repository/queries"
+	"go.uber.org/zap"
+)
+
+// insertEvent дописывает событие в журнал pr_events в транзакции самой мутации
+func insertEvent(exec db.Executor, event *domain.PREvent) error {
+	details := []byte("{}")
+	if len(event.Details) > 0 {
+		var err error
+		if details, err = json.Marshal(event.Details); err != nil {
+			return err
+		}
+	}
+
+	_, err := exec.Exec(queries.InsertPREvent, event.Type, event.PRID, event.UserID, event.OldUserID, details)
+	return err
+}
+
+// scanEvents читает события журнала из sql.Rows
+func scanEvents(rows *sql.Rows, logger *zap.SugaredLogger) ([]*domain.PREvent, error) {
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logger.Errorf("rows close failed: %v", err)
+		}
+	}()
+
+	events := []*domain.PREvent{}
+	for rows.Next() {
+		event := &domain.PREvent{}
+		var details []byte
+		if err := rows.Scan(&event.ID, &event.Type, &event.PRID, &event.UserID, &event.OldUserID, &details, &event.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(details, &event.Details); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
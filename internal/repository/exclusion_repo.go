@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/domain"
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/repository/queries"
+	"go.uber.org/zap"
+)
+
+// ExclusionRepo - репо правил исключения пар автор-ревьювер
+type ExclusionRepo struct {
+	db     *sql.DB
+	logger *zap.SugaredLogger
+}
+
+// NewExclusionRepo создает новое репо правил исключения
+func NewExclusionRepo(db *sql.DB, logger *zap.SugaredLogger) *ExclusionRepo {
+	return &ExclusionRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// CreateExclusions атомарно добавляет правила, уже существующие пропускаются; возвращает новые
+func (r *ExclusionRepo) CreateExclusions(rules []*domain.ReviewExclusion) ([]*domain.ReviewExclusion, error) {
+	created := []*domain.ReviewExclusion{}
+	err := RunInTx(r.db, r.logger, func(tx *sql.Tx) error {
+		for _, rule := range rules {
+			err := tx.QueryRow(queries.InsertReviewExclusion, rule.ReviewerID, rule.AuthorID, rule.Reason).Scan(&rule.CreatedAt)
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			if err != nil {
+				r.logger.Errorf("failed to insert exclusion %s -> %s: %v", rule.ReviewerID, rule.AuthorID, err)
+				return err
+			}
+			created = append(created, rule)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// DeleteExclusions атомарно удаляет правила и возвращает число удаленных
+func (r *ExclusionRepo) DeleteExclusions(rules []*domain.ReviewExclusion) (int, error) {
+	deleted := 0
+	err := RunInTx(r.db, r.logger, func(tx *sql.Tx) error {
+		for _, rule := range rules {
+			res, err := tx.Exec(queries.DeleteReviewExclusion, rule.ReviewerID, rule.AuthorID)
+			if err != nil {
+				return err
+			}
+			rows, err := res.RowsAffected()
+			if err != nil {
+				return err
+			}
+			deleted += int(rows)
+		}
+		return nil
+	})
+	return deleted, err
+}
+
+// ListExclusions возвращает правила, в которых участвует пользователь, а при пустом userID - все
+func (r *ExclusionRepo) ListExclusions(userID string) ([]*domain.ReviewExclusion, error) {
+	rows, err := r.db.Query(queries.SelectReviewExclusions, userID)
+	if err != nil {
+		r.logger.Errorf("failed to list exclusions of %q: %v", userID, err)
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			r.logger.Errorf("rows close failed: %v", err)
+		}
+	}()
+
+	rules := []*domain.ReviewExclusion{}
+	for rows.Next() {
+		rule := &domain.ReviewExclusion{}
+		if err := rows.Scan(&rule.ReviewerID, &rule.AuthorID, &rule.Reason, &rule.CreatedAt); err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+// ListExcludedReviewers возвращает пользователей, которым запрещено ревьюить PR автора
+func (r *ExclusionRepo) ListExcludedReviewers(authorID string) ([]string, error) {
+	rows, err := r.db.Query(queries.SelectExcludedReviewers, authorID)
+	if err != nil {
+		r.logger.Errorf("failed to list excluded reviewers of %s: %v", authorID, err)
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			r.logger.Errorf("rows close failed: %v", err)
+		}
+	}()
+
+	reviewers := []string{}
+	for rows.Next() {
+		var reviewerID string
+		if err := rows.Scan(&reviewerID); err != nil {
+			return nil, err
+		}
+		reviewers = append(reviewers, reviewerID)
+	}
+	return reviewers, rows.Err()
+}
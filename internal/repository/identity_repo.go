@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/domain"
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/repository/queries"
+	"go.uber.org/zap"
+)
+
+var ErrIdentityNotFound = errors.New("github login is not mapped to a user")
+
+// IdentityRepo - репо соответствий внешних логинов пользователям сервиса
+type IdentityRepo struct {
+	db     *sql.DB
+	logger *zap.SugaredLogger
+}
+
+// NewIdentityRepo создает новое репо соответствий логинов
+func NewIdentityRepo(db *sql.DB, logger *zap.SugaredLogger) *IdentityRepo {
+	return &IdentityRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// SaveGitHubIdentity привязывает логин GitHub к пользователю, заменяя прежнюю привязку логина
+func (r *IdentityRepo) SaveGitHubIdentity(identity *domain.GitHubIdentity) error {
+	err := r.db.QueryRow(queries.UpsertGitHubIdentity, identity.GitHubLogin, identity.UserID).Scan(&identity.CreatedAt)
+	if err != nil {
+		r.logger.Errorf("failed to save github identity %s -> %s: %v", identity.GitHubLogin, identity.UserID, err)
+		return err
+	}
+	return nil
+}
+
+// DeleteGitHubIdentity удаляет привязку логина и сообщает, была ли она
+func (r *IdentityRepo) DeleteGitHubIdentity(login string) (bool, error) {
+	res, err := r.db.Exec(queries.DeleteGitHubIdentity, login)
+	if err != nil {
+		r.logger.Errorf("failed to delete github identity %s: %v", login, err)
+		return false, err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// GetUserIDByGitHubLogin возвращает пользователя, привязанного к логину
+func (r *IdentityRepo) GetUserIDByGitHubLogin(login string) (string, error) {
+	var userID string
+	err := r.db.QueryRow(queries.SelectUserByGitHubLogin, login).Scan(&userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrIdentityNotFound
+	}
+	if err != nil {
+		r.logger.Errorf("failed to get user by github login %s: %v", login, err)
+		return "", err
+	}
+	return userID, nil
+}
+
+// ListGitHubIdentities возвращает привязки пользователя, а при пустом userID - все
+func (r *IdentityRepo) ListGitHubIdentities(userID string) ([]*domain.GitHubIdentity, error) {
+	rows, err := r.db.Query(queries.SelectGitHubIdentities, userID)
+	if err != nil {
+		r.logger.Errorf("failed to list github identities of %q: %v", userID, err)
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			r.logger.Errorf("rows close failed: %v", err)
+		}
+	}()
+
+	identities := []*domain.GitHubIdentity{}
+	for rows.Next() {
+		identity := &domain.GitHubIdentity{}
+		if err := rows.Scan(&identity.GitHubLogin, &identity.UserID, &identity.CreatedAt); err != nil {
+			return nil, err
+		}
+		identities = append(identities, identity)
+	}
+	return identities, rows.Err()
+}
@@ -0,0 +1,23 @@
+package interfaces
+
+import (
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/domain"
+)
+
+// только чтение
+type AbsenceReader interface {
+	ListByUser(userID string) ([]*domain.Absence, error)
+	ListStartedPending() ([]*domain.Absence, error)
+}
+
+// только запись
+type AbsenceWriter interface {
+	Create(absence *domain.Absence) error
+	MarkReassigned(absenceID int64) error
+}
+
+// полный интерфейс репо
+type AbsenceRepo interface {
+	AbsenceReader
+	AbsenceWriter
+}
@@ -0,0 +1,7 @@
+package interfaces
+
+// журнал принятых доставок вебхуков
+type DeliveryRepo interface {
+	RecordGitHubDelivery(deliveryID, event string) (bool, error)
+	DeleteGitHubDelivery(deliveryID string) error
+}
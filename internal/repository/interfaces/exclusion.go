@@ -0,0 +1,23 @@
+package interfaces
+
+import (
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/domain"
+)
+
+// только чтение
+type ExclusionReader interface {
+	ListExclusions(userID string) ([]*domain.ReviewExclusion, error)
+	ListExcludedReviewers(authorID string) ([]string, error)
+}
+
+// только запись
+type ExclusionWriter interface {
+	CreateExclusions(rules []*domain.ReviewExclusion) ([]*domain.ReviewExclusion, error)
+	DeleteExclusions(rules []*domain.ReviewExclusion) (int, error)
+}
+
+// полный интерфейс репо
+type ExclusionRepo interface {
+	ExclusionReader
+	ExclusionWriter
+}
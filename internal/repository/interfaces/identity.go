@@ -0,0 +1,23 @@
+package interfaces
+
+import (
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/domain"
+)
+
+// только чтение
+type IdentityReader interface {
+	GetUserIDByGitHubLogin(login string) (string, error)
+	ListGitHubIdentities(userID string) ([]*domain.GitHubIdentity, error)
+}
+
+// только запись
+type IdentityWriter interface {
+	SaveGitHubIdentity(identity *domain.GitHubIdentity) error
+	DeleteGitHubIdentity(login string) (bool, error)
+}
+
+// полный интерфейс репо
+type IdentityRepo interface {
+	IdentityReader
+	IdentityWriter
+}
@@ -0,0 +1,21 @@
+package interfaces
+
+import (
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/domain"
+)
+
+// только чтение
+type OwnershipReader interface {
+	ListRules(repository string) ([]*domain.CodeOwnerRule, error)
+}
+
+// только запись
+type OwnershipWriter interface {
+	ReplaceRules(repository string, rules []*domain.CodeOwnerRule) error
+}
+
+// полный интерфейс репо
+type OwnershipRepo interface {
+	OwnershipReader
+	OwnershipWriter
+}
@@ -4,18 +4,42 @@ import (
 	"time"
 
 	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/domain"
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/repository/db"
 )
 
 type PullRequestReader interface {
 	GetPRByID(prID string) (*domain.PullRequest, error)
 	ListOpenPRsByTeam(teamName string) ([]*domain.PullRequest, error)
+	CountOpenReviews(userIDs []string) (map[string]int, error)
+	ListReviews(prID string) ([]*domain.Review, error)
+	ListBreachedReviews() ([]*domain.OverdueReview, error)
+	ListOverdueReviews() ([]*domain.OverdueReview, error)
+	ListEvents(prID string) ([]*domain.PREvent, error)
+	ListPRs(filter *domain.PRFilter) ([]*domain.PullRequest, error)
+	ListReviewPairsByAuthor(authorID string, reviewerIDs []string, window time.Duration) ([]*domain.ReviewPair, error)
+	ListReviewPairsByTeam(teamName string, window time.Duration) ([]*domain.ReviewPair, error)
 }
 
 type PullRequestWriter interface {
 	CreatePR(pr *domain.PullRequest) error
-	MergePR(prID string, mergedAt time.Time) error
+	MergePR(prID string, mergedAt time.Time, check func(status string, reviews []*domain.Review) error) error
+	ClosePR(prID string, closedAt time.Time) error
+	ReopenPR(prID string) error
+	MarkPRReady(prID string, reviewerIDs []string) error
+	SetLabels(prID string, labels []string) error
 	AssignReviewers(prID string, userIDs []string) error
 	UpdateReviewer(prID, oldUserID, newUserID string) error
+	AddReviewer(prID, userID string, limit int) error
+	RemoveReviewer(prID, userID string) error
+	LockReviewForHandoff(exec db.Executor, prID, userID string) (bool, error)
+	LockHandoffTarget(exec db.Executor, prID, userID string) (bool, int, error)
+	CountOpenReviewsOf(exec db.Executor, userID string) (int, error)
+	HandOffReviewer(exec db.Executor, prID, oldUserID, newUserID string) error
+	UnassignReviewer(exec db.Executor, prID, userID string) error
+	TransferAuthorship(exec db.Executor, fromUserID, toUserID string) (int64, error)
+	MarkOrphaned(exec db.Executor, authorID string) (int64, error)
+	SubmitReview(prID, userID, verdict string, reviewedAt time.Time) error
+	MarkReviewOverdue(prID, userID string, overdueAt time.Time) error
 }
 
 type PullRequestRepo interface {
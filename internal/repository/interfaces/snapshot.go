@@ -0,0 +1,11 @@
+package interfaces
+
+import (
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/domain"
+)
+
+// снимки состояния сервиса
+type SnapshotRepo interface {
+	Export() (*domain.Snapshot, error)
+	Restore(snapshot *domain.Snapshot) (*domain.SnapshotRestore, error)
+}
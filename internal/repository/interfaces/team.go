@@ -7,12 +7,22 @@ import (
 // только запись
 type TeamWriter interface {
 	CreateTeamWithUsers(teamName string, members []*domain.User) error
+	UpsertSettings(settings *domain.TeamSettings) error
+	AddMembers(teamName string, members []*domain.User) error
+	RemoveMember(teamName, userID string) error
+	MoveMember(userID, teamName string) (string, error)
+	SetMemberRole(teamName, userID, role string) (string, error)
+	RenameTeam(oldName, newName string) error
+	DeleteTeam(teamName string) ([]string, error)
+	ImportTeams(teams []*domain.Team, dryRun bool) (*domain.TeamImportReport, error)
 }
 
 // чтение
 type TeamReader interface {
 	Exists(teamName string) (bool, error)
 	GetUsersByTeam(teamName string) ([]*domain.User, error)
+	GetSettings(teamName string) (*domain.TeamSettings, error)
+	ExportTeams() ([]*domain.Team, error)
 }
 
 // полный интерфейс репо
@@ -10,13 +10,21 @@ type UserReader interface {
 	GetByID(userID string) (*domain.User, error)
 	ListByTeam(teamName string) ([]*domain.User, error)
 	ListActiveByTeam(teamName string) ([]*domain.User, error)
+	ListAvailableByIDs(userIDs []string) ([]*domain.User, error)
 	GetReviewPR(userID string) ([]*domain.PullRequestShort, error)
+	ListTagsByUsers(userIDs []string) (map[string][]string, error)
+	ListReviewLimits(userIDs []string) (map[string]int, error)
 }
 
 // только запись
 type UserWriter interface {
 	Create(exec db.Executor, user *domain.User) error
+	Insert(exec db.Executor, user *domain.User) error
+	Update(exec db.Executor, user *domain.User) error
+	Delete(exec db.Executor, userID string) error
 	SetIsActive(userID string, isActive bool) error
+	SetTags(userID string, tags []string) error
+	SetMaxOpenReviews(userID string, limit *int) error
 }
 
 // полный интерфейс репо
@@ -25,4 +33,5 @@ type UserRepo interface {
 	UserWriter
 	ListAllUsers() ([]*domain.User, error)
 	SetIsActiveByTeam(teamName string, isActive bool) error
+	ListEvents(userID string) ([]*domain.PREvent, error)
 }
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/domain"
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/repository/queries"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+// OwnershipRepo - репо правил CODEOWNERS
+type OwnershipRepo struct {
+	db     *sql.DB
+	logger *zap.SugaredLogger
+}
+
+// NewOwnershipRepo создает новое репо правил владения
+func NewOwnershipRepo(db *sql.DB, logger *zap.SugaredLogger) *OwnershipRepo {
+	return &OwnershipRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// ReplaceRules атомарно заменяет все правила репозитория, порядок правил сохраняется
+func (r *OwnershipRepo) ReplaceRules(repository string, rules []*domain.CodeOwnerRule) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			r.logger.Errorf("rollback failed: %v", err)
+		}
+	}()
+
+	if _, err := tx.Exec(queries.DeleteCodeOwnerRules, repository); err != nil {
+		r.logger.Errorf("failed to delete code owners of %s: %v", repository, err)
+		return err
+	}
+
+	for i, rule := range rules {
+		if _, err := tx.Exec(queries.InsertCodeOwnerRule, repository, i, rule.Pattern, pq.Array(rule.Users), pq.Array(rule.Teams)); err != nil {
+			r.logger.Errorf("failed to insert code owner rule %s of %s: %v", rule.Pattern, repository, err)
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ListRules возвращает правила репозитория в порядке из файла
+func (r *OwnershipRepo) ListRules(repository string) ([]*domain.CodeOwnerRule, error) {
+	rows, err := r.db.Query(queries.SelectCodeOwnerRules, repository)
+	if err != nil {
+		r.logger.Errorf("failed to list code owners of %s: %v", repository, err)
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			r.logger.Errorf("rows close failed: %v", err)
+		}
+	}()
+
+	rules := []*domain.CodeOwnerRule{}
+	for rows.Next() {
+		rule := &domain.CodeOwnerRule{}
+		if err := rows.Scan(&rule.Pattern, pq.Array(&rule.Users), pq.Array(&rule.Teams)); err != nil {
+			r.logger.Errorf("failed to scan code owner rule: %v", err)
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
@@ -2,19 +2,39 @@ package repository
 
 import (
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"time"
 
 	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/domain"
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/repository/db"
 	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/repository/queries"
+	"github.com/lib/pq"
 	"go.uber.org/zap"
 )
 
 var (
-	ErrPRExists   = errors.New("PR_EXISTS")
-	ErrPRNotFound = errors.New("PR_NOT_FOUND")
+	ErrPRExists            = errors.New("PR_EXISTS")
+	ErrPRNotFound          = errors.New("PR_NOT_FOUND")
+	ErrReviewerNotAssigned = errors.New("reviewer is not assigned to PR")
+
+	// состояние PR сменилось параллельно между чтением и изменением
+	ErrPRStateChanged = errors.New("PR state changed concurrently")
+	// пользователь уже назначен ревьювером PR
+	ErrReviewerAlreadyAssigned = errors.New("reviewer is already assigned to PR")
+	// у PR уже столько ревьюверов, сколько допускает лимит
+	ErrReviewerLimitReached = errors.New("PR reviewer limit reached")
 )
 
+// pgUniqueViolation - код ошибки Postgres при нарушении уникальности
+const pgUniqueViolation = "23505"
+
+// isUniqueViolation проверяет, что запись не вставлена из-за нарушения уникального ключа
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == pgUniqueViolation
+}
+
 // PullRequestRepo - репо PR
 type PullRequestRepo struct {
 	db     *sql.DB
@@ -22,11 +42,14 @@ type PullRequestRepo struct {
 }
 
 // NewPullRequestRepo создает новый репо PR
-func NewPullRequestRepo(db *sql.DB) *PullRequestRepo {
-	return &PullRequestRepo{db: db}
+func NewPullRequestRepo(db *sql.DB, logger *zap.SugaredLogger) *PullRequestRepo {
+	return &PullRequestRepo{
+		db:     db,
+		logger: logger,
+	}
 }
 
-// CreatePR создает запись PR в базе
+// CreatePR атомарно создает запись PR в базе вместе с ревьюверами, измененными файлами и метками
 func (r *PullRequestRepo) CreatePR(pr *domain.PullRequest) error {
 	assignedIDs := []string{}
 	for _, u := range pr.AssignReviewers {
@@ -41,53 +64,203 @@ func (r *PullRequestRepo) CreatePR(pr *domain.PullRequest) error {
 		return ErrPRExists
 	}
 
-	_, err := r.db.Exec(queries.InsertPR, pr.PRID, pr.PRName, pr.AuthorID)
+	tx, err := r.db.Begin()
 	if err != nil {
 		return err
 	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			r.logger.Errorf("rollback failed: %v", err)
+		}
+	}()
+
+	if _, err := tx.Exec(queries.InsertPR, pr.PRID, pr.PRName, pr.AuthorID, pr.TeamName, pr.IsDraft, pr.Repository); err != nil {
+		return err
+	}
+	if err := insertEvent(tx, &domain.PREvent{
+		Type: domain.EventPRCreated, PRID: pr.PRID, UserID: pr.AuthorID,
+		Details: map[string]any{"is_draft": pr.IsDraft},
+	}); err != nil {
+		return err
+	}
 
 	for _, id := range assignedIDs {
-		if _, err := r.db.Exec(queries.InsertPRReviewer, pr.PRID, id); err != nil {
+		if _, err := tx.Exec(queries.InsertPRReviewer, pr.PRID, id); err != nil {
+			return err
+		}
+		if err := insertEvent(tx, &domain.PREvent{Type: domain.EventReviewerAssigned, PRID: pr.PRID, UserID: id}); err != nil {
 			return err
 		}
 	}
 
-	return err
+	for _, path := range pr.ChangedFiles {
+		if _, err := tx.Exec(queries.InsertPRFile, pr.PRID, path); err != nil {
+			return err
+		}
+	}
+
+	for _, label := range pr.Labels {
+		if _, err := tx.Exec(queries.InsertPRLabel, pr.PRID, label); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
 }
 
 // AssignReviewers назначает ревьюверов
 func (r *PullRequestRepo) AssignReviewers(prID string, userIDs []string) error {
-	tx, err := r.db.Begin()
+	return RunInTx(r.db, r.logger, func(tx *sql.Tx) error {
+		return insertReviewers(tx, prID, userIDs)
+	})
+}
+
+// insertReviewers назначает ревьюверов в транзакции вызывающего
+func insertReviewers(exec db.Executor, prID string, userIDs []string) error {
+	for _, u := range userIDs {
+		if _, err := exec.Exec(queries.InsertPRReviewer, prID, u); err != nil {
+			return err
+		}
+		if err := insertEvent(exec, &domain.PREvent{Type: domain.EventReviewerAssigned, PRID: prID, UserID: u}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// execWithEvent выполняет изменение и, если оно затронуло строки, пишет событие в той же транзакции;
+// возвращает число затронутых строк
+func (r *PullRequestRepo) execWithEvent(event *domain.PREvent, query string, args ...any) (int64, error) {
+	var rows int64
+	err := RunInTx(r.db, r.logger, func(tx *sql.Tx) error {
+		res, err := tx.Exec(query, args...)
+		if err != nil {
+			return err
+		}
+		if rows, err = res.RowsAffected(); err != nil || rows == 0 {
+			return err
+		}
+		return insertEvent(tx, event)
+	})
+	return rows, err
+}
+
+// MergePR блокирует PR и передает check его статус и вердикты ревьюверов; если check не вернул
+// ошибку, в той же транзакции меняет статус на MERGED и фиксирует в истории ревьюверов на момент
+// слияния. Вердикты и смены статуса, пришедшие во время проверки, ждут конца транзакции
+func (r *PullRequestRepo) MergePR(prID string, mergedAt time.Time, check func(status string, reviews []*domain.Review) error) error {
+	return RunInTx(r.db, r.logger, func(tx *sql.Tx) error {
+		status, err := lockPRStatus(tx, prID)
+		if err != nil {
+			return err
+		}
+		reviews, err := r.listReviews(tx, prID)
+		if err != nil {
+			return err
+		}
+		if err := check(status, reviews); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(queries.UpdatePRStatusMerged, mergedAt, prID); err != nil {
+			return err
+		}
+		_, err = tx.Exec(queries.InsertPRMergedEvent, prID)
+		return err
+	})
+}
+
+// lockPRStatus блокирует PR в транзакции вызывающего и возвращает его статус
+func lockPRStatus(exec db.Executor, prID string) (string, error) {
+	var status string
+	err := exec.QueryRow(queries.LockPRStatus, prID).Scan(&status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrPRNotFound
+	}
+	return status, err
+}
+
+// lockOpenPR блокирует PR в транзакции вызывающего до ее конца; если PR не открыт,
+// возвращает ErrPRStateChanged
+func lockOpenPR(exec db.Executor, prID string) error {
+	status, err := lockPRStatus(exec, prID)
 	if err != nil {
 		return err
 	}
-	defer func() {
-		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
-			r.logger.Errorf("rollback failed: %v", err)
+	if status != domain.StatusOpen {
+		return ErrPRStateChanged
+	}
+	return nil
+}
+
+// ClosePR меняет статус открытого PR на CLOSED; если PR уже не открыт, возвращает ErrPRStateChanged
+func (r *PullRequestRepo) ClosePR(prID string, closedAt time.Time) error {
+	rows, err := r.execWithEvent(&domain.PREvent{Type: domain.EventPRClosed, PRID: prID}, queries.UpdatePRStatusClosed, closedAt, prID)
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrPRStateChanged
+	}
+	return nil
+}
+
+// ReopenPR возвращает закрытый PR в OPEN и в той же транзакции заново отсчитывает SLA ревьюверов
+// без вердикта, чтобы время в закрытом PR не делало их ревью просроченными; если PR уже
+// не закрыт, ничего не меняет и возвращает ErrPRStateChanged
+func (r *PullRequestRepo) ReopenPR(prID string) error {
+	return RunInTx(r.db, r.logger, func(tx *sql.Tx) error {
+		res, err := tx.Exec(queries.UpdatePRStatusReopened, prID)
+		if err != nil {
+			return err
+		}
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return ErrPRStateChanged
 		}
-	}()
 
-	for _, u := range userIDs {
-		if _, err := tx.Exec(queries.InsertPRReviewer, prID, u); err != nil {
+		if err := insertEvent(tx, &domain.PREvent{Type: domain.EventPRReopened, PRID: prID}); err != nil {
 			return err
 		}
-	}
-	return tx.Commit()
+		_, err = tx.Exec(queries.ResetPendingReviewsTimers, prID)
+		return err
+	})
 }
 
-// MergePR меняет статус на MERGED
-func (r *PullRequestRepo) MergePR(prID string, mergedAt time.Time) error {
-	_, err := r.db.Exec(queries.UpdatePRStatusMerged, mergedAt, prID)
-	return err
+// MarkPRReady снимает с открытого черновика признак черновика и назначает ревьюверов одной
+// транзакцией; если PR уже не открытый черновик, ничего не меняет и возвращает ErrPRStateChanged
+func (r *PullRequestRepo) MarkPRReady(prID string, reviewerIDs []string) error {
+	return RunInTx(r.db, r.logger, func(tx *sql.Tx) error {
+		res, err := tx.Exec(queries.UpdatePRReady, prID)
+		if err != nil {
+			return err
+		}
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return ErrPRStateChanged
+		}
+
+		if err := insertEvent(tx, &domain.PREvent{Type: domain.EventPRReady, PRID: prID}); err != nil {
+			return err
+		}
+		return insertReviewers(tx, prID, reviewerIDs)
+	})
 }
 
 // GetPRByID возвращает PR по ID
 func (r *PullRequestRepo) GetPRByID(prID string) (*domain.PullRequest, error) {
 	pr := &domain.PullRequest{}
-	var mergedAt sql.NullTime
+	var mergedAt, closedAt sql.NullTime
 
 	err := r.db.QueryRow(queries.SelectPRByID, prID).
-		Scan(&pr.PRID, &pr.PRName, &pr.AuthorID, &pr.Status, &pr.CreatedAt, &mergedAt)
+		Scan(&pr.PRID, &pr.PRName, &pr.AuthorID, &pr.TeamName, &pr.Status, &pr.IsDraft, &pr.Repository,
+			&pr.CreatedAt, &mergedAt, &closedAt, &pr.Orphaned)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrPRNotFound
@@ -98,6 +271,9 @@ func (r *PullRequestRepo) GetPRByID(prID string) (*domain.PullRequest, error) {
 	if mergedAt.Valid {
 		pr.MergedAt = &mergedAt.Time
 	}
+	if closedAt.Valid {
+		pr.ClosedAt = &closedAt.Time
+	}
 
 	// выбираем назначенных ревьюверов
 	rows, err := r.db.Query(queries.SelectPRReviewersFull, prID)
@@ -112,22 +288,290 @@ func (r *PullRequestRepo) GetPRByID(prID string) (*domain.PullRequest, error) {
 
 	for rows.Next() {
 		u := &domain.User{}
-		if err := rows.Scan(&u.UserID, &u.Username, &u.TeamName, &u.IsActive); err != nil {
+		if err := rows.Scan(&u.UserID, &u.Username, &u.TeamName, &u.IsActive, &u.Role); err != nil {
 			return nil, err
 		}
 		pr.AssignReviewers = append(pr.AssignReviewers, u)
 	}
 
+	files, err := r.listPRStrings(queries.SelectPRFiles, prID)
+	if err != nil {
+		return nil, err
+	}
+	pr.ChangedFiles = files
+
+	labels, err := r.listPRStrings(queries.SelectPRLabels, prID)
+	if err != nil {
+		return nil, err
+	}
+	pr.Labels = labels
+
 	return pr, nil
 }
 
-// UpdateReviewer заменяет одного ревьювера на другого
+// listPRStrings возвращает строковые атрибуты PR (файлы, метки) по запросу
+func (r *PullRequestRepo) listPRStrings(query, prID string) ([]string, error) {
+	rows, err := r.db.Query(query, prID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			r.logger.Errorf("rows close failed: %v", err)
+		}
+	}()
+
+	var values []string
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, rows.Err()
+}
+
+// SetLabels атомарно заменяет метки открытого PR; если PR уже не открыт, возвращает ErrPRStateChanged
+func (r *PullRequestRepo) SetLabels(prID string, labels []string) error {
+	return RunInTx(r.db, r.logger, func(tx *sql.Tx) error {
+		if err := lockOpenPR(tx, prID); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(queries.DeletePRLabels, prID); err != nil {
+			return err
+		}
+		for _, label := range labels {
+			if _, err := tx.Exec(queries.InsertPRLabel, prID, label); err != nil {
+				return err
+			}
+		}
+		return insertEvent(tx, &domain.PREvent{
+			Type: domain.EventLabelsChanged, PRID: prID, Details: map[string]any{"labels": labels},
+		})
+	})
+}
+
+// UpdateReviewer заменяет одного ревьювера на другого под блокировкой PR; если PR уже не открыт,
+// возвращает ErrPRStateChanged, если прежний ревьювер уже снят - ErrReviewerNotAssigned,
+// если новый уже назначен - ErrReviewerAlreadyAssigned
 func (r *PullRequestRepo) UpdateReviewer(prID, oldUserID, newUserID string) error {
-	_, err := r.db.Exec(queries.UpdatePRReviewer, newUserID, prID, oldUserID)
-	return err
+	return RunInTx(r.db, r.logger, func(tx *sql.Tx) error {
+		if err := lockOpenPR(tx, prID); err != nil {
+			return err
+		}
+
+		res, err := tx.Exec(queries.UpdatePRReviewer, newUserID, prID, oldUserID)
+		if isUniqueViolation(err) {
+			return ErrReviewerAlreadyAssigned
+		}
+		if err != nil {
+			return err
+		}
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return ErrReviewerNotAssigned
+		}
+		return insertEvent(tx, &domain.PREvent{Type: domain.EventReviewerReassigned, PRID: prID, UserID: newUserID, OldUserID: oldUserID})
+	})
 }
 
-// ListOpenPRsByTeam возвращает все PR со статусом OPEN для авторов команды
+// AddReviewer добавляет одного ревьювера к уже назначенным под блокировкой PR; число ревьюверов
+// пересчитывается под блокировкой и не должно достичь limit. Если PR уже не открыт, возвращает
+// ErrPRStateChanged, при достигнутом лимите - ErrReviewerLimitReached, если пользователь уже
+// назначен - ErrReviewerAlreadyAssigned
+func (r *PullRequestRepo) AddReviewer(prID, userID string, limit int) error {
+	return RunInTx(r.db, r.logger, func(tx *sql.Tx) error {
+		if err := lockOpenPR(tx, prID); err != nil {
+			return err
+		}
+
+		var count int
+		if err := tx.QueryRow(queries.CountPRReviewers, prID).Scan(&count); err != nil {
+			return err
+		}
+		if count >= limit {
+			return ErrReviewerLimitReached
+		}
+
+		if _, err := tx.Exec(queries.InsertPRReviewer, prID, userID); err != nil {
+			if isUniqueViolation(err) {
+				return ErrReviewerAlreadyAssigned
+			}
+			return err
+		}
+		return insertEvent(tx, &domain.PREvent{Type: domain.EventReviewerAssigned, PRID: prID, UserID: userID})
+	})
+}
+
+// RemoveReviewer снимает ревьювера с PR вместе с его вердиктом под блокировкой PR; если PR уже
+// не открыт, возвращает ErrPRStateChanged, если ревьювер не назначен - ErrReviewerNotAssigned
+func (r *PullRequestRepo) RemoveReviewer(prID, userID string) error {
+	return RunInTx(r.db, r.logger, func(tx *sql.Tx) error {
+		if err := lockOpenPR(tx, prID); err != nil {
+			return err
+		}
+
+		res, err := tx.Exec(queries.DeletePRReviewer, prID, userID)
+		if err != nil {
+			return err
+		}
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return ErrReviewerNotAssigned
+		}
+		return insertEvent(tx, &domain.PREvent{Type: domain.EventReviewerRemoved, PRID: prID, UserID: userID})
+	})
+}
+
+// HandOffReviewer заменяет ревьювера в транзакции вызывающего
+func (r *PullRequestRepo) HandOffReviewer(exec db.Executor, prID, oldUserID, newUserID string) error {
+	if _, err := exec.Exec(queries.UpdatePRReviewer, newUserID, prID, oldUserID); err != nil {
+		return err
+	}
+	return insertEvent(exec, &domain.PREvent{Type: domain.EventReviewerReassigned, PRID: prID, UserID: newUserID, OldUserID: oldUserID})
+}
+
+// LockReviewForHandoff блокирует открытый PR в транзакции вызывающего и сообщает, назначен ли
+// на него userID; для закрытого, слитого или удаленного PR возвращает false
+func (r *PullRequestRepo) LockReviewForHandoff(exec db.Executor, prID, userID string) (bool, error) {
+	var assigned bool
+	err := exec.QueryRow(queries.LockReviewForHandoff, prID, userID).Scan(&assigned)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return assigned, err
+}
+
+// LockHandoffTarget блокирует получателя ревью PR prID в транзакции вызывающего и возвращает,
+// может ли он принять ревью, и число его открытых ревью с учетом уже сделанных в транзакции передач
+func (r *PullRequestRepo) LockHandoffTarget(exec db.Executor, prID, userID string) (bool, int, error) {
+	var available bool
+	var openReviews int
+	err := exec.QueryRow(queries.LockHandoffTarget, userID, prID).Scan(&available, &openReviews)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, 0, nil
+	}
+	return available, openReviews, err
+}
+
+// CountOpenReviewsOf возвращает число открытых PR на ревью у пользователя в транзакции вызывающего
+func (r *PullRequestRepo) CountOpenReviewsOf(exec db.Executor, userID string) (int, error) {
+	var count int
+	err := exec.QueryRow(queries.SelectOpenReviewCountByUser, userID).Scan(&count)
+	return count, err
+}
+
+// UnassignReviewer снимает ревьювера в транзакции вызывающего
+func (r *PullRequestRepo) UnassignReviewer(exec db.Executor, prID, userID string) error {
+	if _, err := exec.Exec(queries.DeletePRReviewer, prID, userID); err != nil {
+		return err
+	}
+	return insertEvent(exec, &domain.PREvent{Type: domain.EventReviewerRemoved, PRID: prID, UserID: userID})
+}
+
+// TransferAuthorship передает незавершенные PR автора новому владельцу и возвращает их число;
+// новый автор снимается с ревью этих PR
+func (r *PullRequestRepo) TransferAuthorship(exec db.Executor, fromUserID, toUserID string) (int64, error) {
+	if _, err := exec.Exec(queries.InsertNewAuthorRemovedEvents, fromUserID, toUserID); err != nil {
+		return 0, err
+	}
+	if _, err := exec.Exec(queries.DeleteNewAuthorReviews, fromUserID, toUserID); err != nil {
+		return 0, err
+	}
+	if _, err := exec.Exec(queries.InsertAuthorChangedEvents, fromUserID, toUserID); err != nil {
+		return 0, err
+	}
+
+	res, err := exec.Exec(queries.UpdatePRAuthor, fromUserID, toUserID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// MarkOrphaned помечает незавершенные PR автора как оставшиеся без владельца и возвращает их число
+func (r *PullRequestRepo) MarkOrphaned(exec db.Executor, authorID string) (int64, error) {
+	if _, err := exec.Exec(queries.InsertPROrphanedEvents, authorID); err != nil {
+		return 0, err
+	}
+
+	res, err := exec.Exec(queries.UpdatePROrphaned, authorID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// SubmitReview сохраняет вердикт назначенного ревьювера открытого PR; PR блокируется, чтобы вердикт
+// не разминулся с проверкой кворума в MergePR. Если PR уже не открыт, возвращает ErrPRStateChanged
+func (r *PullRequestRepo) SubmitReview(prID, userID, verdict string, reviewedAt time.Time) error {
+	return RunInTx(r.db, r.logger, func(tx *sql.Tx) error {
+		status, err := lockPRStatus(tx, prID)
+		if err != nil {
+			return err
+		}
+		if status != domain.StatusOpen {
+			return ErrPRStateChanged
+		}
+
+		res, err := tx.Exec(queries.UpdatePRReviewVerdict, verdict, reviewedAt, prID, userID)
+		if err != nil {
+			return err
+		}
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return ErrReviewerNotAssigned
+		}
+		return insertEvent(tx, &domain.PREvent{
+			Type: domain.EventReviewSubmitted, PRID: prID, UserID: userID, Details: map[string]any{"verdict": verdict},
+		})
+	})
+}
+
+// ListReviews возвращает вердикты всех назначенных ревьюверов PR
+func (r *PullRequestRepo) ListReviews(prID string) ([]*domain.Review, error) {
+	return r.listReviews(r.db, prID)
+}
+
+// listReviews читает вердикты ревьюверов PR через exec, в том числе в транзакции вызывающего
+func (r *PullRequestRepo) listReviews(exec db.Executor, prID string) ([]*domain.Review, error) {
+	rows, err := exec.Query(queries.SelectPRReviews, prID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			r.logger.Errorf("rows close failed: %v", err)
+		}
+	}()
+
+	reviews := []*domain.Review{}
+	for rows.Next() {
+		review := &domain.Review{}
+		var reviewedAt sql.NullTime
+		if err := rows.Scan(&review.PRID, &review.ReviewerID, &review.Verdict, &reviewedAt); err != nil {
+			return nil, err
+		}
+		if reviewedAt.Valid {
+			review.ReviewedAt = &reviewedAt.Time
+		}
+		reviews = append(reviews, review)
+	}
+	return reviews, rows.Err()
+}
+
+// ListOpenPRsByTeam возвращает все PR команды со статусом OPEN
 func (r *PullRequestRepo) ListOpenPRsByTeam(teamName string) ([]*domain.PullRequest, error) {
 	rows, err := r.db.Query(queries.GetOpenPRsByTeamName, teamName)
 	if err != nil {
@@ -145,7 +589,7 @@ func (r *PullRequestRepo) ListOpenPRsByTeam(teamName string) ([]*domain.PullRequ
 		var reviewer domain.User
 		var reviewerID sql.NullString
 
-		if err := rows.Scan(&prID, &prName, &authorID, &status, &reviewerID, &reviewer.Username, &reviewer.TeamName, &reviewer.IsActive); err != nil {
+		if err := rows.Scan(&prID, &prName, &authorID, &status, &reviewerID, &reviewer.Username, &reviewer.TeamName, &reviewer.IsActive, &reviewer.Role); err != nil {
 			return nil, err
 		}
 
@@ -155,6 +599,7 @@ func (r *PullRequestRepo) ListOpenPRsByTeam(teamName string) ([]*domain.PullRequ
 				PRID:            prID,
 				PRName:          prName,
 				AuthorID:        authorID,
+				TeamName:        teamName,
 				Status:          status,
 				AssignReviewers: []*domain.User{},
 			}
@@ -167,6 +612,10 @@ func (r *PullRequestRepo) ListOpenPRsByTeam(teamName string) ([]*domain.PullRequ
 		}
 	}
 
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
 	prs := make([]*domain.PullRequest, 0, len(prMap))
 	for _, pr := range prMap {
 		prs = append(prs, pr)
@@ -174,6 +623,30 @@ func (r *PullRequestRepo) ListOpenPRsByTeam(teamName string) ([]*domain.PullRequ
 	return prs, nil
 }
 
+// CountOpenReviews возвращает число открытых PR на ревью у каждого из пользователей
+func (r *PullRequestRepo) CountOpenReviews(userIDs []string) (map[string]int, error) {
+	rows, err := r.db.Query(queries.SelectOpenReviewCountsByUsers, pq.Array(userIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			r.logger.Errorf("rows close failed: %v", err)
+		}
+	}()
+
+	counts := make(map[string]int, len(userIDs))
+	for rows.Next() {
+		var userID string
+		var count int
+		if err := rows.Scan(&userID, &count); err != nil {
+			return nil, err
+		}
+		counts[userID] = count
+	}
+	return counts, rows.Err()
+}
+
 // ListAllPRs вывод всех пулл реквестов
 func (r *PullRequestRepo) ListAllPRs() ([]*domain.PullRequest, error) {
 	rows, err := r.db.Query(queries.SelectAllRPs)
@@ -200,3 +673,159 @@ func (r *PullRequestRepo) ListAllPRs() ([]*domain.PullRequest, error) {
 	}
 	return prs, nil
 }
+
+// ListPRs возвращает страницу PR по фильтру вместе с ревьюверами одним запросом
+func (r *PullRequestRepo) ListPRs(filter *domain.PRFilter) ([]*domain.PullRequest, error) {
+	var afterTime *time.Time
+	var afterID string
+	if filter.After != nil {
+		afterTime, afterID = &filter.After.CreatedAt, filter.After.PRID
+	}
+
+	rows, err := r.db.Query(queries.SelectPRsFiltered,
+		filter.Status, filter.AuthorID, filter.TeamName, filter.ReviewerID,
+		filter.CreatedFrom, filter.CreatedTo, filter.MergedFrom, filter.MergedTo,
+		afterTime, afterID, filter.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			r.logger.Errorf("rows close failed: %v", err)
+		}
+	}()
+
+	prs := []*domain.PullRequest{}
+	for rows.Next() {
+		pr := &domain.PullRequest{}
+		var mergedAt, closedAt sql.NullTime
+		var reviewers []byte
+		if err := rows.Scan(
+			&pr.PRID, &pr.PRName, &pr.AuthorID, &pr.TeamName, &pr.Status, &pr.IsDraft, &pr.Repository,
+			&pr.CreatedAt, &mergedAt, &closedAt, &pr.Orphaned, &reviewers,
+		); err != nil {
+			return nil, err
+		}
+		if mergedAt.Valid {
+			pr.MergedAt = &mergedAt.Time
+		}
+		if closedAt.Valid {
+			pr.ClosedAt = &closedAt.Time
+		}
+		if err := json.Unmarshal(reviewers, &pr.AssignReviewers); err != nil {
+			return nil, err
+		}
+		prs = append(prs, pr)
+	}
+	return prs, rows.Err()
+}
+
+// ListBreachedReviews возвращает назначения, нарушившие SLA, которые еще не отмечены просроченными
+func (r *PullRequestRepo) ListBreachedReviews() ([]*domain.OverdueReview, error) {
+	rows, err := r.db.Query(queries.SelectBreachedReviews)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			r.logger.Errorf("rows close failed: %v", err)
+		}
+	}()
+
+	reviews := []*domain.OverdueReview{}
+	for rows.Next() {
+		review := &domain.OverdueReview{}
+		if err := rows.Scan(&review.PRID, &review.PRName, &review.AuthorID, &review.ReviewerID,
+			&review.AssignedAt, &review.DueAt, &review.AutoReassign); err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, review)
+	}
+	return reviews, rows.Err()
+}
+
+// MarkReviewOverdue отмечает назначение просроченным
+func (r *PullRequestRepo) MarkReviewOverdue(prID, userID string, overdueAt time.Time) error {
+	_, err := r.execWithEvent(
+		&domain.PREvent{Type: domain.EventReviewOverdue, PRID: prID, UserID: userID},
+		queries.UpdateReviewOverdue, overdueAt, prID, userID,
+	)
+	return err
+}
+
+// ListOverdueReviews возвращает просроченные ревью открытых PR, по которым еще нет вердикта
+func (r *PullRequestRepo) ListOverdueReviews() ([]*domain.OverdueReview, error) {
+	rows, err := r.db.Query(queries.SelectOverdueReviews)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			r.logger.Errorf("rows close failed: %v", err)
+		}
+	}()
+
+	reviews := []*domain.OverdueReview{}
+	for rows.Next() {
+		review := &domain.OverdueReview{}
+		var overdueAt sql.NullTime
+		if err := rows.Scan(&review.PRID, &review.PRName, &review.AuthorID, &review.ReviewerID,
+			&review.AssignedAt, &review.DueAt, &overdueAt); err != nil {
+			return nil, err
+		}
+		if overdueAt.Valid {
+			review.OverdueAt = &overdueAt.Time
+		}
+		reviews = append(reviews, review)
+	}
+	return reviews, rows.Err()
+}
+
+// ListReviewPairsByAuthor возвращает пары автора с указанными ревьюверами за окно
+func (r *PullRequestRepo) ListReviewPairsByAuthor(authorID string, reviewerIDs []string, window time.Duration) ([]*domain.ReviewPair, error) {
+	rows, err := r.db.Query(queries.SelectReviewPairsByAuthor, authorID, pq.Array(reviewerIDs), window.Seconds())
+	if err != nil {
+		r.logger.Errorf("failed to list review pairs of author %s: %v", authorID, err)
+		return nil, err
+	}
+	return r.scanReviewPairs(rows)
+}
+
+// ListReviewPairsByTeam возвращает все пары автор-ревьювер по PR команды за окно
+func (r *PullRequestRepo) ListReviewPairsByTeam(teamName string, window time.Duration) ([]*domain.ReviewPair, error) {
+	rows, err := r.db.Query(queries.SelectReviewPairsByTeam, teamName, window.Seconds())
+	if err != nil {
+		r.logger.Errorf("failed to list review pairs of team %s: %v", teamName, err)
+		return nil, err
+	}
+	return r.scanReviewPairs(rows)
+}
+
+// scanReviewPairs читает пары автор-ревьювер из sql.Rows
+func (r *PullRequestRepo) scanReviewPairs(rows *sql.Rows) ([]*domain.ReviewPair, error) {
+	defer func() {
+		if err := rows.Close(); err != nil {
+			r.logger.Errorf("rows close failed: %v", err)
+		}
+	}()
+
+	pairs := []*domain.ReviewPair{}
+	for rows.Next() {
+		pair := &domain.ReviewPair{}
+		if err := rows.Scan(&pair.AuthorID, &pair.ReviewerID, &pair.Reviews, &pair.LastAssignedAt, &pair.Penalty); err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, pair)
+	}
+	return pairs, rows.Err()
+}
+
+// ListEvents возвращает историю PR в порядке событий
+func (r *PullRequestRepo) ListEvents(prID string) ([]*domain.PREvent, error) {
+	rows, err := r.db.Query(queries.SelectPREventsByPR, prID)
+	if err != nil {
+		return nil, err
+	}
+	return scanEvents(rows, r.logger)
+}
@@ -2,35 +2,109 @@ package queries
 
 // UserRepo
 const (
+	// основная команда меняется, только если ее еще нет
 	InsertOrUpdateUser = `
 		INSERT INTO users(user_id, username, team_name, is_active)
 		VALUES($1, $2, $3, $4)
 		ON CONFLICT(user_id) DO UPDATE
 		SET username = EXCLUDED.username,
 		is_active = EXCLUDED.is_active,
-		team_name = EXCLUDED.team_name`
+		team_name = CASE WHEN users.deleted_at IS NULL AND COALESCE(users.team_name, '') <> ''
+		                 THEN users.team_name ELSE EXCLUDED.team_name END,
+		deleted_at = NULL`
+
+	// удаленного пользователя можно создать заново, существующего - нет
+	InsertUser = `
+		INSERT INTO users(user_id, username, team_name, is_active)
+		VALUES($1, $2, $3, $4)
+		ON CONFLICT(user_id) DO UPDATE
+		SET username = EXCLUDED.username,
+		is_active = EXCLUDED.is_active,
+		team_name = EXCLUDED.team_name,
+		max_open_reviews = NULL,
+		deleted_at = NULL
+		WHERE users.deleted_at IS NOT NULL`
+
+	UpdateUserProfile = `
+		UPDATE users SET username=$1, team_name=$2
+		WHERE user_id=$3 AND deleted_at IS NULL`
+
+	// удаление мягкое: пользователь выводится из команды и деактивируется
+	SoftDeleteUser = `
+		UPDATE users SET deleted_at=NOW(), is_active=false, team_name=''
+		WHERE user_id=$1 AND deleted_at IS NULL`
 
 	SelectUserByID = `
-		SELECT user_id, username, team_name, is_active FROM users
+		SELECT user_id, username, team_name, is_active, deleted_at IS NOT NULL FROM users
 		WHERE user_id=$1`
 
+	SelectUserIsActiveForUpdate = `
+		SELECT is_active FROM users
+		WHERE user_id=$1
+		FOR UPDATE`
+
 	UpdateUserIsActive = `
 		UPDATE users SET is_active=$1
 		WHERE user_id=$2`
 
+	// участники команды, team_name и role в выборке - по запрошенной команде, а не основной
 	SelectUsersByTeam = `
-		SELECT user_id, username, team_name, is_active FROM users
-		WHERE team_name=$1`
+		SELECT u.user_id, u.username, tm.team_name, u.is_active, tm.role
+		FROM team_members tm
+		JOIN users u ON u.user_id = tm.user_id
+		WHERE tm.team_name=$1`
 
+	// активные участники команды без действующего отсутствия
 	SelectActiveUsersByTeam = `
-		SELECT user_id, username, team_name, is_active FROM users
-		WHERE team_name=$1 AND is_active=true`
+		SELECT u.user_id, u.username, tm.team_name, u.is_active, tm.role
+		FROM team_members tm
+		JOIN users u ON u.user_id = tm.user_id
+		WHERE tm.team_name=$1 AND u.is_active=true
+		  AND NOT EXISTS (
+		      SELECT 1 FROM user_absences a
+		      WHERE a.user_id = u.user_id AND a.starts_at <= NOW() AND a.ends_at > NOW())`
+
+	SelectUserTeams = `
+		SELECT team_name FROM team_members
+		WHERE user_id=$1
+		ORDER BY team_name`
+
+	// роль вне контекста команды не определена
+	SelectAvailableUsersByIDs = `
+		SELECT user_id, username, team_name, is_active, '' FROM users
+		WHERE user_id = ANY($1) AND is_active=true
+		  AND NOT EXISTS (
+		      SELECT 1 FROM user_absences a
+		      WHERE a.user_id = users.user_id AND a.starts_at <= NOW() AND a.ends_at > NOW())
+		ORDER BY user_id`
 
 	SelectReviewPRsByUser = `
 		SELECT pr.pull_request_id AS pr_id, pr.pull_request_name, pr.author_id, pr.status
 		FROM pull_request_reviewers prr
 		JOIN pull_requests pr ON prr.pull_request_id = pr.pull_request_id
-		WHERE prr.user_id = $1;`
+		WHERE prr.user_id = $1 AND pr.status <> 'CLOSED';`
+
+	DeleteUserTags = `
+		DELETE FROM user_tags
+		WHERE user_id=$1`
+
+	InsertUserTag = `
+		INSERT INTO user_tags(user_id, tag)
+		VALUES($1, $2)
+		ON CONFLICT DO NOTHING`
+
+	UpdateUserMaxOpenReviews = `
+		UPDATE users SET max_open_reviews=$1
+		WHERE user_id=$2`
+
+	SelectReviewLimitsByUsers = `
+		SELECT user_id, max_open_reviews FROM users
+		WHERE user_id = ANY($1) AND max_open_reviews IS NOT NULL`
+
+	SelectTagsByUsers = `
+		SELECT user_id, tag FROM user_tags
+		WHERE user_id = ANY($1)
+		ORDER BY user_id, tag`
 )
 
 // TeamRepo
@@ -43,17 +117,143 @@ const (
 		WHERE team_name=$1)`
 
 	SelectTeamUsers = `
-		SELECT user_id, username, is_active FROM users
-		WHERE team_name=$1`
+		SELECT u.user_id, u.username, u.is_active
+		FROM team_members tm
+		JOIN users u ON u.user_id = tm.user_id
+		WHERE tm.team_name=$1`
 
 	SetIsActiveStatusByTeamName = `
         UPDATE users
         SET is_active = $1
-        WHERE team_name = $2
+        WHERE user_id IN (SELECT user_id FROM team_members WHERE team_name = $2)
     `
 	SelectAllTeams = `
 		SELECT team_name
 		FROM teams`
+
+	SelectTeamSettings = `
+		SELECT team_name, reviewers_count, min_reviewers, strategy, allow_cross_team, approvals_required, max_open_reviews,
+		       review_sla_hours, auto_reassign_overdue, max_reviewers, require_lead, rotation_window_days
+		FROM team_settings
+		WHERE team_name=$1`
+
+	UpsertTeamSettings = `
+		INSERT INTO team_settings(team_name, reviewers_count, min_reviewers, strategy, allow_cross_team, approvals_required, max_open_reviews,
+		                          review_sla_hours, auto_reassign_overdue, max_reviewers, require_lead, rotation_window_days)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT(team_name) DO UPDATE
+		SET reviewers_count = EXCLUDED.reviewers_count,
+		min_reviewers = EXCLUDED.min_reviewers,
+		strategy = EXCLUDED.strategy,
+		allow_cross_team = EXCLUDED.allow_cross_team,
+		approvals_required = EXCLUDED.approvals_required,
+		max_open_reviews = EXCLUDED.max_open_reviews,
+		review_sla_hours = EXCLUDED.review_sla_hours,
+		auto_reassign_overdue = EXCLUDED.auto_reassign_overdue,
+		max_reviewers = EXCLUDED.max_reviewers,
+		require_lead = EXCLUDED.require_lead,
+		rotation_window_days = EXCLUDED.rotation_window_days`
+
+	SelectTeamFallbacks = `
+		SELECT fallback_team FROM team_fallbacks
+		WHERE team_name=$1
+		ORDER BY position`
+
+	DeleteTeamFallbacks = `
+		DELETE FROM team_fallbacks
+		WHERE team_name=$1`
+
+	InsertTeamFallback = `
+		INSERT INTO team_fallbacks(team_name, fallback_team, position)
+		VALUES($1, $2, $3)`
+
+	// основная команда: пользователь без команд хранится с пустым team_name
+	SelectUserTeamForUpdate = `
+		SELECT COALESCE(team_name, ''), COALESCE(is_active, false) FROM users
+		WHERE user_id=$1 AND deleted_at IS NULL
+		FOR UPDATE`
+
+	UpdateUserTeam = `
+		UPDATE users SET team_name=$1
+		WHERE user_id=$2`
+
+	UpdateUserTeamAndProfile = `
+		UPDATE users SET team_name=$1, username=$2, is_active=$3
+		WHERE user_id=$4`
+
+	DetachUserFromTeam = `
+		UPDATE users SET team_name='', is_active=false
+		WHERE user_id=$1`
+
+	// пустая роль означает member
+	InsertTeamMember = `
+		INSERT INTO team_members(team_name, user_id, role)
+		VALUES($1, $2, COALESCE(NULLIF($3, ''), 'member'))
+		ON CONFLICT DO NOTHING`
+
+	SelectTeamMemberRoleForUpdate = `
+		SELECT role FROM team_members
+		WHERE team_name=$1 AND user_id=$2
+		FOR UPDATE`
+
+	UpdateTeamMemberRole = `
+		UPDATE team_members SET role=$1
+		WHERE team_name=$2 AND user_id=$3`
+
+	DeleteTeamMember = `
+		DELETE FROM team_members
+		WHERE team_name=$1 AND user_id=$2`
+
+	DeleteUserMemberships = `
+		DELETE FROM team_members
+		WHERE user_id=$1`
+
+	SelectTeamMemberExists = `
+		SELECT EXISTS(SELECT 1 FROM team_members
+		WHERE team_name=$1 AND user_id=$2)`
+
+	// первая по имени из оставшихся команд становится основной
+	SelectNextPrimaryTeam = `
+		SELECT COALESCE(MIN(team_name), '') FROM team_members
+		WHERE user_id=$1`
+
+	SelectTeamMemberIDs = `
+		SELECT user_id FROM team_members
+		WHERE team_name=$1
+		ORDER BY user_id`
+
+	RenameTeam = `
+		UPDATE teams SET team_name=$1
+		WHERE team_name=$2`
+
+	MoveTeamUsers = `
+		UPDATE users SET team_name=$1
+		WHERE team_name=$2`
+
+	MoveTeamPRs = `
+		UPDATE pull_requests SET team_name=$1
+		WHERE team_name=$2`
+
+	DeleteTeam = `
+		DELETE FROM teams
+		WHERE team_name=$1`
+
+	// импорт команд: создание недостающей команды и профиль пользователя под блокировкой
+	InsertTeamIfMissing = `
+		INSERT INTO teams(team_name) VALUES($1)
+		ON CONFLICT DO NOTHING`
+
+	SelectUserProfileForUpdate = `
+		SELECT COALESCE(team_name, ''), username, COALESCE(is_active, false) FROM users
+		WHERE user_id=$1 AND deleted_at IS NULL
+		FOR UPDATE`
+
+	// экспорт команд: участники по основной команде, команды без участников тоже попадают
+	SelectTeamsForExport = `
+		SELECT t.team_name, COALESCE(u.user_id, ''), COALESCE(u.username, ''), COALESCE(u.is_active, false)
+		FROM teams t
+		LEFT JOIN users u ON u.team_name = t.team_name AND u.deleted_at IS NULL
+		ORDER BY t.team_name, u.user_id`
 )
 
 // PullRequestRepo
@@ -61,54 +261,491 @@ const (
 	SelectAllRPs = `
 		SELECT pull_request_id FROM pull_requests`
 
+	// ревьюверы собираются в json одним запросом вместе с PR
+	SelectPRsFiltered = `
+		SELECT pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.team_name, pr.status, pr.is_draft, pr.repository,
+		       pr.created_at, pr.merged_at, pr.closed_at, pr.orphaned, COALESCE(rv.reviewers, '[]')
+		FROM pull_requests pr
+		LEFT JOIN LATERAL (
+			SELECT json_agg(json_build_object(
+				'user_id', u.user_id, 'username', u.username, 'team_name', u.team_name, 'is_active', u.is_active
+			) ORDER BY u.user_id) AS reviewers
+			FROM pull_request_reviewers prr
+			JOIN users u ON u.user_id = prr.user_id
+			WHERE prr.pull_request_id = pr.pull_request_id
+		) rv ON TRUE
+		WHERE ($1::text = '' OR pr.status = $1)
+		  AND ($2::text = '' OR pr.author_id = $2)
+		  AND ($3::text = '' OR pr.team_name = $3)
+		  AND ($4::text = '' OR EXISTS (
+			SELECT 1 FROM pull_request_reviewers f
+			WHERE f.pull_request_id = pr.pull_request_id AND f.user_id = $4))
+		  AND ($5::timestamp IS NULL OR pr.created_at >= $5)
+		  AND ($6::timestamp IS NULL OR pr.created_at < $6)
+		  AND ($7::timestamp IS NULL OR pr.merged_at >= $7)
+		  AND ($8::timestamp IS NULL OR pr.merged_at < $8)
+		  AND ($9::timestamp IS NULL OR (pr.created_at, pr.pull_request_id) < ($9, $10::text))
+		ORDER BY pr.created_at DESC, pr.pull_request_id DESC
+		LIMIT $11`
+
 	SelectPRExist = `
 		SELECT EXISTS(SELECT 1 FROM pull_requests
 		WHERE pull_request_id=$1)`
 
 	InsertPR = `
-		INSERT INTO pull_requests(pull_request_id, pull_request_name, author_id, status, created_at)
-		VALUES($1,$2,$3,'OPEN',NOW())`
+		INSERT INTO pull_requests(pull_request_id, pull_request_name, author_id, team_name, status, is_draft, repository, created_at)
+		VALUES($1,$2,$3,$4,'OPEN',$5,$6,NOW())`
+
+	InsertPRFile = `
+		INSERT INTO pull_request_files(pull_request_id, path)
+		VALUES($1, $2)
+		ON CONFLICT DO NOTHING`
+
+	SelectPRFiles = `
+		SELECT path FROM pull_request_files
+		WHERE pull_request_id=$1
+		ORDER BY path`
+
+	InsertPRLabel = `
+		INSERT INTO pull_request_labels(pull_request_id, label)
+		VALUES($1, $2)
+		ON CONFLICT DO NOTHING`
+
+	DeletePRLabels = `
+		DELETE FROM pull_request_labels
+		WHERE pull_request_id=$1`
+
+	SelectPRLabels = `
+		SELECT label FROM pull_request_labels
+		WHERE pull_request_id=$1
+		ORDER BY label`
+
+	UpdatePRReady = `
+		UPDATE pull_requests SET is_draft=false
+		WHERE pull_request_id=$1 AND is_draft AND status='OPEN'`
 
 	UpdatePRStatusMerged = `
 		UPDATE pull_requests SET status='MERGED', merged_at=$1
-		WHERE pull_request_id=$2`
+		WHERE pull_request_id=$2 AND status<>'MERGED'`
+
+	// блокирует PR, чтобы слияние, вердикты и смена статуса применялись по очереди
+	LockPRStatus = `
+		SELECT status
+		FROM pull_requests
+		WHERE pull_request_id=$1
+		FOR UPDATE`
+
+	UpdatePRStatusClosed = `
+		UPDATE pull_requests SET status='CLOSED', closed_at=$1
+		WHERE pull_request_id=$2 AND status='OPEN'`
+
+	UpdatePRStatusReopened = `
+		UPDATE pull_requests SET status='OPEN', closed_at=NULL
+		WHERE pull_request_id=$1 AND status='CLOSED'`
+
+	ResetPendingReviewsTimers = `
+		UPDATE pull_request_reviewers SET assigned_at=NOW(), overdue_at=NULL
+		WHERE pull_request_id=$1 AND verdict IS NULL`
 
 	SelectPRByID = `
-		SELECT pull_request_id, pull_request_name, author_id, status, created_at, merged_at
+		SELECT pull_request_id, pull_request_name, author_id, team_name, status, is_draft, repository, created_at, merged_at, closed_at, orphaned
 		FROM pull_requests
 		WHERE pull_request_id=$1`
 
+	// роль ревьювера - в команде PR, для ревьюверов из других команд пустая
 	SelectPRReviewersFull = `
-		SELECT u.user_id, u.username, u.team_name, u.is_active
+		SELECT u.user_id, u.username, u.team_name, u.is_active, COALESCE(tm.role, '')
 		FROM pull_request_reviewers prr
+		JOIN pull_requests pr ON pr.pull_request_id = prr.pull_request_id
 		JOIN users u ON prr.user_id = u.user_id
+		LEFT JOIN team_members tm ON tm.user_id = u.user_id AND tm.team_name = pr.team_name
 		WHERE prr.pull_request_id = $1`
 
 	SelectPRReviewers = `
 		SELECT COUNT(1) FROM pull_request_reviewers
 		WHERE pull_request_id=$1 AND user_id=$2`
 
+	CountPRReviewers = `
+		SELECT COUNT(*) FROM pull_request_reviewers
+		WHERE pull_request_id=$1`
+
 	InsertPRReviewer = `
 		INSERT INTO pull_request_reviewers(pull_request_id, user_id)
 		VALUES($1, $2)`
 
+	DeletePRReviewer = `
+		DELETE FROM pull_request_reviewers
+		WHERE pull_request_id=$1 AND user_id=$2`
+
+	// передача авторства незавершенных PR: события пишутся до изменения, новый автор снимается с ревью своих PR
+	InsertAuthorChangedEvents = `
+		INSERT INTO pr_events(event_type, pull_request_id, user_id, old_user_id)
+		SELECT 'AUTHOR_CHANGED', pull_request_id, $2::text, $1::text FROM pull_requests
+		WHERE author_id = $1::text AND status <> 'MERGED'`
+
+	InsertNewAuthorRemovedEvents = `
+		INSERT INTO pr_events(event_type, pull_request_id, user_id)
+		SELECT 'REVIEWER_REMOVED', pr.pull_request_id, prr.user_id
+		FROM pull_requests pr
+		JOIN pull_request_reviewers prr ON prr.pull_request_id = pr.pull_request_id
+		WHERE pr.author_id = $1 AND pr.status <> 'MERGED' AND prr.user_id = $2`
+
+	DeleteNewAuthorReviews = `
+		DELETE FROM pull_request_reviewers prr
+		USING pull_requests pr
+		WHERE pr.pull_request_id = prr.pull_request_id
+		  AND pr.author_id = $1 AND pr.status <> 'MERGED' AND prr.user_id = $2`
+
+	UpdatePRAuthor = `
+		UPDATE pull_requests SET author_id=$2, orphaned=false
+		WHERE author_id=$1 AND status <> 'MERGED'`
+
+	InsertPROrphanedEvents = `
+		INSERT INTO pr_events(event_type, pull_request_id, old_user_id)
+		SELECT 'PR_ORPHANED', pull_request_id, author_id FROM pull_requests
+		WHERE author_id = $1 AND status <> 'MERGED' AND NOT orphaned`
+
+	UpdatePROrphaned = `
+		UPDATE pull_requests SET orphaned=true
+		WHERE author_id=$1 AND status <> 'MERGED'`
+
+	// передача ревью удаляемого пользователя: блокируем открытый PR и проверяем, что ревью еще за ним
+	LockReviewForHandoff = `
+		SELECT EXISTS (
+		    SELECT 1 FROM pull_request_reviewers
+		    WHERE pull_request_id=$1 AND user_id=$2)
+		FROM pull_requests
+		WHERE pull_request_id=$1 AND status='OPEN'
+		FOR UPDATE`
+
+	// получатель ревью: блокируем его строку, чтобы параллельные передачи считали загрузку по очереди;
+	// доступен, если активен, не удален, не в отсутствии и еще не ревьюит этот PR
+	LockHandoffTarget = `
+		SELECT is_active AND deleted_at IS NULL
+		       AND NOT EXISTS (
+		           SELECT 1 FROM user_absences a
+		           WHERE a.user_id = users.user_id AND a.starts_at <= NOW() AND a.ends_at > NOW())
+		       AND NOT EXISTS (
+		           SELECT 1 FROM pull_request_reviewers
+		           WHERE pull_request_id=$2 AND user_id = users.user_id),
+		       (SELECT COUNT(*)
+		        FROM pull_request_reviewers prr
+		        JOIN pull_requests pr ON pr.pull_request_id = prr.pull_request_id
+		        WHERE pr.status = 'OPEN' AND prr.user_id = users.user_id)
+		FROM users
+		WHERE user_id=$1
+		FOR UPDATE`
+
+	SelectOpenReviewCountByUser = `
+		SELECT COUNT(*)
+		FROM pull_request_reviewers prr
+		JOIN pull_requests pr ON pr.pull_request_id = prr.pull_request_id
+		WHERE pr.status = 'OPEN' AND prr.user_id = $1`
+
 	UpdatePRReviewer = `
-		UPDATE pull_request_reviewers SET user_id=$1
+		UPDATE pull_request_reviewers SET user_id=$1, verdict=NULL, reviewed_at=NULL, assigned_at=NOW(), overdue_at=NULL
 		WHERE pull_request_id=$2 AND user_id=$3`
 
+	UpdatePRReviewVerdict = `
+		UPDATE pull_request_reviewers SET verdict=$1, reviewed_at=$2
+		WHERE pull_request_id=$3 AND user_id=$4`
+
+	SelectPRReviews = `
+		SELECT pull_request_id, user_id, COALESCE(verdict, ''), reviewed_at
+		FROM pull_request_reviewers
+		WHERE pull_request_id=$1
+		ORDER BY user_id`
+
 	SelectPRsByReviewer = `
 		SELECT pr.pull_request_id AS pr_id, pr.pull_request_name, pr.author_id, pr.status
 		FROM pull_requests pr
 		JOIN pull_request_reviewers prr ON pr.pull_request_id = prr.pull_request_id
 		WHERE prr.user_id=$1;`
 
+	// у черновиков и PR со снятыми ревьюверами строка ревьювера пустая
 	GetOpenPRsByTeamName = `
 		SELECT pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status,
-		       u.user_id, u.username, u.team_name, u.is_active
+		       u.user_id, COALESCE(u.username, ''), COALESCE(u.team_name, ''),
+		       COALESCE(u.is_active, false), COALESCE(tm.role, '')
 		FROM pull_requests pr
 		LEFT JOIN pull_request_reviewers prr ON pr.pull_request_id = prr.pull_request_id
 		LEFT JOIN users u ON prr.user_id = u.user_id
+		LEFT JOIN team_members tm ON tm.user_id = u.user_id AND tm.team_name = pr.team_name
 		WHERE pr.status = 'OPEN'
-		  AND pr.author_id IN (SELECT user_id FROM users WHERE team_name = $1)
+		  AND pr.team_name = $1
 	`
+
+	// пары автор-ревьювер за окно в $3 секунд: вес назначения убывает линейно от 1 до 0 за окно
+	SelectReviewPairsByAuthor = `
+		SELECT pr.author_id, prr.user_id, COUNT(*), MAX(prr.assigned_at),
+		       SUM(1 - EXTRACT(EPOCH FROM NOW() - prr.assigned_at) / $3::float8)
+		FROM pull_request_reviewers prr
+		JOIN pull_requests pr ON pr.pull_request_id = prr.pull_request_id
+		WHERE pr.author_id = $1 AND prr.user_id = ANY($2)
+		  AND prr.assigned_at > NOW() - make_interval(secs => $3::float8)
+		GROUP BY pr.author_id, prr.user_id`
+
+	// матрица пар по PR команды за окно в $2 секунд
+	SelectReviewPairsByTeam = `
+		SELECT pr.author_id, prr.user_id, COUNT(*), MAX(prr.assigned_at),
+		       SUM(1 - EXTRACT(EPOCH FROM NOW() - prr.assigned_at) / $2::float8)
+		FROM pull_request_reviewers prr
+		JOIN pull_requests pr ON pr.pull_request_id = prr.pull_request_id
+		WHERE pr.team_name = $1
+		  AND prr.assigned_at > NOW() - make_interval(secs => $2::float8)
+		GROUP BY pr.author_id, prr.user_id
+		ORDER BY pr.author_id, prr.user_id`
+
+	SelectOpenReviewCountsByUsers = `
+		SELECT prr.user_id, COUNT(*)
+		FROM pull_request_reviewers prr
+		JOIN pull_requests pr ON pr.pull_request_id = prr.pull_request_id
+		WHERE pr.status = 'OPEN' AND prr.user_id = ANY($1)
+		GROUP BY prr.user_id`
+
+	// назначения без вердикта в открытых PR, у которых истек SLA команды PR и просрочка еще не отмечена
+	SelectBreachedReviews = `
+		SELECT pr.pull_request_id, pr.pull_request_name, pr.author_id, prr.user_id, prr.assigned_at,
+		       prr.assigned_at + make_interval(hours => ts.review_sla_hours) AS due_at, ts.auto_reassign_overdue
+		FROM pull_request_reviewers prr
+		JOIN pull_requests pr ON pr.pull_request_id = prr.pull_request_id
+		JOIN team_settings ts ON ts.team_name = pr.team_name
+		WHERE pr.status = 'OPEN' AND NOT pr.is_draft
+		  AND prr.verdict IS NULL AND prr.overdue_at IS NULL
+		  AND ts.review_sla_hours > 0
+		  AND prr.assigned_at + make_interval(hours => ts.review_sla_hours) < NOW()
+		ORDER BY prr.assigned_at`
+
+	UpdateReviewOverdue = `
+		UPDATE pull_request_reviewers SET overdue_at=$1
+		WHERE pull_request_id=$2 AND user_id=$3 AND overdue_at IS NULL`
+
+	SelectOverdueReviews = `
+		SELECT pr.pull_request_id, pr.pull_request_name, pr.author_id, prr.user_id, prr.assigned_at,
+		       prr.assigned_at + make_interval(hours => ts.review_sla_hours) AS due_at, prr.overdue_at
+		FROM pull_request_reviewers prr
+		JOIN pull_requests pr ON pr.pull_request_id = prr.pull_request_id
+		JOIN team_settings ts ON ts.team_name = pr.team_name
+		WHERE pr.status = 'OPEN' AND prr.verdict IS NULL AND prr.overdue_at IS NOT NULL
+		ORDER BY prr.overdue_at, pr.pull_request_id`
+)
+
+// OwnershipRepo
+const (
+	DeleteCodeOwnerRules = `
+		DELETE FROM code_owner_rules
+		WHERE repository=$1`
+
+	InsertCodeOwnerRule = `
+		INSERT INTO code_owner_rules(repository, position, pattern, users, teams)
+		VALUES($1, $2, $3, $4, $5)`
+
+	SelectCodeOwnerRules = `
+		SELECT pattern, users, teams FROM code_owner_rules
+		WHERE repository=$1
+		ORDER BY position`
+)
+
+// AbsenceRepo
+const (
+	InsertAbsence = `
+		INSERT INTO user_absences(user_id, starts_at, ends_at, reason)
+		VALUES($1, $2, $3, $4)
+		RETURNING absence_id, created_at`
+
+	SelectAbsencesByUser = `
+		SELECT absence_id, user_id, starts_at, ends_at, reason, reviews_reassigned, created_at
+		FROM user_absences
+		WHERE user_id=$1 AND ends_at > NOW()
+		ORDER BY starts_at`
+
+	SelectStartedPendingAbsences = `
+		SELECT absence_id, user_id, starts_at, ends_at, reason, reviews_reassigned, created_at
+		FROM user_absences
+		WHERE NOT reviews_reassigned AND starts_at <= NOW() AND ends_at > NOW()
+		ORDER BY starts_at`
+
+	UpdateAbsenceReassigned = `
+		UPDATE user_absences SET reviews_reassigned=true
+		WHERE absence_id=$1`
+)
+
+// журнал событий pr_events
+const (
+	InsertPREvent = `
+		INSERT INTO pr_events(event_type, pull_request_id, user_id, old_user_id, details)
+		VALUES($1, NULLIF($2, ''), NULLIF($3, ''), NULLIF($4, ''), $5)`
+
+	// фиксирует состав ревьюверов на момент слияния
+	InsertPRMergedEvent = `
+		INSERT INTO pr_events(event_type, pull_request_id, details)
+		SELECT 'PR_MERGED', $1::text,
+		       jsonb_build_object('reviewers', COALESCE(jsonb_agg(user_id ORDER BY user_id), '[]'::jsonb))
+		FROM pull_request_reviewers
+		WHERE pull_request_id = $1::text`
+
+	// по событию на каждого участника команды, у которого флаг действительно меняется
+	InsertTeamActivityEvents = `
+		INSERT INTO pr_events(event_type, user_id)
+		SELECT $1::text, user_id FROM users
+		WHERE user_id IN (SELECT user_id FROM team_members WHERE team_name = $2)
+		  AND is_active IS DISTINCT FROM $3::boolean`
+
+	// по событию смены команды на каждого участника команды $1
+	InsertTeamChangedEvents = `
+		INSERT INTO pr_events(event_type, user_id, details)
+		SELECT 'USER_TEAM_CHANGED', user_id, jsonb_build_object('from', $1::text, 'to', $2::text)
+		FROM team_members
+		WHERE team_name = $1::text`
+
+	SelectPREventsByPR = `
+		SELECT event_id, event_type, COALESCE(pull_request_id, ''), COALESCE(user_id, ''), COALESCE(old_user_id, ''), details, created_at
+		FROM pr_events
+		WHERE pull_request_id=$1
+		ORDER BY event_id`
+
+	SelectPREventsByUser = `
+		SELECT event_id, event_type, COALESCE(pull_request_id, ''), COALESCE(user_id, ''), COALESCE(old_user_id, ''), details, created_at
+		FROM pr_events
+		WHERE user_id=$1 OR old_user_id=$1
+		ORDER BY event_id`
+)
+
+// ExclusionRepo
+const (
+	InsertReviewExclusion = `
+		INSERT INTO review_exclusions(reviewer_id, author_id, reason)
+		VALUES($1, $2, $3)
+		ON CONFLICT DO NOTHING
+		RETURNING created_at`
+
+	DeleteReviewExclusion = `
+		DELETE FROM review_exclusions
+		WHERE reviewer_id=$1 AND author_id=$2`
+
+	// пустой $1 - все правила, иначе правила, где пользователь ревьювер или автор
+	SelectReviewExclusions = `
+		SELECT reviewer_id, author_id, reason, created_at
+		FROM review_exclusions
+		WHERE $1 = '' OR reviewer_id = $1 OR author_id = $1
+		ORDER BY author_id, reviewer_id`
+
+	SelectExcludedReviewers = `
+		SELECT reviewer_id FROM review_exclusions
+		WHERE author_id=$1
+		ORDER BY reviewer_id`
+)
+
+// SnapshotRepo
+const (
+	SelectSnapshotTeams = `
+		SELECT team_name FROM teams
+		ORDER BY team_name`
+
+	SelectSnapshotTeamSettings = `
+		SELECT team_name, reviewers_count, min_reviewers, strategy, allow_cross_team, approvals_required, max_open_reviews,
+		       review_sla_hours, auto_reassign_overdue, max_reviewers, require_lead, rotation_window_days
+		FROM team_settings`
+
+	SelectSnapshotFallbacks = `
+		SELECT team_name, fallback_team FROM team_fallbacks
+		ORDER BY team_name, position`
+
+	SelectSnapshotMembers = `
+		SELECT team_name, user_id, role FROM team_members
+		ORDER BY team_name, user_id`
+
+	SelectSnapshotUsers = `
+		SELECT user_id, username, COALESCE(team_name, ''), COALESCE(is_active, false), max_open_reviews, deleted_at
+		FROM users
+		ORDER BY user_id`
+
+	SelectSnapshotPRs = `
+		SELECT pull_request_id, pull_request_name, author_id, team_name, COALESCE(status, 'OPEN'), is_draft, orphaned,
+		       repository, COALESCE(created_at, now()), merged_at, closed_at
+		FROM pull_requests
+		ORDER BY created_at, pull_request_id`
+
+	SelectSnapshotPRFiles = `
+		SELECT pull_request_id, path FROM pull_request_files
+		ORDER BY pull_request_id, path`
+
+	SelectSnapshotPRLabels = `
+		SELECT pull_request_id, label FROM pull_request_labels
+		ORDER BY pull_request_id, label`
+
+	SelectSnapshotReviewers = `
+		SELECT pull_request_id, user_id, COALESCE(verdict, ''), assigned_at, reviewed_at, overdue_at
+		FROM pull_request_reviewers
+		ORDER BY pull_request_id, assigned_at, user_id`
+
+	// восстановление меняет состояние целиком, чтение не блокируется
+	LockSnapshotTables = `
+		LOCK TABLE users, teams, team_members, team_settings, team_fallbacks,
+		           pull_requests, pull_request_reviewers, pull_request_files, pull_request_labels
+		IN EXCLUSIVE MODE`
+
+	// файлы, метки, настройки, резервные команды и участие удаляются каскадом
+	DeleteAllReviewers = `DELETE FROM pull_request_reviewers`
+	DeleteAllPRs       = `DELETE FROM pull_requests`
+	DeleteAllTeams     = `DELETE FROM teams`
+
+	UpsertSnapshotUser = `
+		INSERT INTO users(user_id, username, team_name, is_active, max_open_reviews, deleted_at)
+		VALUES($1, $2, $3, $4, $5, $6)
+		ON CONFLICT(user_id) DO UPDATE
+		SET username = EXCLUDED.username,
+		team_name = EXCLUDED.team_name,
+		is_active = EXCLUDED.is_active,
+		max_open_reviews = EXCLUDED.max_open_reviews,
+		deleted_at = EXCLUDED.deleted_at`
+
+	// пользователи не из снимка не удаляются физически: на них ссылаются история, теги и отсутствия
+	RetireUsersNotInSnapshot = `
+		UPDATE users SET deleted_at=now(), is_active=false, team_name=''
+		WHERE deleted_at IS NULL AND NOT (user_id = ANY($1))`
+
+	InsertSnapshotPR = `
+		INSERT INTO pull_requests(pull_request_id, pull_request_name, author_id, team_name, status, is_draft, orphaned,
+		                          repository, created_at, merged_at, closed_at)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+
+	InsertSnapshotReviewer = `
+		INSERT INTO pull_request_reviewers(pull_request_id, user_id, verdict, assigned_at, reviewed_at, overdue_at)
+		VALUES($1, $2, NULLIF($3, ''), $4, $5, $6)`
+)
+
+// IdentityRepo
+const (
+	// повторная привязка логина переносит его на другого пользователя
+	UpsertGitHubIdentity = `
+		INSERT INTO github_identities(github_login, user_id)
+		VALUES($1, $2)
+		ON CONFLICT (github_login) DO UPDATE SET user_id = EXCLUDED.user_id, created_at = now()
+		RETURNING created_at`
+
+	DeleteGitHubIdentity = `
+		DELETE FROM github_identities
+		WHERE github_login=$1`
+
+	SelectUserByGitHubLogin = `
+		SELECT user_id FROM github_identities
+		WHERE github_login=$1`
+
+	// пустой $1 - все привязки, иначе привязки пользователя
+	SelectGitHubIdentities = `
+		SELECT github_login, user_id, created_at
+		FROM github_identities
+		WHERE $1 = '' OR user_id = $1
+		ORDER BY github_login`
+
+	// повторная доставка с тем же id не вставляется
+	InsertGitHubDelivery = `
+		INSERT INTO github_deliveries(delivery_id, event)
+		VALUES($1, $2)
+		ON CONFLICT (delivery_id) DO NOTHING`
+
+	DeleteGitHubDelivery = `
+		DELETE FROM github_deliveries
+		WHERE delivery_id=$1`
 )
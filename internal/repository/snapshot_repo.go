@@ -0,0 +1,366 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/domain"
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/repository/queries"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+// SnapshotRepo - репо снимков состояния сервиса
+type SnapshotRepo struct {
+	db     *sql.DB
+	logger *zap.SugaredLogger
+}
+
+// NewSnapshotRepo создает новое репо снимков
+func NewSnapshotRepo(db *sql.DB, logger *zap.SugaredLogger) *SnapshotRepo {
+	return &SnapshotRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Export читает снимок в одной транзакции только для чтения, поэтому он согласован
+// даже при параллельных изменениях
+func (r *SnapshotRepo) Export() (*domain.Snapshot, error) {
+	tx, err := r.db.BeginTx(context.Background(), &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			r.logger.Errorf("rollback failed: %v", err)
+		}
+	}()
+
+	snapshot := &domain.Snapshot{Version: domain.SnapshotVersion, CreatedAt: time.Now().UTC()}
+	if snapshot.Teams, err = r.exportTeams(tx); err != nil {
+		r.logger.Errorf("failed to export teams: %v", err)
+		return nil, err
+	}
+	if snapshot.Users, err = r.exportUsers(tx); err != nil {
+		r.logger.Errorf("failed to export users: %v", err)
+		return nil, err
+	}
+	if snapshot.PullRequests, err = r.exportPRs(tx); err != nil {
+		r.logger.Errorf("failed to export pull requests: %v", err)
+		return nil, err
+	}
+	return snapshot, tx.Commit()
+}
+
+// exportTeams читает команды с настройками, резервными командами и участниками
+func (r *SnapshotRepo) exportTeams(tx *sql.Tx) ([]*domain.SnapshotTeam, error) {
+	teams := []*domain.SnapshotTeam{}
+	byName := map[string]*domain.SnapshotTeam{}
+	err := queryRows(tx, r.logger, queries.SelectSnapshotTeams, func(rows *sql.Rows) error {
+		team := &domain.SnapshotTeam{Members: []*domain.SnapshotMember{}}
+		if err := rows.Scan(&team.TeamName); err != nil {
+			return err
+		}
+		teams = append(teams, team)
+		byName[team.TeamName] = team
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = queryRows(tx, r.logger, queries.SelectSnapshotTeamSettings, func(rows *sql.Rows) error {
+		s := &domain.TeamSettings{FallbackTeams: []string{}}
+		if err := rows.Scan(
+			&s.TeamName, &s.ReviewersCount, &s.MinReviewers, &s.Strategy, &s.AllowCrossTeam,
+			&s.ApprovalsRequired, &s.MaxOpenReviews, &s.ReviewSLAHours, &s.AutoReassignOverdue,
+			&s.MaxReviewers, &s.RequireLead, &s.RotationWindowDays,
+		); err != nil {
+			return err
+		}
+		if team, ok := byName[s.TeamName]; ok {
+			team.Settings = s
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = queryRows(tx, r.logger, queries.SelectSnapshotFallbacks, func(rows *sql.Rows) error {
+		var teamName, fallback string
+		if err := rows.Scan(&teamName, &fallback); err != nil {
+			return err
+		}
+		if team, ok := byName[teamName]; ok && team.Settings != nil {
+			team.Settings.FallbackTeams = append(team.Settings.FallbackTeams, fallback)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = queryRows(tx, r.logger, queries.SelectSnapshotMembers, func(rows *sql.Rows) error {
+		var teamName string
+		member := &domain.SnapshotMember{}
+		if err := rows.Scan(&teamName, &member.UserID, &member.Role); err != nil {
+			return err
+		}
+		if team, ok := byName[teamName]; ok {
+			team.Members = append(team.Members, member)
+		}
+		return nil
+	})
+	return teams, err
+}
+
+// exportUsers читает всех пользователей, включая удаленных
+func (r *SnapshotRepo) exportUsers(tx *sql.Tx) ([]*domain.SnapshotUser, error) {
+	users := []*domain.SnapshotUser{}
+	err := queryRows(tx, r.logger, queries.SelectSnapshotUsers, func(rows *sql.Rows) error {
+		u := &domain.SnapshotUser{}
+		var maxOpen sql.NullInt64
+		var deletedAt sql.NullTime
+		if err := rows.Scan(&u.UserID, &u.Username, &u.TeamName, &u.IsActive, &maxOpen, &deletedAt); err != nil {
+			return err
+		}
+		if maxOpen.Valid {
+			limit := int(maxOpen.Int64)
+			u.MaxOpenReviews = &limit
+		}
+		u.DeletedAt = nullTimePtr(deletedAt)
+		users = append(users, u)
+		return nil
+	})
+	return users, err
+}
+
+// exportPRs читает PR вместе с файлами, метками и ревьюверами
+func (r *SnapshotRepo) exportPRs(tx *sql.Tx) ([]*domain.SnapshotPR, error) {
+	prs := []*domain.SnapshotPR{}
+	byID := map[string]*domain.SnapshotPR{}
+	err := queryRows(tx, r.logger, queries.SelectSnapshotPRs, func(rows *sql.Rows) error {
+		pr := &domain.SnapshotPR{ChangedFiles: []string{}, Labels: []string{}, Reviewers: []*domain.SnapshotReviewer{}}
+		var mergedAt, closedAt sql.NullTime
+		if err := rows.Scan(
+			&pr.PRID, &pr.PRName, &pr.AuthorID, &pr.TeamName, &pr.Status, &pr.IsDraft, &pr.Orphaned,
+			&pr.Repository, &pr.CreatedAt, &mergedAt, &closedAt,
+		); err != nil {
+			return err
+		}
+		pr.MergedAt = nullTimePtr(mergedAt)
+		pr.ClosedAt = nullTimePtr(closedAt)
+		prs = append(prs, pr)
+		byID[pr.PRID] = pr
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = queryRows(tx, r.logger, queries.SelectSnapshotPRFiles, func(rows *sql.Rows) error {
+		var prID, path string
+		if err := rows.Scan(&prID, &path); err != nil {
+			return err
+		}
+		if pr, ok := byID[prID]; ok {
+			pr.ChangedFiles = append(pr.ChangedFiles, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = queryRows(tx, r.logger, queries.SelectSnapshotPRLabels, func(rows *sql.Rows) error {
+		var prID, label string
+		if err := rows.Scan(&prID, &label); err != nil {
+			return err
+		}
+		if pr, ok := byID[prID]; ok {
+			pr.Labels = append(pr.Labels, label)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = queryRows(tx, r.logger, queries.SelectSnapshotReviewers, func(rows *sql.Rows) error {
+		var prID string
+		var reviewedAt, overdueAt sql.NullTime
+		reviewer := &domain.SnapshotReviewer{}
+		if err := rows.Scan(&prID, &reviewer.UserID, &reviewer.Verdict, &reviewer.AssignedAt, &reviewedAt, &overdueAt); err != nil {
+			return err
+		}
+		reviewer.ReviewedAt = nullTimePtr(reviewedAt)
+		reviewer.OverdueAt = nullTimePtr(overdueAt)
+		if pr, ok := byID[prID]; ok {
+			pr.Reviewers = append(pr.Reviewers, reviewer)
+		}
+		return nil
+	})
+	return prs, err
+}
+
+// Restore атомарно заменяет команды, PR и назначения содержимым снимка. Пользователи из снимка
+// создаются или перезаписываются, остальные помечаются удаленными; история pr_events сохраняется
+func (r *SnapshotRepo) Restore(snapshot *domain.Snapshot) (*domain.SnapshotRestore, error) {
+	result := &domain.SnapshotRestore{}
+	err := RunInTx(r.db, r.logger, func(tx *sql.Tx) error {
+		if _, err := tx.Exec(queries.LockSnapshotTables); err != nil {
+			return err
+		}
+		for _, query := range []string{queries.DeleteAllReviewers, queries.DeleteAllPRs, queries.DeleteAllTeams} {
+			if _, err := tx.Exec(query); err != nil {
+				return err
+			}
+		}
+
+		// пользователи раньше команд и PR: на них ссылаются участие и ревью
+		userIDs := make([]string, 0, len(snapshot.Users))
+		for _, u := range snapshot.Users {
+			if _, err := tx.Exec(queries.UpsertSnapshotUser,
+				u.UserID, u.Username, u.TeamName, u.IsActive, u.MaxOpenReviews, u.DeletedAt,
+			); err != nil {
+				r.logger.Errorf("failed to restore user %s: %v", u.UserID, err)
+				return err
+			}
+			userIDs = append(userIDs, u.UserID)
+		}
+		res, err := tx.Exec(queries.RetireUsersNotInSnapshot, pq.Array(userIDs))
+		if err != nil {
+			return err
+		}
+		retired, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if err := restoreTeams(tx, snapshot.Teams); err != nil {
+			r.logger.Errorf("failed to restore teams: %v", err)
+			return err
+		}
+		reviewers, err := restorePRs(tx, snapshot.PullRequests)
+		if err != nil {
+			r.logger.Errorf("failed to restore pull requests: %v", err)
+			return err
+		}
+
+		*result = domain.SnapshotRestore{
+			Teams:        len(snapshot.Teams),
+			Users:        len(snapshot.Users),
+			PullRequests: len(snapshot.PullRequests),
+			Reviewers:    reviewers,
+			RetiredUsers: int(retired),
+		}
+		return insertEvent(tx, &domain.PREvent{
+			Type: domain.EventSnapshotRestored,
+			Details: map[string]any{
+				"version": snapshot.Version, "created_at": snapshot.CreatedAt,
+				"teams": result.Teams, "users": result.Users, "pull_requests": result.PullRequests,
+				"retired_users": result.RetiredUsers,
+			},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// restoreTeams вставляет команды, затем настройки и участников: резервные команды ссылаются на другие команды
+func restoreTeams(tx *sql.Tx, teams []*domain.SnapshotTeam) error {
+	for _, team := range teams {
+		if _, err := tx.Exec(queries.InsertTeam, team.TeamName); err != nil {
+			return err
+		}
+	}
+
+	for _, team := range teams {
+		if s := team.Settings; s != nil {
+			if _, err := tx.Exec(queries.UpsertTeamSettings,
+				team.TeamName, s.ReviewersCount, s.MinReviewers, s.Strategy, s.AllowCrossTeam,
+				s.ApprovalsRequired, s.MaxOpenReviews, s.ReviewSLAHours, s.AutoReassignOverdue,
+				s.MaxReviewers, s.RequireLead, s.RotationWindowDays,
+			); err != nil {
+				return err
+			}
+			for i, fallback := range s.FallbackTeams {
+				if _, err := tx.Exec(queries.InsertTeamFallback, team.TeamName, fallback, i); err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, member := range team.Members {
+			if _, err := tx.Exec(queries.InsertTeamMember, team.TeamName, member.UserID, member.Role); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// restorePRs вставляет PR с файлами, метками и ревьюверами, возвращает число назначений
+func restorePRs(tx *sql.Tx, prs []*domain.SnapshotPR) (int, error) {
+	reviewers := 0
+	for _, pr := range prs {
+		if _, err := tx.Exec(queries.InsertSnapshotPR,
+			pr.PRID, pr.PRName, pr.AuthorID, pr.TeamName, pr.Status, pr.IsDraft, pr.Orphaned,
+			pr.Repository, pr.CreatedAt, pr.MergedAt, pr.ClosedAt,
+		); err != nil {
+			return 0, err
+		}
+		for _, path := range pr.ChangedFiles {
+			if _, err := tx.Exec(queries.InsertPRFile, pr.PRID, path); err != nil {
+				return 0, err
+			}
+		}
+		for _, label := range pr.Labels {
+			if _, err := tx.Exec(queries.InsertPRLabel, pr.PRID, label); err != nil {
+				return 0, err
+			}
+		}
+		for _, reviewer := range pr.Reviewers {
+			if _, err := tx.Exec(queries.InsertSnapshotReviewer,
+				pr.PRID, reviewer.UserID, reviewer.Verdict, reviewer.AssignedAt, reviewer.ReviewedAt, reviewer.OverdueAt,
+			); err != nil {
+				return 0, err
+			}
+			reviewers++
+		}
+	}
+	return reviewers, nil
+}
+
+// queryRows выполняет запрос в транзакции и передает каждую строку в scan
+func queryRows(tx *sql.Tx, logger *zap.SugaredLogger, query string, scan func(rows *sql.Rows) error) error {
+	rows, err := tx.Query(query)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logger.Errorf("rows close failed: %v", err)
+		}
+	}()
+
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// nullTimePtr переводит NULL-время в nil
+func nullTimePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
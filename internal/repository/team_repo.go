@@ -10,8 +10,15 @@ import (
 )
 
 var (
-	ErrTeamExists   = errors.New("TEAM_EXISTS")
-	ErrTeamNotFound = errors.New("NOT_FOUND")
+	ErrTeamExists           = errors.New("TEAM_EXISTS")
+	ErrTeamNotFound         = errors.New("NOT_FOUND")
+	ErrTeamSettingsNotFound = errors.New("team settings not found")
+	ErrMemberNotFound       = errors.New("user not found")
+	ErrNotTeamMember        = errors.New("user is not a member of the team")
+	ErrAlreadyTeamMember    = errors.New("user is already a member of the team")
+
+	// откатывает транзакцию пробного импорта
+	errImportDryRun = errors.New("dry run")
 )
 
 // TeamRepo - репо пользователей
@@ -56,11 +63,14 @@ func (r *TeamRepo) CreateTeamWithUsers(teamName string, members []*domain.User)
 		return err
 	}
 
-	// вставка/обновление пользователей
+	// вставка/обновление пользователей, у состоящих в других командах основная команда не меняется
 	for _, u := range members {
 		u.TeamName = teamName
 		r.logger.Infof("Inserting user: %s, %s, %s, %v", u.UserID, u.Username, u.TeamName, u.IsActive)
-		if _, err := tx.Exec(queries.InsertOrUpdateUser, u.UserID, u.Username, u.TeamName, u.IsActive); err != nil {
+		if err := upsertUser(tx, u); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(queries.InsertTeamMember, teamName, u.UserID, u.Role); err != nil {
 			return err
 		}
 	}
@@ -84,7 +94,7 @@ func (r *TeamRepo) GetUsersByTeam(teamName string) ([]*domain.User, error) {
 	var users []*domain.User
 	for rows.Next() {
 		u := &domain.User{}
-		if err := rows.Scan(&u.UserID, &u.Username, &u.TeamName, &u.IsActive); err != nil {
+		if err := rows.Scan(&u.UserID, &u.Username, &u.TeamName, &u.IsActive, &u.Role); err != nil {
 			r.logger.Errorf("SQL error: failed to scan user row: %v", err)
 			return nil, err
 		}
@@ -118,3 +128,465 @@ func (r *TeamRepo) ListAllTeams() ([]*domain.Team, error) {
 	}
 	return teams, nil
 }
+
+// GetSettings получает сохраненные настройки команды
+func (r *TeamRepo) GetSettings(teamName string) (*domain.TeamSettings, error) {
+	var settings domain.TeamSettings
+	err := r.db.QueryRow(queries.SelectTeamSettings, teamName).Scan(
+		&settings.TeamName, &settings.ReviewersCount, &settings.MinReviewers, &settings.Strategy, &settings.AllowCrossTeam,
+		&settings.ApprovalsRequired, &settings.MaxOpenReviews, &settings.ReviewSLAHours, &settings.AutoReassignOverdue,
+		&settings.MaxReviewers, &settings.RequireLead, &settings.RotationWindowDays,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTeamSettingsNotFound
+		}
+		r.logger.Errorf("failed to get settings for team %s: %v", teamName, err)
+		return nil, err
+	}
+
+	// резервные команды в порядке приоритета
+	rows, err := r.db.Query(queries.SelectTeamFallbacks, teamName)
+	if err != nil {
+		r.logger.Errorf("failed to get fallback teams for team %s: %v", teamName, err)
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			r.logger.Errorf("rows close failed: %v", err)
+		}
+	}()
+
+	settings.FallbackTeams = []string{}
+	for rows.Next() {
+		var fallback string
+		if err := rows.Scan(&fallback); err != nil {
+			r.logger.Errorf("failed to scan fallback team row: %v", err)
+			return nil, err
+		}
+		settings.FallbackTeams = append(settings.FallbackTeams, fallback)
+	}
+	return &settings, rows.Err()
+}
+
+// UpsertSettings атомарно создает или обновляет настройки команды вместе с резервными командами
+func (r *TeamRepo) UpsertSettings(settings *domain.TeamSettings) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			r.logger.Errorf("rollback failed: %v", err)
+		}
+	}()
+
+	if _, err := tx.Exec(queries.UpsertTeamSettings,
+		settings.TeamName, settings.ReviewersCount, settings.MinReviewers, settings.Strategy, settings.AllowCrossTeam,
+		settings.ApprovalsRequired, settings.MaxOpenReviews, settings.ReviewSLAHours, settings.AutoReassignOverdue,
+		settings.MaxReviewers, settings.RequireLead, settings.RotationWindowDays,
+	); err != nil {
+		r.logger.Errorf("failed to upsert settings for team %s: %v", settings.TeamName, err)
+		return err
+	}
+
+	// список резервных команд перезаписывается целиком
+	if _, err := tx.Exec(queries.DeleteTeamFallbacks, settings.TeamName); err != nil {
+		return err
+	}
+	for i, fallback := range settings.FallbackTeams {
+		if _, err := tx.Exec(queries.InsertTeamFallback, settings.TeamName, fallback, i); err != nil {
+			r.logger.Errorf("failed to insert fallback team %s for team %s: %v", fallback, settings.TeamName, err)
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// AddMembers атомарно добавляет в команду новых или уже существующих пользователей;
+// для пользователя без команды она становится основной
+func (r *TeamRepo) AddMembers(teamName string, members []*domain.User) error {
+	return RunInTx(r.db, r.logger, func(tx *sql.Tx) error {
+		for _, u := range members {
+			primary, wasActive, err := lockUserTeam(tx, u.UserID)
+			switch {
+			case errors.Is(err, ErrMemberNotFound):
+				if _, err := tx.Exec(queries.InsertOrUpdateUser, u.UserID, u.Username, teamName, u.IsActive); err != nil {
+					return err
+				}
+				primary = teamName
+			case err != nil:
+				return err
+			default:
+				member, err := isTeamMember(tx, teamName, u.UserID)
+				if err != nil {
+					return err
+				}
+				if member {
+					return ErrAlreadyTeamMember
+				}
+
+				if primary == "" {
+					primary = teamName
+				}
+				if _, err := tx.Exec(queries.UpdateUserTeamAndProfile, primary, u.Username, u.IsActive, u.UserID); err != nil {
+					return err
+				}
+				if wasActive != u.IsActive {
+					if err := insertEvent(tx, &domain.PREvent{Type: activityEvent(u.IsActive), UserID: u.UserID}); err != nil {
+						return err
+					}
+				}
+			}
+
+			if _, err := tx.Exec(queries.InsertTeamMember, teamName, u.UserID, u.Role); err != nil {
+				return err
+			}
+			u.TeamName = primary
+			if err := insertTeamChangedEvent(tx, u.UserID, "", teamName); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// RemoveMember выводит пользователя из команды; если других команд у него не осталось,
+// он деактивируется, сам пользователь и его история сохраняются
+func (r *TeamRepo) RemoveMember(teamName, userID string) error {
+	return RunInTx(r.db, r.logger, func(tx *sql.Tx) error {
+		return leaveTeam(tx, teamName, userID)
+	})
+}
+
+// MoveMember переводит пользователя из основной команды в другую и возвращает прежнюю основную,
+// участие в остальных командах сохраняется
+func (r *TeamRepo) MoveMember(userID, teamName string) (string, error) {
+	var previous string
+	err := RunInTx(r.db, r.logger, func(tx *sql.Tx) error {
+		current, _, err := lockUserTeam(tx, userID)
+		if err != nil {
+			return err
+		}
+		member, err := isTeamMember(tx, teamName, userID)
+		if err != nil {
+			return err
+		}
+		if member {
+			return ErrAlreadyTeamMember
+		}
+		previous = current
+
+		if _, err := tx.Exec(queries.DeleteTeamMember, current, userID); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(queries.InsertTeamMember, teamName, userID, ""); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(queries.UpdateUserTeam, teamName, userID); err != nil {
+			return err
+		}
+		return insertTeamChangedEvent(tx, userID, current, teamName)
+	})
+	return previous, err
+}
+
+// SetMemberRole меняет роль участника команды и возвращает прежнюю
+func (r *TeamRepo) SetMemberRole(teamName, userID, role string) (string, error) {
+	var previous string
+	err := RunInTx(r.db, r.logger, func(tx *sql.Tx) error {
+		if err := tx.QueryRow(queries.SelectTeamMemberRoleForUpdate, teamName, userID).Scan(&previous); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrNotTeamMember
+			}
+			return err
+		}
+		if previous == role {
+			return nil
+		}
+
+		if _, err := tx.Exec(queries.UpdateTeamMemberRole, role, teamName, userID); err != nil {
+			return err
+		}
+		return insertEvent(tx, &domain.PREvent{
+			Type: domain.EventUserRoleChanged, UserID: userID,
+			Details: map[string]any{"team_name": teamName, "from": previous, "to": role},
+		})
+	})
+	return previous, err
+}
+
+// RenameTeam переименовывает команду; участие, настройки и резервные команды переносятся каскадом
+func (r *TeamRepo) RenameTeam(oldName, newName string) error {
+	return RunInTx(r.db, r.logger, func(tx *sql.Tx) error {
+		// события пишутся до переноса, пока участники видны под старым именем
+		if _, err := tx.Exec(queries.InsertTeamChangedEvents, oldName, newName); err != nil {
+			return err
+		}
+
+		res, err := tx.Exec(queries.RenameTeam, newName, oldName)
+		if err != nil {
+			return err
+		}
+		if rows, err := res.RowsAffected(); err != nil || rows == 0 {
+			if err == nil {
+				err = ErrTeamNotFound
+			}
+			return err
+		}
+
+		if _, err := tx.Exec(queries.MoveTeamUsers, newName, oldName); err != nil {
+			return err
+		}
+		_, err = tx.Exec(queries.MoveTeamPRs, newName, oldName)
+		return err
+	})
+}
+
+// DeleteTeam удаляет команду вместе с настройками, ее участники выходят из нее так же,
+// как при RemoveMember; возвращает их идентификаторы
+func (r *TeamRepo) DeleteTeam(teamName string) ([]string, error) {
+	removed := []string{}
+	err := RunInTx(r.db, r.logger, func(tx *sql.Tx) error {
+		rows, err := tx.Query(queries.SelectTeamMemberIDs, teamName)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			var userID string
+			if err := rows.Scan(&userID); err != nil {
+				rows.Close()
+				return err
+			}
+			removed = append(removed, userID)
+		}
+		if err := rows.Close(); err != nil {
+			return err
+		}
+
+		for _, userID := range removed {
+			if err := leaveTeam(tx, teamName, userID); err != nil {
+				return err
+			}
+		}
+
+		res, err := tx.Exec(queries.DeleteTeam, teamName)
+		if err != nil {
+			return err
+		}
+		if rows, err := res.RowsAffected(); err != nil || rows == 0 {
+			if err == nil {
+				err = ErrTeamNotFound
+			}
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return removed, nil
+}
+
+// ImportTeams атомарно приводит основные команды, имена и активность пользователей к файлу импорта:
+// недостающие команды и пользователи создаются, пользователь из другой основной команды переводится
+// (как MoveMember, участие в остальных командах сохраняется), отсутствующие в файле не трогаются.
+// При dryRun те же изменения выполняются в транзакции, которая откатывается
+func (r *TeamRepo) ImportTeams(teams []*domain.Team, dryRun bool) (*domain.TeamImportReport, error) {
+	report := &domain.TeamImportReport{
+		DryRun:       dryRun,
+		TeamsCreated: []string{},
+		UsersCreated: []string{},
+		UsersUpdated: []string{},
+		UsersMoved:   []*domain.UserMove{},
+	}
+
+	err := RunInTx(r.db, r.logger, func(tx *sql.Tx) error {
+		for _, t := range teams {
+			res, err := tx.Exec(queries.InsertTeamIfMissing, t.TeamName)
+			if err != nil {
+				return err
+			}
+			if rows, err := res.RowsAffected(); err != nil {
+				return err
+			} else if rows > 0 {
+				report.TeamsCreated = append(report.TeamsCreated, t.TeamName)
+			}
+
+			for _, u := range t.Members {
+				if err := importMember(tx, t.TeamName, u, report); err != nil {
+					r.logger.Errorf("failed to import user %s into team %s: %v", u.UserID, t.TeamName, err)
+					return err
+				}
+			}
+		}
+
+		if dryRun {
+			return errImportDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errImportDryRun) {
+		return nil, err
+	}
+	return report, nil
+}
+
+// importMember приводит одного пользователя к строке импорта и отмечает изменение в отчете
+func importMember(tx *sql.Tx, teamName string, u *domain.User, report *domain.TeamImportReport) error {
+	var primary, username string
+	var wasActive bool
+	err := tx.QueryRow(queries.SelectUserProfileForUpdate, u.UserID).Scan(&primary, &username, &wasActive)
+	if errors.Is(err, sql.ErrNoRows) {
+		// новый или удаленный ранее пользователь
+		if _, err := tx.Exec(queries.InsertOrUpdateUser, u.UserID, u.Username, teamName, u.IsActive); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(queries.InsertTeamMember, teamName, u.UserID, ""); err != nil {
+			return err
+		}
+		report.UsersCreated = append(report.UsersCreated, u.UserID)
+		return insertTeamChangedEvent(tx, u.UserID, "", teamName)
+	}
+	if err != nil {
+		return err
+	}
+
+	if primary == teamName {
+		if username == u.Username && wasActive == u.IsActive {
+			report.Unchanged++
+			return nil
+		}
+		report.UsersUpdated = append(report.UsersUpdated, u.UserID)
+	} else {
+		if primary != "" {
+			if _, err := tx.Exec(queries.DeleteTeamMember, primary, u.UserID); err != nil {
+				return err
+			}
+		}
+		// участник команды не основной просто делает ее основной
+		if _, err := tx.Exec(queries.InsertTeamMember, teamName, u.UserID, ""); err != nil {
+			return err
+		}
+		if err := insertTeamChangedEvent(tx, u.UserID, primary, teamName); err != nil {
+			return err
+		}
+		report.UsersMoved = append(report.UsersMoved, &domain.UserMove{UserID: u.UserID, From: primary, To: teamName})
+	}
+
+	if _, err := tx.Exec(queries.UpdateUserTeamAndProfile, teamName, u.Username, u.IsActive, u.UserID); err != nil {
+		return err
+	}
+	if wasActive != u.IsActive {
+		return insertEvent(tx, &domain.PREvent{Type: activityEvent(u.IsActive), UserID: u.UserID})
+	}
+	return nil
+}
+
+// ExportTeams возвращает все команды с участниками по основной команде, упорядоченные по имени
+func (r *TeamRepo) ExportTeams() ([]*domain.Team, error) {
+	rows, err := r.db.Query(queries.SelectTeamsForExport)
+	if err != nil {
+		r.logger.Errorf("failed to export teams: %v", err)
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			r.logger.Errorf("rows close failed: %v", err)
+		}
+	}()
+
+	teams := []*domain.Team{}
+	var current *domain.Team
+	for rows.Next() {
+		var teamName string
+		u := &domain.User{}
+		if err := rows.Scan(&teamName, &u.UserID, &u.Username, &u.IsActive); err != nil {
+			r.logger.Errorf("failed to scan exported team row: %v", err)
+			return nil, err
+		}
+		if current == nil || current.TeamName != teamName {
+			current = &domain.Team{TeamName: teamName, Members: []*domain.User{}}
+			teams = append(teams, current)
+		}
+		if u.UserID != "" {
+			u.TeamName = teamName
+			current.Members = append(current.Members, u)
+		}
+	}
+	return teams, rows.Err()
+}
+
+// leaveTeam выводит пользователя из команды: если она была основной, основной становится
+// следующая из оставшихся, а без команд пользователь деактивируется
+func leaveTeam(tx *sql.Tx, teamName, userID string) error {
+	primary, wasActive, err := lockUserTeam(tx, userID)
+	if errors.Is(err, ErrMemberNotFound) {
+		return ErrNotTeamMember
+	}
+	if err != nil {
+		return err
+	}
+
+	res, err := tx.Exec(queries.DeleteTeamMember, teamName, userID)
+	if err != nil {
+		return err
+	}
+	if rows, err := res.RowsAffected(); err != nil || rows == 0 {
+		if err == nil {
+			err = ErrNotTeamMember
+		}
+		return err
+	}
+
+	if primary == teamName {
+		var next string
+		if err := tx.QueryRow(queries.SelectNextPrimaryTeam, userID).Scan(&next); err != nil {
+			return err
+		}
+
+		if next != "" {
+			if _, err := tx.Exec(queries.UpdateUserTeam, next, userID); err != nil {
+				return err
+			}
+		} else {
+			if _, err := tx.Exec(queries.DetachUserFromTeam, userID); err != nil {
+				return err
+			}
+			if wasActive {
+				if err := insertEvent(tx, &domain.PREvent{Type: domain.EventUserDeactivated, UserID: userID}); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return insertTeamChangedEvent(tx, userID, teamName, "")
+}
+
+// isTeamMember проверяет участие пользователя в команде
+func isTeamMember(tx *sql.Tx, teamName, userID string) (bool, error) {
+	var member bool
+	err := tx.QueryRow(queries.SelectTeamMemberExists, teamName, userID).Scan(&member)
+	return member, err
+}
+
+// lockUserTeam блокирует строку пользователя и возвращает его основную команду и активность
+func lockUserTeam(tx *sql.Tx, userID string) (string, bool, error) {
+	var teamName string
+	var isActive bool
+	if err := tx.QueryRow(queries.SelectUserTeamForUpdate, userID).Scan(&teamName, &isActive); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", false, ErrMemberNotFound
+		}
+		return "", false, err
+	}
+	return teamName, isActive, nil
+}
+
+// insertTeamChangedEvent пишет событие смены команды, пустое имя - без команды
+func insertTeamChangedEvent(tx *sql.Tx, userID, from, to string) error {
+	return insertEvent(tx, &domain.PREvent{
+		Type: domain.EventUserTeamChanged, UserID: userID, Details: map[string]any{"from": from, "to": to},
+	})
+}
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"database/sql"
+
+	"go.uber.org/zap"
+)
+
+// RunInTx выполняет fn в транзакции: коммит при успехе, откат при ошибке
+func RunInTx(conn *sql.DB, logger *zap.SugaredLogger, fn func(tx *sql.Tx) error) error {
+	tx, err := conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			logger.Errorf("rollback failed: %v", err)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
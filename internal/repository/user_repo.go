@@ -8,9 +8,15 @@ import (
 	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/domain"
 	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/repository/db"
 	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/repository/queries"
+	"github.com/lib/pq"
 	"go.uber.org/zap"
 )
 
+var (
+	ErrUserExists   = errors.New("USER_EXISTS")
+	ErrUserNotFound = errors.New("user not found")
+)
+
 // UserRepo репозиторий для работы с пользователями
 type UserRepo struct {
 	db     *sql.DB
@@ -25,19 +31,100 @@ func NewUserRepo(db *sql.DB, logger *zap.SugaredLogger) *UserRepo {
 	}
 }
 
-// Create создает нового пользователя или обновляет существующего
+// Create создает нового пользователя или обновляет существующего и добавляет его в команду
 func (r *UserRepo) Create(exec db.Executor, user *domain.User) error {
-	if _, err := exec.Exec(queries.InsertOrUpdateUser, user.UserID, user.Username, user.TeamName, user.IsActive); err != nil {
+	if err := upsertUser(exec, user); err != nil {
 		r.logger.Errorf("SQL error: failed to create/update user %s: %v", user.UserID, err)
 		return fmt.Errorf("failed to create/update user: %w", err)
 	}
-	return nil
+	if user.TeamName == "" {
+		return nil
+	}
+	_, err := exec.Exec(queries.InsertTeamMember, user.TeamName, user.UserID, "")
+	return err
 }
 
-// GetByID получает пользователя по ID.
+// Insert создает пользователя или заново создает удаленного; существующий дает ErrUserExists
+func (r *UserRepo) Insert(exec db.Executor, user *domain.User) error {
+	res, err := exec.Exec(queries.InsertUser, user.UserID, user.Username, user.TeamName, user.IsActive)
+	if err != nil {
+		r.logger.Errorf("SQL error: failed to insert user %s: %v", user.UserID, err)
+		return err
+	}
+	if rows, err := res.RowsAffected(); err != nil || rows == 0 {
+		if err == nil {
+			err = ErrUserExists
+		}
+		return err
+	}
+
+	if err := insertEvent(exec, &domain.PREvent{Type: domain.EventUserCreated, UserID: user.UserID}); err != nil {
+		return err
+	}
+	if user.TeamName == "" {
+		return nil
+	}
+	if _, err := exec.Exec(queries.InsertTeamMember, user.TeamName, user.UserID, ""); err != nil {
+		return err
+	}
+	return insertEvent(exec, &domain.PREvent{
+		Type: domain.EventUserTeamChanged, UserID: user.UserID, Details: map[string]any{"from": "", "to": user.TeamName},
+	})
+}
+
+// Update меняет имя и основную команду пользователя: участие в прежней основной команде
+// заменяется участием в новой, смена команды попадает в историю
+func (r *UserRepo) Update(exec db.Executor, user *domain.User) error {
+	var previous string
+	var isActive bool
+	if err := exec.QueryRow(queries.SelectUserTeamForUpdate, user.UserID).Scan(&previous, &isActive); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+
+	if _, err := exec.Exec(queries.UpdateUserProfile, user.Username, user.TeamName, user.UserID); err != nil {
+		r.logger.Errorf("SQL error: failed to update user %s: %v", user.UserID, err)
+		return err
+	}
+	if previous == user.TeamName {
+		return nil
+	}
+	if _, err := exec.Exec(queries.DeleteTeamMember, previous, user.UserID); err != nil {
+		return err
+	}
+	if _, err := exec.Exec(queries.InsertTeamMember, user.TeamName, user.UserID, ""); err != nil {
+		return err
+	}
+	return insertEvent(exec, &domain.PREvent{
+		Type: domain.EventUserTeamChanged, UserID: user.UserID, Details: map[string]any{"from": previous, "to": user.TeamName},
+	})
+}
+
+// Delete помечает пользователя удаленным, выводит из всех команд и деактивирует
+func (r *UserRepo) Delete(exec db.Executor, userID string) error {
+	if _, err := exec.Exec(queries.DeleteUserMemberships, userID); err != nil {
+		return err
+	}
+	res, err := exec.Exec(queries.SoftDeleteUser, userID)
+	if err != nil {
+		r.logger.Errorf("SQL error: failed to delete user %s: %v", userID, err)
+		return err
+	}
+	if rows, err := res.RowsAffected(); err != nil || rows == 0 {
+		if err == nil {
+			err = ErrUserNotFound
+		}
+		return err
+	}
+	return insertEvent(exec, &domain.PREvent{Type: domain.EventUserDeleted, UserID: userID})
+}
+
+// GetByID получает пользователя по ID вместе со всеми его командами.
 func (r *UserRepo) GetByID(userID string) (*domain.User, error) {
 	var u domain.User
-	err := r.db.QueryRow(queries.SelectUserByID, userID).Scan(&u.UserID, &u.Username, &u.TeamName, &u.IsActive)
+	err := r.db.QueryRow(queries.SelectUserByID, userID).Scan(&u.UserID, &u.Username, &u.TeamName, &u.IsActive, &u.Deleted)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, fmt.Errorf("user not found")
@@ -45,14 +132,65 @@ func (r *UserRepo) GetByID(userID string) (*domain.User, error) {
 		r.logger.Errorf("SQL error: failed to get user %s: %v", userID, err)
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
+
+	u.Teams, err = r.listTeams(userID)
+	if err != nil {
+		return nil, err
+	}
 	return &u, nil
 }
 
-// SetIsActive обновляет статус активности пользователя.
+// listTeams возвращает команды пользователя по имени
+func (r *UserRepo) listTeams(userID string) ([]string, error) {
+	rows, err := r.db.Query(queries.SelectUserTeams, userID)
+	if err != nil {
+		r.logger.Errorf("SQL error: failed to list teams of user %s: %v", userID, err)
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			r.logger.Errorf("rows close failed: %v", err)
+		}
+	}()
+
+	teams := []string{}
+	for rows.Next() {
+		var team string
+		if err := rows.Scan(&team); err != nil {
+			return nil, err
+		}
+		teams = append(teams, team)
+	}
+	return teams, rows.Err()
+}
+
+// SetIsActive обновляет статус активности пользователя, смена флага попадает в историю.
 func (r *UserRepo) SetIsActive(userID string, isActive bool) error {
-	res, err := r.db.Exec(queries.UpdateUserIsActive, isActive, userID)
+	return RunInTx(r.db, r.logger, func(tx *sql.Tx) error {
+		var wasActive sql.NullBool
+		if err := tx.QueryRow(queries.SelectUserIsActiveForUpdate, userID).Scan(&wasActive); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("user not found")
+			}
+			return err
+		}
+
+		if _, err := tx.Exec(queries.UpdateUserIsActive, isActive, userID); err != nil {
+			r.logger.Errorf("SQL error: failed to update isActive for user %s: %v", userID, err)
+			return err
+		}
+		if wasActive.Valid && wasActive.Bool == isActive {
+			return nil
+		}
+		return insertEvent(tx, &domain.PREvent{Type: activityEvent(isActive), UserID: userID})
+	})
+}
+
+// SetMaxOpenReviews задает личный лимит открытых ревью, nil возвращает лимит команды
+func (r *UserRepo) SetMaxOpenReviews(userID string, limit *int) error {
+	res, err := r.db.Exec(queries.UpdateUserMaxOpenReviews, limit, userID)
 	if err != nil {
-		r.logger.Errorf("SQL error: failed to update isActive for user %s: %v", userID, err)
+		r.logger.Errorf("SQL error: failed to update max_open_reviews for user %s: %v", userID, err)
 		return err
 	}
 
@@ -60,14 +198,86 @@ func (r *UserRepo) SetIsActive(userID string, isActive bool) error {
 	if rows == 0 {
 		return fmt.Errorf("user not found")
 	}
-
 	return nil
 }
 
+// ListReviewLimits возвращает личные лимиты открытых ревью тех пользователей, у кого они заданы
+func (r *UserRepo) ListReviewLimits(userIDs []string) (map[string]int, error) {
+	limits := make(map[string]int, len(userIDs))
+	if len(userIDs) == 0 {
+		return limits, nil
+	}
+
+	rows, err := r.db.Query(queries.SelectReviewLimitsByUsers, pq.Array(userIDs))
+	if err != nil {
+		r.logger.Errorf("SQL error: failed to list review limits: %v", err)
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			r.logger.Errorf("rows close failed: %v", err)
+		}
+	}()
+
+	for rows.Next() {
+		var userID string
+		var limit int
+		if err := rows.Scan(&userID, &limit); err != nil {
+			return nil, err
+		}
+		limits[userID] = limit
+	}
+	return limits, rows.Err()
+}
+
 // SetIsActiveByTeam массово обновляет статус для всех пользователей команды
 func (r *UserRepo) SetIsActiveByTeam(teamName string, isActive bool) error {
-	_, err := r.db.Exec(queries.SetIsActiveStatusByTeamName, isActive, teamName)
-	return err
+	return RunInTx(r.db, r.logger, func(tx *sql.Tx) error {
+		// события пишутся до обновления, пока видно, у кого флаг меняется
+		if _, err := tx.Exec(queries.InsertTeamActivityEvents, activityEvent(isActive), teamName, isActive); err != nil {
+			return err
+		}
+		_, err := tx.Exec(queries.SetIsActiveStatusByTeamName, isActive, teamName)
+		return err
+	})
+}
+
+// upsertUser создает или обновляет пользователя; смена активности существующего
+// пользователя попадает в историю, как при /users/setIsActive
+func upsertUser(exec db.Executor, user *domain.User) error {
+	var primary string
+	var wasActive bool
+	err := exec.QueryRow(queries.SelectUserTeamForUpdate, user.UserID).Scan(&primary, &wasActive)
+	existed := err == nil
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	if _, err := exec.Exec(queries.InsertOrUpdateUser, user.UserID, user.Username, user.TeamName, user.IsActive); err != nil {
+		return err
+	}
+	if existed && wasActive != user.IsActive {
+		return insertEvent(exec, &domain.PREvent{Type: activityEvent(user.IsActive), UserID: user.UserID})
+	}
+	return nil
+}
+
+// activityEvent возвращает тип события смены активности
+func activityEvent(isActive bool) string {
+	if isActive {
+		return domain.EventUserActivated
+	}
+	return domain.EventUserDeactivated
+}
+
+// ListEvents возвращает историю пользователя: назначения, переназначения и смены активности
+func (r *UserRepo) ListEvents(userID string) ([]*domain.PREvent, error) {
+	rows, err := r.db.Query(queries.SelectPREventsByUser, userID)
+	if err != nil {
+		r.logger.Errorf("SQL error: failed to list events of user %s: %v", userID, err)
+		return nil, err
+	}
+	return scanEvents(rows, r.logger)
 }
 
 // scanUsers является вспомогательной функцией для чтения пользователей из sql.Rows.
@@ -80,7 +290,7 @@ func (r *UserRepo) scanUsers(rows *sql.Rows) ([]*domain.User, error) {
 	var users []*domain.User
 	for rows.Next() {
 		u := &domain.User{}
-		if err := rows.Scan(&u.UserID, &u.Username, &u.TeamName, &u.IsActive); err != nil {
+		if err := rows.Scan(&u.UserID, &u.Username, &u.TeamName, &u.IsActive, &u.Role); err != nil {
 			r.logger.Errorf("SQL error: failed to scan user row: %v", err)
 			return nil, err
 		}
@@ -99,7 +309,7 @@ func (r *UserRepo) ListByTeam(teamName string) ([]*domain.User, error) {
 	return r.scanUsers(rows)
 }
 
-// ListActiveByTeam возвращает всех активных пользователей команды
+// ListActiveByTeam возвращает активных пользователей команды, которые сейчас не отсутствуют
 func (r *UserRepo) ListActiveByTeam(teamName string) ([]*domain.User, error) {
 	rows, err := r.db.Query(queries.SelectActiveUsersByTeam, teamName)
 	if err != nil {
@@ -109,6 +319,20 @@ func (r *UserRepo) ListActiveByTeam(teamName string) ([]*domain.User, error) {
 	return r.scanUsers(rows)
 }
 
+// ListAvailableByIDs возвращает тех из пользователей, кто активен и не отсутствует
+func (r *UserRepo) ListAvailableByIDs(userIDs []string) ([]*domain.User, error) {
+	if len(userIDs) == 0 {
+		return []*domain.User{}, nil
+	}
+
+	rows, err := r.db.Query(queries.SelectAvailableUsersByIDs, pq.Array(userIDs))
+	if err != nil {
+		r.logger.Errorf("SQL error: failed to list available users: %v", err)
+		return nil, err
+	}
+	return r.scanUsers(rows)
+}
+
 // GetReviewPR получает список PR, где пользователь является ревьюером
 func (r *UserRepo) GetReviewPR(userID string) ([]*domain.PullRequestShort, error) {
 	rows, err := r.db.Query(queries.SelectReviewPRsByUser, userID)
@@ -134,10 +358,63 @@ func (r *UserRepo) GetReviewPR(userID string) ([]*domain.PullRequestShort, error
 
 // ListAllUsers возвращает список всех пользователей.
 func (r *UserRepo) ListAllUsers() ([]*domain.User, error) {
-	rows, err := r.db.Query(`SELECT user_id, username, team_name, is_active FROM users`)
+	rows, err := r.db.Query(`SELECT user_id, username, team_name, is_active, '' FROM users`)
 	if err != nil {
 		r.logger.Errorf("failed to list all users: %v", err)
 		return nil, err
 	}
 	return r.scanUsers(rows)
 }
+
+// SetTags атомарно заменяет теги пользователя
+func (r *UserRepo) SetTags(userID string, tags []string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			r.logger.Errorf("rollback failed: %v", err)
+		}
+	}()
+
+	if _, err := tx.Exec(queries.DeleteUserTags, userID); err != nil {
+		r.logger.Errorf("SQL error: failed to clear tags of user %s: %v", userID, err)
+		return err
+	}
+	for _, tag := range tags {
+		if _, err := tx.Exec(queries.InsertUserTag, userID, tag); err != nil {
+			r.logger.Errorf("SQL error: failed to add tag %s to user %s: %v", tag, userID, err)
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// ListTagsByUsers одним запросом возвращает теги пользователей
+func (r *UserRepo) ListTagsByUsers(userIDs []string) (map[string][]string, error) {
+	tags := make(map[string][]string, len(userIDs))
+	if len(userIDs) == 0 {
+		return tags, nil
+	}
+
+	rows, err := r.db.Query(queries.SelectTagsByUsers, pq.Array(userIDs))
+	if err != nil {
+		r.logger.Errorf("SQL error: failed to list tags of users: %v", err)
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			r.logger.Errorf("rows close failed: %v", err)
+		}
+	}()
+
+	for rows.Next() {
+		var userID, tag string
+		if err := rows.Scan(&userID, &tag); err != nil {
+			return nil, err
+		}
+		tags[userID] = append(tags[userID], tag)
+	}
+	return tags, rows.Err()
+}
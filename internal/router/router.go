@@ -11,6 +11,13 @@ func NewRouter(
 	teamH *handler.TeamHandler,
 	prH *handler.PullRequestHandler,
 	statsH *handler.StatsHandler,
+	ownershipH *handler.OwnershipHandler,
+	absenceH *handler.AbsenceHandler,
+	reviewH *handler.ReviewHandler,
+	lifecycleH *handler.UserLifecycleHandler,
+	exclusionH *handler.ExclusionHandler,
+	snapshotH *handler.SnapshotHandler,
+	githubH *handler.GitHubHandler,
 ) *gin.Engine {
 	router := gin.Default()
 
@@ -22,19 +29,71 @@ func NewRouter(
 	// пользователи
 	router.POST("/users/setIsActive", userH.SetIsActive)
 	router.GET("/users/getReview", userH.GetReviewPR)
+	router.POST("/users/setTags", userH.SetTags)
+	router.POST("/users/setCapacity", userH.SetCapacity)
+	router.GET("/users/history", userH.GetHistory)
+	router.POST("/users/absence", absenceH.CreateAbsence)
+	router.GET("/users/absence", absenceH.ListAbsences)
+	router.POST("/users/create", lifecycleH.CreateUser)
+	router.POST("/users/update", lifecycleH.UpdateUser)
+	router.POST("/users/delete", lifecycleH.DeleteUser)
 
 	// команды
 	router.POST("/team/add", teamH.CreateTeam)
 	router.GET("/team/get", teamH.GetTeam)
 	router.POST("/team/deactivate", teamH.DeactivateTeam)
+	router.GET("/team/settings", teamH.GetSettings)
+	router.POST("/team/settings", teamH.UpdateSettings)
+	router.POST("/team/members/add", teamH.AddMembers)
+	router.POST("/team/members/remove", teamH.RemoveMember)
+	router.POST("/team/members/move", teamH.MoveMember)
+	router.POST("/team/members/setRole", teamH.SetMemberRole)
+	router.POST("/team/rename", teamH.RenameTeam)
+	router.POST("/team/delete", teamH.DeleteTeam)
+
+	// массовый импорт и выгрузка команд (CSV, YAML)
+	router.POST("/import/teams", teamH.ImportTeams)
+	router.GET("/export/teams", teamH.ExportTeams)
 
 	// пулл реквесты
 	router.POST("/pullRequest/create", prH.CreatePR)
 	router.POST("/pullRequest/merge", prH.MergePR)
 	router.POST("/pullRequest/reassign", prH.ReassignReviewer)
+	router.POST("/pullRequest/review", prH.SubmitReview)
+	router.POST("/pullRequest/close", prH.ClosePR)
+	router.POST("/pullRequest/reopen", prH.ReopenPR)
+	router.POST("/pullRequest/ready", prH.ReadyPR)
+	router.POST("/pullRequest/setLabels", prH.SetLabels)
+	router.POST("/pullRequest/reviewers/add", prH.AddReviewer)
+	router.POST("/pullRequest/reviewers/remove", prH.RemoveReviewer)
+	router.GET("/pullRequest/history", prH.GetHistory)
+	router.GET("/pullRequest/list", prH.ListPRs)
+
+	// сроки ревью
+	router.GET("/reviews/overdue", reviewH.ListOverdue)
+
+	// владельцы путей (CODEOWNERS)
+	router.POST("/ownership/upload", ownershipH.Upload)
+	router.GET("/ownership/get", ownershipH.GetRules)
+
+	// правила исключения автор-ревьювер
+	router.GET("/rules/exclusions", exclusionH.ListExclusions)
+	router.POST("/rules/exclusions", exclusionH.CreateExclusion)
+	router.POST("/rules/exclusions/delete", exclusionH.DeleteExclusion)
+
+	// снимок состояния для переноса и восстановления
+	router.GET("/admin/snapshot", snapshotH.GetSnapshot)
+	router.POST("/admin/restore", snapshotH.Restore)
+
+	// вебхуки GitHub и привязка логинов GitHub к пользователям
+	router.POST("/webhooks/github", githubH.Webhook)
+	router.GET("/identities/github", githubH.ListIdentities)
+	router.POST("/identities/github", githubH.LinkIdentity)
+	router.POST("/identities/github/delete", githubH.UnlinkIdentity)
 
 	// статистика
 	router.GET("/stats", statsH.GetStats)
+	router.GET("/stats/pairs", statsH.GetPairs)
 
 	return router
 }
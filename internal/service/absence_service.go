@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/domain"
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/repository/interfaces"
+	"go.uber.org/zap"
+)
+
+var ErrInvalidAbsence = errors.New("INVALID_ABSENCE")
+
+// ReviewReassigner передает открытые ревью пользователя другим ревьюверам
+type ReviewReassigner interface {
+	ReassignReviewsOfUser(userID string) (int, error)
+}
+
+// AbsenceService - сервис периодов отсутствия пользователей
+type AbsenceService struct {
+	repo       interfaces.AbsenceRepo
+	users      interfaces.UserReader
+	reassigner ReviewReassigner
+	logger     *zap.SugaredLogger
+}
+
+// NewAbsenceService создает сервис отсутствий
+func NewAbsenceService(
+	repo interfaces.AbsenceRepo,
+	users interfaces.UserReader,
+	reassigner ReviewReassigner,
+	logger *zap.SugaredLogger,
+) *AbsenceService {
+	return &AbsenceService{
+		repo:       repo,
+		users:      users,
+		reassigner: reassigner,
+		logger:     logger,
+	}
+}
+
+// Create регистрирует отсутствие; если оно уже началось, ревью пользователя сразу переназначаются
+func (s *AbsenceService) Create(absence *domain.Absence) error {
+	if !absence.EndsAt.After(absence.StartsAt) || !absence.EndsAt.After(time.Now()) {
+		return ErrInvalidAbsence
+	}
+	if _, err := s.users.GetByID(absence.UserID); err != nil {
+		return ErrUserNotFound
+	}
+
+	if err := s.repo.Create(absence); err != nil {
+		return err
+	}
+	s.logger.Infof("absence %d of user %s registered: %s - %s", absence.ID, absence.UserID, absence.StartsAt, absence.EndsAt)
+
+	if absence.IsActiveAt(time.Now()) {
+		if err := s.reassign(absence); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListByUser возвращает текущие и будущие отсутствия пользователя
+func (s *AbsenceService) ListByUser(userID string) ([]*domain.Absence, error) {
+	if _, err := s.users.GetByID(userID); err != nil {
+		return nil, ErrUserNotFound
+	}
+	return s.repo.ListByUser(userID)
+}
+
+// ProcessStarted переназначает ревью всех начавшихся отсутствий, которые еще не обработаны;
+// отсутствие, которое не удалось обработать, остается на следующий запуск и не задерживает остальные
+func (s *AbsenceService) ProcessStarted() error {
+	absences, err := s.repo.ListStartedPending()
+	if err != nil {
+		return err
+	}
+
+	for _, absence := range absences {
+		if err := s.reassign(absence); err != nil {
+			s.logger.Warnf("failed to reassign reviews of absence %d of user %s: %v", absence.ID, absence.UserID, err)
+		}
+	}
+	return nil
+}
+
+// Run периодически вызывает ProcessStarted до отмены контекста
+func (s *AbsenceService) Run(ctx context.Context, interval time.Duration) {
+	runEvery(ctx, interval, s.logger, "processing of started absences", s.ProcessStarted)
+}
+
+// reassign передает открытые ревью отсутствующего и отмечает отсутствие обработанным
+func (s *AbsenceService) reassign(absence *domain.Absence) error {
+	count, err := s.reassigner.ReassignReviewsOfUser(absence.UserID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.MarkReassigned(absence.ID); err != nil {
+		return err
+	}
+	absence.ReviewsReassigned = true
+
+	s.logger.Infof("absence %d of user %s began, %d reviews reassigned", absence.ID, absence.UserID, count)
+	return nil
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/domain"
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/repository/interfaces"
+	"go.uber.org/zap"
+)
+
+// ошибки управления правилами исключения
+var (
+	ErrInvalidExclusion  = errors.New("INVALID_EXCLUSION")
+	ErrExclusionNotFound = errors.New("EXCLUSION_NOT_FOUND")
+)
+
+// ExcludedReviewReassigner снимает ревьювера с открытых PR автора, которые нарушают правило
+type ExcludedReviewReassigner interface {
+	ReassignExcludedReviews(reviewerID, authorID string) (int, int, error)
+}
+
+// ExclusionService - сервис правил конфликта интересов между автором и ревьювером
+type ExclusionService struct {
+	repo       interfaces.ExclusionRepo
+	users      interfaces.UserReader
+	reassigner ExcludedReviewReassigner
+	logger     *zap.SugaredLogger
+}
+
+// NewExclusionService создает сервис правил исключения
+func NewExclusionService(
+	repo interfaces.ExclusionRepo,
+	users interfaces.UserReader,
+	reassigner ExcludedReviewReassigner,
+	logger *zap.SugaredLogger,
+) *ExclusionService {
+	return &ExclusionService{
+		repo:       repo,
+		users:      users,
+		reassigner: reassigner,
+		logger:     logger,
+	}
+}
+
+// ExclusionResult - созданные правила и судьба уже назначенных ревью, которые их нарушали
+type ExclusionResult struct {
+	Exclusions        []*domain.ReviewExclusion `json:"exclusions"`
+	ReassignedReviews int                       `json:"reassigned_reviews"`
+	UnassignedReviews int                       `json:"unassigned_reviews"`
+}
+
+// Create добавляет правило reviewerID -> authorID (при mutual - и обратное) и переназначает
+// уже назначенные ревью, которые его нарушают. Ревью проверяются и по уже существующим
+// правилам: если переназначение прошлого вызова не завершилось, повтор его доделывает,
+// а в результате для уже существующего правила список exclusions пуст
+func (s *ExclusionService) Create(rule *domain.ReviewExclusion, mutual bool) (*ExclusionResult, error) {
+	rules, err := s.expand(rule, mutual)
+	if err != nil {
+		return nil, err
+	}
+	for _, userID := range []string{rule.ReviewerID, rule.AuthorID} {
+		if _, err := s.users.GetByID(userID); err != nil {
+			return nil, ErrUserNotFound
+		}
+	}
+
+	created, err := s.repo.CreateExclusions(rules)
+	if err != nil {
+		return nil, err
+	}
+	if len(created) > 0 {
+		s.logger.Infof("exclusion %s -> %s created (mutual: %v)", rule.ReviewerID, rule.AuthorID, mutual)
+	}
+
+	result := &ExclusionResult{Exclusions: created}
+	for _, r := range rules {
+		moved, removed, err := s.reassigner.ReassignExcludedReviews(r.ReviewerID, r.AuthorID)
+		if err != nil {
+			return nil, err
+		}
+		result.ReassignedReviews += moved
+		result.UnassignedReviews += removed
+	}
+
+	if len(created) == 0 && result.ReassignedReviews+result.UnassignedReviews > 0 {
+		s.logger.Infof("existing exclusion %s -> %s: %d reviews reassigned, %d unassigned",
+			rule.ReviewerID, rule.AuthorID, result.ReassignedReviews, result.UnassignedReviews)
+	}
+	return result, nil
+}
+
+// Delete удаляет правило reviewerID -> authorID (при mutual - и обратное)
+func (s *ExclusionService) Delete(reviewerID, authorID string, mutual bool) error {
+	rules, err := s.expand(&domain.ReviewExclusion{ReviewerID: reviewerID, AuthorID: authorID}, mutual)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := s.repo.DeleteExclusions(rules)
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrExclusionNotFound
+	}
+	return nil
+}
+
+// List возвращает правила, где пользователь ревьювер или автор, а при пустом userID - все правила
+func (s *ExclusionService) List(userID string) ([]*domain.ReviewExclusion, error) {
+	return s.repo.ListExclusions(userID)
+}
+
+// expand проверяет правило и добавляет обратное для взаимного исключения
+func (s *ExclusionService) expand(rule *domain.ReviewExclusion, mutual bool) ([]*domain.ReviewExclusion, error) {
+	if rule.ReviewerID == "" || rule.AuthorID == "" || rule.ReviewerID == rule.AuthorID {
+		return nil, ErrInvalidExclusion
+	}
+
+	rules := []*domain.ReviewExclusion{rule}
+	if mutual {
+		rules = append(rules, &domain.ReviewExclusion{ReviewerID: rule.AuthorID, AuthorID: rule.ReviewerID, Reason: rule.Reason})
+	}
+	return rules, nil
+}
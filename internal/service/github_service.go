@@ -0,0 +1,257 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/domain"
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/github"
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/repository"
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/repository/interfaces"
+	"go.uber.org/zap"
+)
+
+// ошибки приема вебхуков GitHub и управления привязками логинов
+var (
+	ErrWebhookDisabled    = errors.New("WEBHOOK_DISABLED")
+	ErrInvalidSignature   = errors.New("INVALID_SIGNATURE")
+	ErrInvalidPayload     = errors.New("INVALID_PAYLOAD")
+	ErrUnknownGitHubLogin = errors.New("UNKNOWN_GITHUB_LOGIN")
+	ErrInvalidIdentity    = errors.New("INVALID_IDENTITY")
+	ErrIdentityNotFound   = errors.New("IDENTITY_NOT_FOUND")
+)
+
+// итог обработки доставки вебхука
+const (
+	WebhookPong        = "pong"
+	WebhookCreated     = "created"
+	WebhookMerged      = "merged"
+	WebhookClosed      = "closed"
+	WebhookReopened    = "reopened"
+	WebhookReady       = "ready"
+	WebhookDuplicate   = "duplicate"   // повторная доставка opened для уже созданного PR
+	WebhookRedelivered = "redelivered" // доставка с этим X-GitHub-Delivery уже обработана
+	WebhookIgnored     = "ignored"
+)
+
+// PRLifecycle - операции над PR, которые выполняют события GitHub
+type PRLifecycle interface {
+	CreatePR(pr *domain.PullRequest) error
+	RecordExternalMerge(prID string) (*domain.PullRequest, error)
+	ClosePR(prID string) (*domain.PullRequest, error)
+	ReopenPR(prID string) (*domain.PullRequest, error)
+	ReadyPR(prID string) (*domain.PullRequest, error)
+}
+
+// WebhookResult - что сервис сделал с доставкой
+type WebhookResult struct {
+	Event  string              `json:"event"`
+	Action string              `json:"action,omitempty"`
+	Result string              `json:"result"`
+	PR     *domain.PullRequest `json:"-"`
+}
+
+// GitHubService принимает вебхуки GitHub и ведет PR сервиса по событиям pull_request
+type GitHubService struct {
+	identities interfaces.IdentityRepo
+	deliveries interfaces.DeliveryRepo
+	users      interfaces.UserReader
+	prs        PRLifecycle
+	secret     []byte
+	logger     *zap.SugaredLogger
+}
+
+// NewGitHubService создает сервис вебхуков, пустой secret отключает прием вебхуков
+func NewGitHubService(
+	identities interfaces.IdentityRepo,
+	deliveries interfaces.DeliveryRepo,
+	users interfaces.UserReader,
+	prs PRLifecycle,
+	secret string,
+	logger *zap.SugaredLogger,
+) *GitHubService {
+	return &GitHubService{
+		identities: identities,
+		deliveries: deliveries,
+		users:      users,
+		prs:        prs,
+		secret:     []byte(secret),
+		logger:     logger,
+	}
+}
+
+// HandleWebhook проверяет подпись доставки и применяет событие; события, кроме
+// pull_request и ping, и прочие действия pull_request пропускаются. Доставка с уже
+// принятым deliveryID не применяется повторно, неудачная доставка забывается, чтобы
+// ее повтор обработался заново
+func (s *GitHubService) HandleWebhook(event, deliveryID, signature string, body []byte) (*WebhookResult, error) {
+	if len(s.secret) == 0 {
+		return nil, ErrWebhookDisabled
+	}
+	if !github.VerifySignature(s.secret, body, signature) {
+		return nil, ErrInvalidSignature
+	}
+
+	if deliveryID == "" {
+		return s.dispatch(event, body)
+	}
+	fresh, err := s.deliveries.RecordGitHubDelivery(deliveryID, event)
+	if err != nil {
+		return nil, err
+	}
+	if !fresh {
+		s.logger.Infof("github delivery %s already processed", deliveryID)
+		return &WebhookResult{Event: event, Result: WebhookRedelivered}, nil
+	}
+
+	result, err := s.dispatch(event, body)
+	if err != nil {
+		if forgetErr := s.deliveries.DeleteGitHubDelivery(deliveryID); forgetErr != nil {
+			s.logger.Warnf("failed to forget github delivery %s: %v", deliveryID, forgetErr)
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+// dispatch применяет событие доставки
+func (s *GitHubService) dispatch(event string, body []byte) (*WebhookResult, error) {
+	switch event {
+	case github.EventPing:
+		return &WebhookResult{Event: event, Result: WebhookPong}, nil
+	case github.EventPullRequest:
+		return s.handlePullRequest(body)
+	}
+	return &WebhookResult{Event: event, Result: WebhookIgnored}, nil
+}
+
+// handlePullRequest переводит действие над PR в вызов PullRequestService
+func (s *GitHubService) handlePullRequest(body []byte) (*WebhookResult, error) {
+	var payload github.PullRequestEvent
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+	}
+	if payload.Repository.FullName == "" || (payload.Number == 0 && payload.PullRequest.Number == 0) {
+		return nil, fmt.Errorf("%w: repository.full_name and number are required", ErrInvalidPayload)
+	}
+
+	prID := payload.PRID()
+	result := &WebhookResult{Event: github.EventPullRequest, Action: payload.Action}
+
+	var pr *domain.PullRequest
+	var err error
+	switch payload.Action {
+	case github.ActionOpened:
+		return s.openPR(&payload, result)
+	case github.ActionClosed:
+		// слияние на GitHub уже состоялось, поэтому кворум сервиса не проверяется
+		if payload.PullRequest.Merged {
+			result.Result = WebhookMerged
+			pr, err = s.prs.RecordExternalMerge(prID)
+		} else {
+			result.Result = WebhookClosed
+			pr, err = s.prs.ClosePR(prID)
+		}
+	case github.ActionReopened:
+		result.Result = WebhookReopened
+		pr, err = s.prs.ReopenPR(prID)
+	case github.ActionReadyForReview:
+		result.Result = WebhookReady
+		pr, err = s.prs.ReadyPR(prID)
+	default:
+		result.Result = WebhookIgnored
+		return result, nil
+	}
+
+	// PR открыт до подключения вебхука - сервис о нем не знает
+	if errors.Is(err, ErrPRNotFound) {
+		s.logger.Infof("github %s for unknown PR %s ignored", payload.Action, prID)
+		result.Result = WebhookIgnored
+		return result, nil
+	}
+	if err != nil {
+		s.logger.Warnf("github %s for PR %s failed: %v", payload.Action, prID, err)
+		return nil, err
+	}
+
+	s.logger.Infof("github %s applied to PR %s", payload.Action, prID)
+	result.PR = pr
+	return result, nil
+}
+
+// openPR создает PR от имени пользователя, привязанного к логину автора
+func (s *GitHubService) openPR(payload *github.PullRequestEvent, result *WebhookResult) (*WebhookResult, error) {
+	login := github.NormalizeLogin(payload.PullRequest.User.Login)
+	authorID, err := s.identities.GetUserIDByGitHubLogin(login)
+	if errors.Is(err, repository.ErrIdentityNotFound) {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownGitHubLogin, login)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	pr := &domain.PullRequest{
+		PRID:       payload.PRID(),
+		PRName:     payload.PullRequest.Title,
+		AuthorID:   authorID,
+		Status:     domain.StatusOpen,
+		IsDraft:    payload.PullRequest.Draft,
+		Repository: payload.Repository.FullName,
+		Labels:     payload.LabelNames(),
+	}
+	err = s.prs.CreatePR(pr)
+	if errors.Is(err, ErrPRExists) {
+		result.Result = WebhookDuplicate
+		return result, nil
+	}
+	if err != nil {
+		s.logger.Warnf("github opened for PR %s failed: %v", pr.PRID, err)
+		return nil, err
+	}
+
+	s.logger.Infof("PR %s created from github by %s", pr.PRID, authorID)
+	result.Result = WebhookCreated
+	result.PR = pr
+	return result, nil
+}
+
+// LinkIdentity привязывает логин GitHub к пользователю сервиса
+func (s *GitHubService) LinkIdentity(login, userID string) (*domain.GitHubIdentity, error) {
+	login = github.NormalizeLogin(login)
+	if login == "" || userID == "" {
+		return nil, ErrInvalidIdentity
+	}
+	if _, err := s.users.GetByID(userID); err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	identity := &domain.GitHubIdentity{GitHubLogin: login, UserID: userID}
+	if err := s.identities.SaveGitHubIdentity(identity); err != nil {
+		return nil, err
+	}
+	s.logger.Infof("github login %s linked to %s", login, userID)
+	return identity, nil
+}
+
+// UnlinkIdentity удаляет привязку логина
+func (s *GitHubService) UnlinkIdentity(login string) error {
+	login = github.NormalizeLogin(login)
+	if login == "" {
+		return ErrInvalidIdentity
+	}
+	deleted, err := s.identities.DeleteGitHubIdentity(login)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrIdentityNotFound
+	}
+	s.logger.Infof("github login %s unlinked", login)
+	return nil
+}
+
+// ListIdentities возвращает привязки пользователя, а при пустом userID - все
+func (s *GitHubService) ListIdentities(userID string) ([]*domain.GitHubIdentity, error) {
+	return s.identities.ListGitHubIdentities(userID)
+}
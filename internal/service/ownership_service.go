@@ -0,0 +1,65 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/codeowners"
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/domain"
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/repository/interfaces"
+	"go.uber.org/zap"
+)
+
+var ErrInvalidCodeOwners = errors.New("INVALID_CODEOWNERS")
+
+// OwnershipService - сервис правил владения путями (CODEOWNERS)
+type OwnershipService struct {
+	repo   interfaces.OwnershipRepo
+	logger *zap.SugaredLogger
+}
+
+// NewOwnershipService создает сервис правил владения
+func NewOwnershipService(repo interfaces.OwnershipRepo, logger *zap.SugaredLogger) *OwnershipService {
+	return &OwnershipService{repo: repo, logger: logger}
+}
+
+// Upload разбирает CODEOWNERS файл и заменяет им правила репозитория
+func (s *OwnershipService) Upload(repository, content string) ([]*domain.CodeOwnerRule, error) {
+	rules, err := codeowners.Parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCodeOwners, err)
+	}
+
+	if err := s.repo.ReplaceRules(repository, rules); err != nil {
+		return nil, err
+	}
+
+	s.logger.Infof("uploaded %d code owner rules for repository %s", len(rules), repository)
+	return rules, nil
+}
+
+// GetRules возвращает правила репозитория
+func (s *OwnershipService) GetRules(repository string) ([]*domain.CodeOwnerRule, error) {
+	return s.repo.ListRules(repository)
+}
+
+// OwnersForPaths сопоставляет каждому пути последнее подходящее правило с владельцами
+func (s *OwnershipService) OwnersForPaths(repository string, paths []string) (map[string]*domain.CodeOwnerRule, error) {
+	owners := make(map[string]*domain.CodeOwnerRule)
+	if repository == "" || len(paths) == 0 {
+		return owners, nil
+	}
+
+	rules, err := s.repo.ListRules(repository)
+	if err != nil {
+		return nil, err
+	}
+	codeowners.Compile(rules)
+
+	for _, path := range paths {
+		if rule := codeowners.Match(rules, path); rule != nil && rule.HasOwners() {
+			owners[path] = rule
+		}
+	}
+	return owners, nil
+}
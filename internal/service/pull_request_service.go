@@ -1,7 +1,11 @@
 package service
 
 import (
+	"encoding/base64"
 	"errors"
+	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/domain"
@@ -15,27 +19,260 @@ var (
 	ErrAuthorNotFound = errors.New("AUTHOR_NOT_FOUND")
 	ErrPRExists       = errors.New("PR_EXISTS")
 	ErrPRMerged       = errors.New("PR_MERGED")
+	ErrPRClosed       = errors.New("PR_CLOSED")
+	ErrPRDraft        = errors.New("PR_DRAFT")
 	ErrNoCandidate    = errors.New("NO_CANDIDATE")
 	ErrNotAssigned    = errors.New("reviewer is not assigned to PR")
+
+	ErrNotEnoughReviewers  = errors.New("NOT_ENOUGH_REVIEWERS")
+	ErrInvalidVerdict      = errors.New("INVALID_VERDICT")
+	ErrQuorumNotMet        = errors.New("QUORUM_NOT_MET")
+	ErrChangesRequested    = errors.New("CHANGES_REQUESTED")
+	ErrReviewersAtCapacity = errors.New("REVIEWERS_AT_CAPACITY")
+
+	// ошибки переназначения на выбранного пользователя
+	ErrReviewerNotFound = errors.New("REVIEWER_NOT_FOUND")
+	ErrReviewerIsAuthor = errors.New("REVIEWER_IS_AUTHOR")
+	ErrAlreadyAssigned  = errors.New("ALREADY_ASSIGNED")
+	ErrReviewerInactive = errors.New("REVIEWER_INACTIVE")
+	ErrTeamNotAllowed   = errors.New("TEAM_NOT_ALLOWED")
+	ErrTooManyReviewers = errors.New("TOO_MANY_REVIEWERS")
+
+	ErrInvalidFilter = errors.New("INVALID_FILTER")
+
+	// автор не состоит в команде, указанной при создании PR
+	ErrAuthorNotInTeam = errors.New("AUTHOR_NOT_IN_TEAM")
+
+	// нарушение требования require_lead команды PR
+	ErrLeadUnavailable      = errors.New("LEAD_UNAVAILABLE")
+	ErrLeadApprovalRequired = errors.New("LEAD_APPROVAL_REQUIRED")
+
+	// правило исключения запрещает пользователю ревьюить автора; ErrExcludedByRules
+	// уточняет ErrNoCandidate, когда кандидатов не осталось из-за правил
+	ErrReviewerExcluded = errors.New("REVIEWER_EXCLUDED")
+	ErrExcludedByRules  = errors.New("all candidates are excluded by review exclusion rules")
+
+	errNoCandidateByRules = fmt.Errorf("%w: %w", ErrNoCandidate, ErrExcludedByRules)
+)
+
+// размер страницы списка PR
+const (
+	DefaultPRListLimit = 50
+	MaxPRListLimit     = 200
 )
 
+// TeamSettingsProvider отдает настройки назначения ревьюверов команды
+type TeamSettingsProvider interface {
+	GetSettings(teamName string) (*domain.TeamSettings, error)
+}
+
+// OwnershipProvider сопоставляет измененные пути с правилами CODEOWNERS
+type OwnershipProvider interface {
+	OwnersForPaths(repository string, paths []string) (map[string]*domain.CodeOwnerRule, error)
+}
+
 // PullRequestService сервис пулл реквестов
 type PullRequestService struct {
-	prRepo   interfaces.PullRequestRepo // репо PR
-	userRepo interfaces.UserReader      // для получения активных ревьюверов
-	logger   *zap.SugaredLogger
+	prRepo    interfaces.PullRequestRepo // репо PR
+	userRepo  interfaces.UserReader      // для получения активных ревьюверов
+	teams     TeamSettingsProvider       // настройки команд
+	owners    OwnershipProvider          // владельцы путей
+	excluded  interfaces.ExclusionReader // правила исключения пар автор-ревьювер
+	selectors *ReviewerSelectors         // стратегии выбора ревьюверов
+	rotation  *RotationMemory            // память пар автор-ревьювер
+	logger    *zap.SugaredLogger
 }
 
 // NewPullRequestService cоздает сервис пулл реквестов
-func NewPullRequestService(prRepo interfaces.PullRequestRepo, userRepo interfaces.UserReader, logger *zap.SugaredLogger) *PullRequestService {
+func NewPullRequestService(
+	prRepo interfaces.PullRequestRepo,
+	userRepo interfaces.UserReader,
+	teams TeamSettingsProvider,
+	owners OwnershipProvider,
+	excluded interfaces.ExclusionReader,
+	selectors *ReviewerSelectors,
+	logger *zap.SugaredLogger,
+) *PullRequestService {
 	return &PullRequestService{
-		prRepo:   prRepo,
-		userRepo: userRepo,
-		logger:   logger,
+		prRepo:    prRepo,
+		userRepo:  userRepo,
+		teams:     teams,
+		owners:    owners,
+		excluded:  excluded,
+		selectors: selectors,
+		rotation:  NewRotationMemory(prRepo),
+		logger:    logger,
+	}
+}
+
+// teamPolicy возвращает настройки команды и ее стратегию выбора ревьюверов, привязанную к команде
+func (s *PullRequestService) teamPolicy(teamName string) (*domain.TeamSettings, ReviewerSelector, error) {
+	settings, err := s.teams.GetSettings(teamName)
+	if err != nil {
+		return nil, nil, err
+	}
+	return settings, ForTeam(s.selectors.Get(settings.Strategy), teamName), nil
+}
+
+// pickReviewers выбирает до n ревьюверов из кандидатов команды, а при нехватке и разрешении
+// в настройках добирает их из резервных команд; вторым значением возвращает id резервных ревьюверов
+func (s *PullRequestService) pickReviewers(
+	settings *domain.TeamSettings,
+	selector ReviewerSelector,
+	candidates []*domain.User,
+	n int,
+	excluded ...string,
+) ([]*domain.User, []string, error) {
+	selected, err := selector.Select(excludeUsers(candidates, excluded...), n)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(selected) >= n || !settings.AllowCrossTeam {
+		return selected, nil, nil
+	}
+
+	fallback := []string{}
+	for _, team := range settings.FallbackTeams {
+		if len(selected) >= n {
+			break
+		}
+
+		users, err := s.userRepo.ListActiveByTeam(team)
+		if err != nil {
+			return nil, nil, err
+		}
+		users, _, err = s.filterByCapacity(users)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// не берем повторно уже выбранных
+		skip := append([]string{}, excluded...)
+		for _, u := range selected {
+			skip = append(skip, u.UserID)
+		}
+
+		// круг round-robin резервной команды ведется отдельно от круга команды PR
+		extra, err := ForTeam(selector, team).Select(excludeUsers(users, skip...), n-len(selected))
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, u := range extra {
+			selected = append(selected, u)
+			fallback = append(fallback, u.UserID)
+		}
+	}
+
+	if len(fallback) > 0 {
+		s.logger.Infof("borrowed reviewers %v from fallback teams of %s", fallback, settings.TeamName)
+	}
+	return selected, fallback, nil
+}
+
+// filterByCapacity убирает кандидатов, у которых открытых ревью не меньше личного лимита
+// или лимита их команды; вторым значением возвращает число отсеянных
+func (s *PullRequestService) filterByCapacity(candidates []*domain.User) ([]*domain.User, int, error) {
+	return s.filterByCapacityWith(candidates, nil)
+}
+
+// filterByCapacityWith как filterByCapacity, но добавляет к загрузке pending - ревью, которые
+// вызывающий уже решил передать кандидатам, но еще не записал
+func (s *PullRequestService) filterByCapacityWith(candidates []*domain.User, pending map[string]int) ([]*domain.User, int, error) {
+	if len(candidates) == 0 {
+		return candidates, 0, nil
+	}
+
+	limits, err := s.reviewLimits(candidates)
+	if err != nil {
+		return nil, 0, err
+	}
+	ids := make([]string, 0, len(candidates))
+	for _, u := range candidates {
+		ids = append(ids, u.UserID)
+	}
+	loads, err := s.prRepo.CountOpenReviews(ids)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	available := make([]*domain.User, 0, len(candidates))
+	for _, u := range candidates {
+		if limit := limits[u.UserID]; limit > 0 && loads[u.UserID]+pending[u.UserID] >= limit {
+			continue
+		}
+		available = append(available, u)
+	}
+	return available, len(candidates) - len(available), nil
+}
+
+// reviewLimits возвращает лимит открытых ревью каждого пользователя: личный, а без него -
+// лимит его команды; 0 - без лимита
+func (s *PullRequestService) reviewLimits(users []*domain.User) (map[string]int, error) {
+	ids := make([]string, 0, len(users))
+	for _, u := range users {
+		ids = append(ids, u.UserID)
+	}
+	personal, err := s.userRepo.ListReviewLimits(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	teamLimits := make(map[string]int)
+	limits := make(map[string]int, len(users))
+	for _, u := range users {
+		limit, ok := personal[u.UserID]
+		if !ok {
+			// личного лимита нет - берем лимит команды пользователя
+			teamLimit, cached := teamLimits[u.TeamName]
+			if !cached {
+				settings, err := s.teams.GetSettings(u.TeamName)
+				if err != nil {
+					return nil, err
+				}
+				teamLimit = settings.MaxOpenReviews
+				teamLimits[u.TeamName] = teamLimit
+			}
+			limit = teamLimit
+		}
+		limits[u.UserID] = limit
+	}
+	return limits, nil
+}
+
+// excludeUsers возвращает кандидатов без указанных пользователей
+func excludeUsers(users []*domain.User, excluded ...string) []*domain.User {
+	skip := make(map[string]struct{}, len(excluded))
+	for _, id := range excluded {
+		skip[id] = struct{}{}
+	}
+
+	candidates := make([]*domain.User, 0, len(users))
+	for _, u := range users {
+		if _, ok := skip[u.UserID]; !ok {
+			candidates = append(candidates, u)
+		}
+	}
+	return candidates
+}
+
+// leadsOf возвращает лидов среди пользователей, роли которых прочитаны в контексте команды PR
+func leadsOf(users []*domain.User) []*domain.User {
+	leads := []*domain.User{}
+	for _, u := range users {
+		if u.Role == domain.RoleLead {
+			leads = append(leads, u)
+		}
 	}
+	return leads
+}
+
+// containsUser проверяет, есть ли пользователь с таким id в списке
+func containsUser(users []*domain.User, userID string) bool {
+	return slices.ContainsFunc(users, func(u *domain.User) bool { return u.UserID == userID })
 }
 
-// CreatePR cоздание PR с назначением до 2 ревьюверов
+// CreatePR cоздание PR с назначением ревьюверов по настройкам выбранной команды автора
+// (по умолчанию основной), черновик создается без ревьюверов
 func (s *PullRequestService) CreatePR(pr *domain.PullRequest) error {
 	existing, err := s.prRepo.GetPRByID(pr.PRID)
 	if err != nil {
@@ -51,150 +288,1379 @@ func (s *PullRequestService) CreatePR(pr *domain.PullRequest) error {
 	if err != nil {
 		return ErrAuthorNotFound
 	}
+	if pr.TeamName == "" {
+		pr.TeamName = author.TeamName
+	} else if !slices.Contains(author.Teams, pr.TeamName) {
+		return ErrAuthorNotInTeam
+	}
+
+	pr.Labels = domain.NormalizeTags(pr.Labels)
+
+	// для черновика ревьюверы назначаются в ReadyPR
+	var selected []*domain.User
+	var fallback []string
+	if !pr.IsDraft {
+		selected, fallback, err = s.selectInitialReviewers(pr)
+		if err != nil {
+			return err
+		}
+	}
+
+	// создаем PR вместе с ревьюверами в одной транзакции
+	pr.Status = domain.StatusOpen
+	pr.AssignReviewers = selected
+	if pr.AssignReviewers == nil {
+		pr.AssignReviewers = []*domain.User{}
+	}
+	pr.FallbackReviewers = fallback
+	return s.prRepo.CreatePR(pr)
+}
 
-	// получаем активных участников
-	users, err := s.userRepo.ListActiveByTeam(author.TeamName)
+// SetLabels заменяет метки открытого PR (в том числе черновика), уже назначенные ревьюверы не меняются
+func (s *PullRequestService) SetLabels(prID string, labels []string) (*domain.PullRequest, error) {
+	pr, err := s.getPR(prID)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	switch pr.Status {
+	case domain.StatusMerged:
+		return nil, ErrPRMerged
+	case domain.StatusClosed:
+		return nil, ErrPRClosed
 	}
 
-	// исключаем автора
-	var reviewers []string
-	for _, u := range users {
-		if u.UserID != pr.AuthorID && len(reviewers) < 2 {
-			reviewers = append(reviewers, u.UserID)
+	labels = domain.NormalizeTags(labels)
+	if err := s.prRepo.SetLabels(prID, labels); err != nil {
+		if errors.Is(err, repository.ErrPRStateChanged) {
+			// PR слили или закрыли после чтения
+			return nil, s.stateError(prID, err)
 		}
+		return nil, err
 	}
+	pr.Labels = labels
 
-	// создаем PR
-	pr.Status = "OPEN"
-	if err := s.prRepo.CreatePR(pr); err != nil {
-		return err
+	s.logger.Infof("PR %s labels updated to %v", prID, labels)
+	return pr, nil
+}
+
+// ListPRs возвращает страницу PR по фильтру и курсор следующей страницы (пустой, если она последняя)
+func (s *PullRequestService) ListPRs(filter *domain.PRFilter, cursor string) ([]*domain.PullRequest, string, error) {
+	switch filter.Status {
+	case "", domain.StatusOpen, domain.StatusMerged, domain.StatusClosed:
+	default:
+		return nil, "", ErrInvalidFilter
+	}
+	if filter.Limit < 0 || filter.Limit > MaxPRListLimit {
+		return nil, "", ErrInvalidFilter
+	}
+	if filter.Limit == 0 {
+		filter.Limit = DefaultPRListLimit
 	}
 
-	// назначаем ревьюверов
-	if len(reviewers) > 0 {
-		if err := s.prRepo.AssignReviewers(pr.PRID, reviewers); err != nil {
-			return err
+	if cursor != "" {
+		after, err := decodePRCursor(cursor)
+		if err != nil {
+			return nil, "", ErrInvalidFilter
 		}
+		filter.After = after
+	}
 
-		// заполняем поле AssignReviewers для ответа
-		pr.AssignReviewers = []*domain.User{}
-		for _, u := range users {
-			for _, id := range reviewers {
-				if u.UserID == id {
-					pr.AssignReviewers = append(pr.AssignReviewers, u)
-					break
-				}
-			}
-		}
-	} else {
-		pr.AssignReviewers = []*domain.User{}
+	// берем на одну запись больше, чтобы понять, есть ли следующая страница
+	limit := filter.Limit
+	filter.Limit++
+	prs, err := s.prRepo.ListPRs(filter)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(prs) <= limit {
+		return prs, "", nil
 	}
 
-	return nil
+	prs = prs[:limit]
+	last := prs[limit-1]
+	return prs, encodePRCursor(&domain.PRCursor{CreatedAt: last.CreatedAt, PRID: last.PRID}), nil
 }
 
-// MergePR производит слияние пулл реквестов
-func (s *PullRequestService) MergePR(prID string) (*domain.PullRequest, error) {
-	pr, err := s.prRepo.GetPRByID(prID)
+// encodePRCursor упаковывает позицию в списке в непрозрачную строку
+func encodePRCursor(c *domain.PRCursor) string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + c.PRID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodePRCursor разбирает курсор, выданный encodePRCursor
+func decodePRCursor(cursor string) (*domain.PRCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, err
+	}
+	createdAt, prID, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return nil, errors.New("malformed cursor")
+	}
+	t, err := time.Parse(time.RFC3339Nano, createdAt)
 	if err != nil {
 		return nil, err
 	}
+	return &domain.PRCursor{CreatedAt: t, PRID: prID}, nil
+}
+
+// GetHistory возвращает историю PR: назначения, переназначения, ревью и смены статуса
+func (s *PullRequestService) GetHistory(prID string) ([]*domain.PREvent, error) {
+	if _, err := s.getPR(prID); err != nil {
+		return nil, err
+	}
+	return s.prRepo.ListEvents(prID)
+}
 
-	if pr.Status == "MERGED" {
+// ReadyPR переводит черновик в готовый к ревью PR и назначает ревьюверов одной транзакцией,
+// для уже готового PR ничего не делает
+func (s *PullRequestService) ReadyPR(prID string) (*domain.PullRequest, error) {
+	pr, err := s.getPR(prID)
+	if err != nil {
+		return nil, err
+	}
+	ready, err := needsReady(pr)
+	if err != nil {
+		return nil, err
+	}
+	if !ready {
 		return pr, nil
 	}
 
-	now := time.Now()
-	if err := s.prRepo.MergePR(prID, now); err != nil {
+	selected, fallback, err := s.selectInitialReviewers(pr)
+	if err != nil {
 		return nil, err
 	}
 
-	pr.Status = "MERGED"
-	pr.MergedAt = &now
+	reviewers := make([]string, 0, len(selected))
+	for _, u := range selected {
+		reviewers = append(reviewers, u.UserID)
+	}
+	if err := s.prRepo.MarkPRReady(prID, reviewers); err != nil {
+		if !errors.Is(err, repository.ErrPRStateChanged) {
+			return nil, err
+		}
+
+		// PR параллельно перевели в ready (вместе с ревьюверами), закрыли или слили
+		current, err := s.getPR(prID)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := needsReady(current); err != nil {
+			return nil, err
+		}
+		return current, nil
+	}
+
+	pr.IsDraft = false
+	pr.AssignReviewers = selected
+	pr.FallbackReviewers = fallback
 	return pr, nil
 }
 
-// ReassignReviewer заменяет ревьювера на активного из его команды
-func (s *PullRequestService) ReassignReviewer(prID, oldReviewerID string) (*domain.PullRequest, string, error) {
-	pr, err := s.prRepo.GetPRByID(prID)
+// needsReady проверяет, что PR - черновик, который можно перевести в ready;
+// слитый или закрытый черновик дает ошибку
+func needsReady(pr *domain.PullRequest) (bool, error) {
+	if !pr.IsDraft {
+		return false, nil
+	}
+	switch pr.Status {
+	case domain.StatusMerged:
+		return false, ErrPRMerged
+	case domain.StatusClosed:
+		return false, ErrPRClosed
+	}
+	return true, nil
+}
+
+// selectInitialReviewers выбирает ревьюверов нового PR из его команды
+func (s *PullRequestService) selectInitialReviewers(pr *domain.PullRequest) ([]*domain.User, []string, error) {
+	blocked, err := s.excluded.ListExcludedReviewers(pr.AuthorID)
 	if err != nil {
-		return nil, "", err
+		return nil, nil, err
 	}
 
-	if pr.Status == "MERGED" {
-		return nil, "", ErrPRMerged
+	// получаем активных участников, у которых есть место под ревью и которым правила не запрещают ревью
+	users, err := s.userRepo.ListActiveByTeam(pr.TeamName)
+	if err != nil {
+		return nil, nil, err
+	}
+	users = excludeUsers(users, pr.AuthorID)
+	allowed := excludeUsers(users, blocked...)
+	ruledOut := len(users) - len(allowed)
+	users, atCapacity, err := s.filterByCapacity(allowed)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	// получаем активных участников
-	oldUser, err := s.userRepo.GetByID(oldReviewerID)
+	settings, selector, err := s.teamPolicy(pr.TeamName)
 	if err != nil {
-		return nil, "", ErrNotAssigned
+		return nil, nil, err
 	}
+	selector = s.rotation.ForAuthor(selector, pr.AuthorID, settings.RotationWindowDays)
 
-	users, err := s.userRepo.ListActiveByTeam(oldUser.TeamName)
+	// сначала владельцы измененных путей, они могут превысить reviewers_count
+	owners, err := s.pickOwners(pr, selector, blocked)
 	if err != nil {
-		return nil, "", err
+		return nil, nil, err
+	}
+	excluded := append([]string{pr.AuthorID}, blocked...)
+	for _, u := range owners {
+		excluded = append(excluded, u.UserID)
 	}
 
-	var newReviewerID string
-	for _, u := range users {
-		if u.UserID != oldReviewerID {
-			newReviewerID = u.UserID
-			break
+	// при require_lead лид команды PR занимает одно из мест, если его нет среди владельцев
+	if settings.RequireLead {
+		leads := leadsOf(users)
+		if !slices.ContainsFunc(owners, func(u *domain.User) bool { return containsUser(leads, u.UserID) }) {
+			lead, err := selector.Select(excludeUsers(leads, excluded...), 1)
+			if err != nil {
+				return nil, nil, err
+			}
+			if len(lead) == 0 {
+				return nil, nil, ErrLeadUnavailable
+			}
+			owners = append(owners, lead...)
+			excluded = append(excluded, lead[0].UserID)
 		}
 	}
-	if newReviewerID == "" {
-		return nil, "", errors.New("NO_CANDIDATE")
+
+	// остальные места добираем из команды PR, сначала среди подходящих по тегам
+	n := max(settings.ReviewersCount-len(owners), 0)
+	matched, err := s.pickByLabels(selector, users, pr.Labels, n, excluded...)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, u := range matched {
+		excluded = append(excluded, u.UserID)
 	}
 
-	// обновляем запись
-	if err := s.prRepo.UpdateReviewer(prID, oldReviewerID, newReviewerID); err != nil {
-		return nil, "", err
+	selected, fallback, err := s.pickReviewers(settings, selector, users, n-len(matched), excluded...)
+	if err != nil {
+		return nil, nil, err
 	}
+	selected = append(append(owners, matched...), selected...)
 
-	return pr, newReviewerID, nil
+	// правила исключения оставили команду без нужного числа ревьюверов
+	if ruledOut > 0 && atCapacity == 0 &&
+		(len(selected) < settings.MinReviewers || (len(selected) == 0 && settings.ReviewersCount > 0)) {
+		return nil, nil, errNoCandidateByRules
+	}
+	if len(selected) < settings.MinReviewers {
+		if atCapacity > 0 {
+			return nil, nil, ErrReviewersAtCapacity
+		}
+		return nil, nil, ErrNotEnoughReviewers
+	}
+	if atCapacity > 0 && len(selected) < settings.ReviewersCount {
+		pr.Warnings = append(pr.Warnings, domain.WarningReviewersAtCapacity)
+	}
+	return selected, fallback, nil
 }
 
-// ReassignReviewersForTeam безопасно переназначает ревьюверов для всех открытых PR команды
-func (s *PullRequestService) ReassignReviewersForTeam(teamName string) error {
-	prs, err := s.prRepo.ListOpenPRsByTeam(teamName)
+// pickByLabels выбирает до n ревьюверов среди кандидатов, чьи теги пересекаются с метками PR
+func (s *PullRequestService) pickByLabels(
+	selector ReviewerSelector,
+	candidates []*domain.User,
+	labels []string,
+	n int,
+	excluded ...string,
+) ([]*domain.User, error) {
+	candidates = excludeUsers(candidates, excluded...)
+	if len(labels) == 0 || len(candidates) == 0 || n <= 0 {
+		return []*domain.User{}, nil
+	}
+
+	ids := make([]string, 0, len(candidates))
+	for _, u := range candidates {
+		ids = append(ids, u.UserID)
+	}
+	tags, err := s.userRepo.ListTagsByUsers(ids)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	activeUsers, err := s.userRepo.ListActiveByTeam(teamName)
+	matching := []*domain.User{}
+	for _, u := range candidates {
+		if domain.HasAnyTag(tags[u.UserID], labels) {
+			matching = append(matching, u)
+		}
+	}
+	if len(matching) == 0 {
+		return matching, nil
+	}
+	return selector.Select(matching, n)
+}
+
+// pickOwners выбирает по одному активному владельцу для каждого измененного пути,
+// которому еще не назначен владелец; пути без доступных владельцев закрывает команда автора
+func (s *PullRequestService) pickOwners(pr *domain.PullRequest, selector ReviewerSelector, blocked []string) ([]*domain.User, error) {
+	rules, err := s.owners.OwnersForPaths(pr.Repository, pr.ChangedFiles)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	activeMap := make(map[string]*domain.User)
-	for _, u := range activeUsers {
-		activeMap[u.UserID] = u
+	selected := []*domain.User{}
+	chosen := make(map[string]bool)
+	for _, path := range pr.ChangedFiles {
+		rule, ok := rules[path]
+		if !ok {
+			continue
+		}
+
+		candidates, err := s.ownerCandidates(rule, pr.AuthorID, blocked)
+		if err != nil {
+			return nil, err
+		}
+
+		// путь уже покрыт одним из выбранных владельцев
+		covered := false
+		for _, u := range candidates {
+			if chosen[u.UserID] {
+				covered = true
+				break
+			}
+		}
+		if covered || len(candidates) == 0 {
+			continue
+		}
+
+		picked, err := selector.Select(candidates, 1)
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range picked {
+			chosen[u.UserID] = true
+			selected = append(selected, u)
+		}
 	}
+	return selected, nil
+}
 
-	for _, pr := range prs {
-		for _, oldReviewer := range pr.AssignReviewers {
-			if !oldReviewer.IsActive {
-				var newUserID string
-				for _, u := range activeUsers {
-					if u.UserID != pr.AuthorID && u.UserID != oldReviewer.UserID {
-						newUserID = u.UserID
-						break
-					}
-				}
+// ownerCandidates раскрывает владельцев правила в доступных пользователей кроме автора
+// и исключенных правилами, у которых есть место под ревью
+func (s *PullRequestService) ownerCandidates(rule *domain.CodeOwnerRule, authorID string, blocked []string) ([]*domain.User, error) {
+	candidates := []*domain.User{}
+	seen := map[string]bool{authorID: true}
+	for _, id := range blocked {
+		seen[id] = true
+	}
 
-				if newUserID != "" {
-					if err := s.prRepo.UpdateReviewer(pr.PRID, oldReviewer.UserID, newUserID); err != nil {
-						return err
-					}
-				}
+	// владельцы из файла могут отсутствовать в сервисе или быть в отпуске
+	users, err := s.userRepo.ListAvailableByIDs(rule.Users)
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range users {
+		if !seen[u.UserID] {
+			seen[u.UserID] = true
+			candidates = append(candidates, u)
+		}
+	}
+
+	for _, team := range rule.Teams {
+		users, err := s.userRepo.ListActiveByTeam(team)
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range users {
+			if !seen[u.UserID] {
+				seen[u.UserID] = true
+				candidates = append(candidates, u)
 			}
 		}
 	}
 
-	return nil
+	// владельцы на пределе загрузки не назначаются, путь закрывает команда автора
+	candidates, _, err = s.filterByCapacity(candidates)
+	return candidates, err
+}
+
+// MergePR производит слияние пулл реквестов, если набран кворум одобрений команды PR
+// и нет неснятых CHANGES_REQUESTED; статус и вердикты проверяются под блокировкой PR
+func (s *PullRequestService) MergePR(prID string) (*domain.PullRequest, error) {
+	pr, err := s.getPR(prID)
+	if err != nil {
+		return nil, err
+	}
+
+	if pr.Status == domain.StatusMerged {
+		return pr, nil
+	}
+	if err := ensureOpen(pr); err != nil {
+		return nil, err
+	}
+
+	settings, err := s.teams.GetSettings(pr.TeamName)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	err = s.prRepo.MergePR(prID, now, func(status string, reviews []*domain.Review) error {
+		// PR могли слить или закрыть после чтения
+		switch status {
+		case domain.StatusMerged:
+			return ErrPRMerged
+		case domain.StatusClosed:
+			return ErrPRClosed
+		}
+		return checkQuorum(settings, pr, reviews)
+	})
+	if err != nil {
+		return s.mergeResult(prID, err)
+	}
+
+	pr.Status = domain.StatusMerged
+	pr.MergedAt = &now
+	return pr, nil
+}
+
+// RecordExternalMerge отмечает PR слитым, когда слияние уже произошло во внешней системе (GitHub):
+// кворум, CHANGES_REQUESTED и одобрение лида не проверяются, повторная отметка идемпотентна
+func (s *PullRequestService) RecordExternalMerge(prID string) (*domain.PullRequest, error) {
+	pr, err := s.getPR(prID)
+	if err != nil {
+		return nil, err
+	}
+	if pr.Status == domain.StatusMerged {
+		return pr, nil
+	}
+
+	now := time.Now()
+	err = s.prRepo.MergePR(prID, now, func(status string, _ []*domain.Review) error {
+		if status == domain.StatusMerged {
+			return ErrPRMerged
+		}
+		return nil
+	})
+	if err != nil {
+		return s.mergeResult(prID, err)
+	}
+	s.logger.Infof("PR %s merged externally", prID)
+
+	pr.Status = domain.StatusMerged
+	pr.MergedAt = &now
+	return pr, nil
+}
+
+// mergeResult переводит ошибку слияния в результат: если PR успели слить параллельно,
+// повторный merge идемпотентен и возвращает слитый PR
+func (s *PullRequestService) mergeResult(prID string, err error) (*domain.PullRequest, error) {
+	switch {
+	case errors.Is(err, ErrPRMerged):
+		return s.getPR(prID)
+	case errors.Is(err, repository.ErrPRNotFound):
+		return nil, ErrPRNotFound
+	}
+	return nil, err
+}
+
+// ClosePR закрывает открытый PR без слияния, повторное закрытие идемпотентно
+func (s *PullRequestService) ClosePR(prID string) (*domain.PullRequest, error) {
+	pr, err := s.getPR(prID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch pr.Status {
+	case domain.StatusClosed:
+		return pr, nil
+	case domain.StatusMerged:
+		return nil, ErrPRMerged
+	}
+
+	now := time.Now()
+	if err := s.prRepo.ClosePR(prID, now); err != nil {
+		if errors.Is(err, repository.ErrPRStateChanged) {
+			return s.settledStatus(prID, domain.StatusClosed)
+		}
+		return nil, err
+	}
+
+	pr.Status = domain.StatusClosed
+	pr.ClosedAt = &now
+	return pr, nil
+}
+
+// ReopenPR возвращает закрытый PR в OPEN, для открытого PR ничего не делает
+func (s *PullRequestService) ReopenPR(prID string) (*domain.PullRequest, error) {
+	pr, err := s.getPR(prID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch pr.Status {
+	case domain.StatusOpen:
+		return pr, nil
+	case domain.StatusMerged:
+		return nil, ErrPRMerged
+	}
+
+	if err := s.prRepo.ReopenPR(prID); err != nil {
+		if errors.Is(err, repository.ErrPRStateChanged) {
+			return s.settledStatus(prID, domain.StatusOpen)
+		}
+		return nil, err
+	}
+
+	pr.Status = domain.StatusOpen
+	pr.ClosedAt = nil
+
+	// передачи ревью закрытые PR не затрагивают, поэтому ревьюверы могли устареть
+	if err := s.restaffReopened(pr); err != nil {
+		s.logger.Errorf("failed to restaff reopened PR %s: %v", prID, err)
+	}
+	return pr, nil
+}
+
+// restaffReopened заменяет в переоткрытом PR ревьюверов, которые, пока он был закрыт, стали
+// недоступны (неактивны, удалены, отсутствуют) или попали под правило исключения. Замена ищется
+// в команде PR, как при переназначении ревьюверов команды; без замены недоступный ревьювер
+// остается, а исключенный снимается
+func (s *PullRequestService) restaffReopened(pr *domain.PullRequest) error {
+	if pr.TeamName == "" || len(pr.AssignReviewers) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(pr.AssignReviewers))
+	for _, u := range pr.AssignReviewers {
+		ids = append(ids, u.UserID)
+	}
+	available, err := s.userRepo.ListAvailableByIDs(ids)
+	if err != nil {
+		return err
+	}
+	blocked, err := s.excluded.ListExcludedReviewers(pr.AuthorID)
+	if err != nil {
+		return err
+	}
+
+	for _, oldReviewerID := range ids {
+		isBlocked := slices.Contains(blocked, oldReviewerID)
+		if containsUser(available, oldReviewerID) && !isBlocked {
+			continue
+		}
+
+		selected, err := s.pickReplacement(pr, pr.TeamName, oldReviewerID, nil)
+		switch {
+		case err == nil:
+			if err := s.replaceReviewer(pr, oldReviewerID, selected); err != nil {
+				return err
+			}
+		case errors.Is(err, ErrNoCandidate) || errors.Is(err, ErrReviewersAtCapacity):
+			s.logger.Warnf("no replacement for %s on reopened PR %s: %v", oldReviewerID, pr.PRID, err)
+			if !isBlocked {
+				continue
+			}
+			if err := s.ensureLeadKept(pr, oldReviewerID); err != nil {
+				if errors.Is(err, ErrLeadUnavailable) {
+					continue
+				}
+				return err
+			}
+			if err := s.prRepo.RemoveReviewer(pr.PRID, oldReviewerID); err != nil {
+				return err
+			}
+			pr.AssignReviewers = excludeUsers(pr.AssignReviewers, oldReviewerID)
+		default:
+			return err
+		}
+	}
+	return nil
+}
+
+// settledStatus перечитывает PR, статус которого сменился параллельно со сменой на target:
+// если PR уже в статусе target, смена идемпотентна, иначе возвращается ошибка его статуса
+func (s *PullRequestService) settledStatus(prID, target string) (*domain.PullRequest, error) {
+	pr, err := s.getPR(prID)
+	if err != nil {
+		return nil, err
+	}
+	switch pr.Status {
+	case target:
+		return pr, nil
+	case domain.StatusMerged:
+		return nil, ErrPRMerged
+	}
+	return nil, ErrPRClosed
+}
+
+// getPR получает PR и переводит ошибку репозитория в ошибку сервиса
+func (s *PullRequestService) getPR(prID string) (*domain.PullRequest, error) {
+	pr, err := s.prRepo.GetPRByID(prID)
+	if err != nil {
+		if errors.Is(err, repository.ErrPRNotFound) {
+			return nil, ErrPRNotFound
+		}
+		return nil, err
+	}
+	return pr, nil
+}
+
+// stateError перечитывает PR, состояние которого сменилось параллельно, и возвращает ошибку
+// по его текущему состоянию; если PR снова открыт, возвращает err
+func (s *PullRequestService) stateError(prID string, err error) error {
+	pr, getErr := s.getPR(prID)
+	if getErr != nil {
+		return getErr
+	}
+	if openErr := ensureOpen(pr); openErr != nil {
+		return openErr
+	}
+	return err
+}
+
+// ensureOpen проверяет, что PR открыт и уже не черновик
+func ensureOpen(pr *domain.PullRequest) error {
+	switch pr.Status {
+	case domain.StatusMerged:
+		return ErrPRMerged
+	case domain.StatusClosed:
+		return ErrPRClosed
+	}
+	if pr.IsDraft {
+		return ErrPRDraft
+	}
+	return nil
+}
+
+// checkQuorum проверяет вердикты ревьюверов перед merge по настройкам команды PR,
+// при require_lead среди одобривших должен быть лид
+func checkQuorum(settings *domain.TeamSettings, pr *domain.PullRequest, reviews []*domain.Review) error {
+	leads := leadsOf(pr.AssignReviewers)
+	approvals, leadApproved := 0, false
+	for _, review := range reviews {
+		switch review.Verdict {
+		case domain.VerdictChangesRequested:
+			return ErrChangesRequested
+		case domain.VerdictApproved:
+			approvals++
+			leadApproved = leadApproved || containsUser(leads, review.ReviewerID)
+		}
+	}
+	if approvals < settings.ApprovalsRequired {
+		return ErrQuorumNotMet
+	}
+	if settings.RequireLead && !leadApproved {
+		return ErrLeadApprovalRequired
+	}
+	return nil
+}
+
+// SubmitReview сохраняет вердикт назначенного ревьювера, повторный вызов перезаписывает вердикт
+func (s *PullRequestService) SubmitReview(prID, reviewerID, verdict string) (*domain.Review, error) {
+	if !domain.IsValidVerdict(verdict) {
+		return nil, ErrInvalidVerdict
+	}
+
+	pr, err := s.getPR(prID)
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureOpen(pr); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := s.prRepo.SubmitReview(prID, reviewerID, verdict, now); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrReviewerNotAssigned):
+			return nil, ErrNotAssigned
+		case errors.Is(err, repository.ErrPRStateChanged):
+			// PR слили или закрыли после чтения
+			return nil, s.stateError(prID, err)
+		}
+		return nil, err
+	}
+
+	s.logger.Infof("reviewer %s submitted %s on PR %s", reviewerID, verdict, prID)
+	return &domain.Review{
+		PRID:       prID,
+		ReviewerID: reviewerID,
+		Verdict:    verdict,
+		ReviewedAt: &now,
+	}, nil
+}
+
+// ReassignReviewer заменяет ревьювера автоматически выбранным участником его команды,
+// кроме автора и уже назначенных ревьюверов
+func (s *PullRequestService) ReassignReviewer(prID, oldReviewerID string) (*domain.PullRequest, string, error) {
+	pr, _, err := s.prepareReassign(prID, oldReviewerID)
+	if err != nil {
+		return nil, "", err
+	}
+	team, err := s.reviewerTeam(pr, oldReviewerID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	selected, err := s.pickReplacement(pr, team, oldReviewerID, nil)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// обновляем запись
+	if err := s.replaceReviewer(pr, oldReviewerID, selected); err != nil {
+		return nil, "", err
+	}
+	return pr, selected.UserID, nil
+}
+
+// pickReplacement выбирает замену ревьюверу среди активных участников команды с местом под ревью,
+// кроме автора и текущих ревьюверов; если без него в PR не остается лида, а команда PR
+// требует его, предпочитается лид; pending - еще не записанные передачи, учитываемые в загрузке
+func (s *PullRequestService) pickReplacement(pr *domain.PullRequest, teamName, oldReviewerID string, pending map[string]int) (*domain.User, error) {
+	excluded := []string{pr.AuthorID}
+	for _, u := range pr.AssignReviewers {
+		excluded = append(excluded, u.UserID)
+	}
+	blocked, err := s.excluded.ListExcludedReviewers(pr.AuthorID)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := s.userRepo.ListActiveByTeam(teamName)
+	if err != nil {
+		return nil, err
+	}
+	users = excludeUsers(users, excluded...)
+	excluded = append(excluded, blocked...)
+	allowed := excludeUsers(users, blocked...)
+	ruledOut := len(users) - len(allowed)
+	users, atCapacity, err := s.filterByCapacityWith(allowed, pending)
+	if err != nil {
+		return nil, err
+	}
+
+	settings, selector, err := s.teamPolicy(teamName)
+	if err != nil {
+		return nil, err
+	}
+	selector = s.rotation.ForAuthor(selector, pr.AuthorID, settings.RotationWindowDays)
+
+	if teamName == pr.TeamName && s.needsLead(settings, pr, oldReviewerID) {
+		lead, err := selector.Select(leadsOf(users), 1)
+		if err != nil {
+			return nil, err
+		}
+		if len(lead) > 0 {
+			return lead[0], nil
+		}
+	}
+
+	selected, fallback, err := s.pickReviewers(settings, selector, users, 1, excluded...)
+	if err != nil {
+		return nil, err
+	}
+	if len(selected) == 0 {
+		if atCapacity > 0 {
+			return nil, ErrReviewersAtCapacity
+		}
+		if ruledOut > 0 {
+			return nil, errNoCandidateByRules
+		}
+		return nil, ErrNoCandidate
+	}
+	pr.FallbackReviewers = fallback
+	return selected[0], nil
+}
+
+// needsLead проверяет, что после снятия ревьювера в PR не останется лида, которого требует команда
+func (s *PullRequestService) needsLead(settings *domain.TeamSettings, pr *domain.PullRequest, oldReviewerID string) bool {
+	if !settings.RequireLead || len(leadsOf(excludeUsers(pr.AssignReviewers, oldReviewerID))) > 0 {
+		return false
+	}
+	s.logger.Infof("replacement for %s on PR %s must be a lead of team %s", oldReviewerID, pr.PRID, pr.TeamName)
+	return true
+}
+
+// reviewerTeam возвращает команду, из которой ищется замена ревьюверу: команду PR,
+// если ревьювер в ней состоит, иначе его основную команду
+func (s *PullRequestService) reviewerTeam(pr *domain.PullRequest, reviewerID string) (string, error) {
+	reviewer, err := s.userRepo.GetByID(reviewerID)
+	if err != nil {
+		return "", ErrReviewerNotFound
+	}
+	if slices.Contains(reviewer.Teams, pr.TeamName) {
+		return pr.TeamName, nil
+	}
+	return reviewer.TeamName, nil
+}
+
+// ReassignReviewerTo передает ревью конкретному пользователю: он должен быть активен, не быть автором
+// или уже назначенным ревьювером и, если anyTeam не задан, состоять в команде прежнего ревьювера
+// или в ее резервной команде при allow_cross_team
+func (s *PullRequestService) ReassignReviewerTo(prID, oldReviewerID, newReviewerID string, anyTeam bool) (*domain.PullRequest, error) {
+	pr, _, err := s.prepareReassign(prID, oldReviewerID)
+	if err != nil {
+		return nil, err
+	}
+	team, err := s.reviewerTeam(pr, oldReviewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	newUser, err := s.userRepo.GetByID(newReviewerID)
+	if err != nil {
+		return nil, ErrReviewerNotFound
+	}
+	if newUser.UserID == pr.AuthorID {
+		return nil, ErrReviewerIsAuthor
+	}
+	for _, u := range pr.AssignReviewers {
+		if u.UserID == newUser.UserID {
+			return nil, ErrAlreadyAssigned
+		}
+	}
+	if err := s.ensureNotExcluded(pr.AuthorID, newUser.UserID); err != nil {
+		return nil, err
+	}
+
+	// активность и отпуск проверяются тем же запросом, что и при автоматическом выборе
+	available, err := s.userRepo.ListAvailableByIDs([]string{newUser.UserID})
+	if err != nil {
+		return nil, err
+	}
+	if len(available) == 0 {
+		return nil, ErrReviewerInactive
+	}
+
+	if !anyTeam {
+		allowed, err := s.isAllowedTeam(team, newUser.Teams)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, ErrTeamNotAllowed
+		}
+	}
+
+	if err := s.ensureLeadReplacement(pr, oldReviewerID, newUser.UserID); err != nil {
+		return nil, err
+	}
+
+	if !slices.Contains(newUser.Teams, team) {
+		pr.FallbackReviewers = []string{newUser.UserID}
+	}
+
+	if err := s.replaceReviewer(pr, oldReviewerID, newUser); err != nil {
+		return nil, err
+	}
+	return pr, nil
+}
+
+// ensureLeadReplacement проверяет, что после замены в PR останется лид, которого требует команда PR:
+// если прежний ревьювер был последним лидом, заменой должен быть лид этой команды
+func (s *PullRequestService) ensureLeadReplacement(pr *domain.PullRequest, oldReviewerID, newReviewerID string) error {
+	settings, err := s.teams.GetSettings(pr.TeamName)
+	if err != nil {
+		return err
+	}
+	if !s.needsLead(settings, pr, oldReviewerID) {
+		return nil
+	}
+
+	// роли читаются в контексте команды PR
+	members, err := s.userRepo.ListActiveByTeam(pr.TeamName)
+	if err != nil {
+		return err
+	}
+	if !containsUser(leadsOf(members), newReviewerID) {
+		return ErrLeadUnavailable
+	}
+	return nil
+}
+
+// ensureLeadKept проверяет, что снятие ревьювера без замены не уберет из PR последнего лида,
+// которого требует команда PR
+func (s *PullRequestService) ensureLeadKept(pr *domain.PullRequest, userID string) error {
+	if !containsUser(leadsOf(pr.AssignReviewers), userID) {
+		return nil
+	}
+	settings, err := s.teams.GetSettings(pr.TeamName)
+	if err != nil {
+		return err
+	}
+	if s.needsLead(settings, pr, userID) {
+		return ErrLeadUnavailable
+	}
+	return nil
+}
+
+// ensureNotExcluded проверяет, что правила исключения не запрещают пользователю ревьюить автора
+func (s *PullRequestService) ensureNotExcluded(authorID, userID string) error {
+	blocked, err := s.excluded.ListExcludedReviewers(authorID)
+	if err != nil {
+		return err
+	}
+	if slices.Contains(blocked, userID) {
+		return ErrReviewerExcluded
+	}
+	return nil
+}
+
+// prepareReassign загружает PR и прежнего ревьювера и проверяет, что переназначение возможно
+func (s *PullRequestService) prepareReassign(prID, oldReviewerID string) (*domain.PullRequest, *domain.User, error) {
+	pr, err := s.getPR(prID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := ensureOpen(pr); err != nil {
+		return nil, nil, err
+	}
+
+	for _, u := range pr.AssignReviewers {
+		if u.UserID == oldReviewerID {
+			return pr, u, nil
+		}
+	}
+	return nil, nil, ErrNotAssigned
+}
+
+// isAllowedTeam проверяет, что ревьювер из команд targets может заменить участника команды team
+func (s *PullRequestService) isAllowedTeam(team string, targets []string) (bool, error) {
+	if slices.Contains(targets, team) {
+		return true, nil
+	}
+
+	settings, err := s.teams.GetSettings(team)
+	if err != nil {
+		return false, err
+	}
+	if !settings.AllowCrossTeam {
+		return false, nil
+	}
+	for _, fallback := range settings.FallbackTeams {
+		if slices.Contains(targets, fallback) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// replaceReviewer сохраняет замену ревьювера и обновляет список ревьюверов PR для ответа;
+// если после чтения PR перестал быть открытым или ревьювера сняли, возвращает ошибку этого состояния
+func (s *PullRequestService) replaceReviewer(pr *domain.PullRequest, oldReviewerID string, newReviewer *domain.User) error {
+	if err := s.prRepo.UpdateReviewer(pr.PRID, oldReviewerID, newReviewer.UserID); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrReviewerNotAssigned):
+			return ErrNotAssigned
+		case errors.Is(err, repository.ErrReviewerAlreadyAssigned):
+			return ErrAlreadyAssigned
+		case errors.Is(err, repository.ErrPRStateChanged):
+			return s.stateError(pr.PRID, err)
+		}
+		return err
+	}
+
+	for i, u := range pr.AssignReviewers {
+		if u.UserID == oldReviewerID {
+			pr.AssignReviewers[i] = newReviewer
+		}
+	}
+	return nil
+}
+
+// AddReviewer вручную добавляет ревьювера сверх автоматически выбранных: пользователь должен быть
+// активен, не быть автором или уже назначенным, а число ревьюверов не должно превысить max_reviewers
+// команды PR
+func (s *PullRequestService) AddReviewer(prID, userID string) (*domain.PullRequest, error) {
+	pr, err := s.getPR(prID)
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureOpen(pr); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, ErrReviewerNotFound
+	}
+	if user.UserID == pr.AuthorID {
+		return nil, ErrReviewerIsAuthor
+	}
+	for _, u := range pr.AssignReviewers {
+		if u.UserID == user.UserID {
+			return nil, ErrAlreadyAssigned
+		}
+	}
+	if err := s.ensureNotExcluded(pr.AuthorID, user.UserID); err != nil {
+		return nil, err
+	}
+
+	available, err := s.userRepo.ListAvailableByIDs([]string{user.UserID})
+	if err != nil {
+		return nil, err
+	}
+	if len(available) == 0 {
+		return nil, ErrReviewerInactive
+	}
+
+	limit, err := s.maxReviewers(pr.TeamName)
+	if err != nil {
+		return nil, err
+	}
+	if len(pr.AssignReviewers) >= limit {
+		return nil, ErrTooManyReviewers
+	}
+
+	// статус, лимит и повтор назначения перепроверяются под блокировкой PR
+	if err := s.prRepo.AddReviewer(pr.PRID, user.UserID, limit); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrReviewerLimitReached):
+			return nil, ErrTooManyReviewers
+		case errors.Is(err, repository.ErrReviewerAlreadyAssigned):
+			return nil, ErrAlreadyAssigned
+		case errors.Is(err, repository.ErrPRStateChanged):
+			return nil, s.stateError(prID, err)
+		}
+		return nil, err
+	}
+	pr.AssignReviewers = append(pr.AssignReviewers, user)
+	return pr, nil
+}
+
+// RemoveReviewer снимает ревьювера с открытого PR без замены; последнего лида в PR команды
+// с require_lead снять нельзя
+func (s *PullRequestService) RemoveReviewer(prID, userID string) (*domain.PullRequest, error) {
+	pr, err := s.getPR(prID)
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureOpen(pr); err != nil {
+		return nil, err
+	}
+	if err := s.ensureLeadKept(pr, userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.prRepo.RemoveReviewer(pr.PRID, userID); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrReviewerNotAssigned):
+			return nil, ErrNotAssigned
+		case errors.Is(err, repository.ErrPRStateChanged):
+			return nil, s.stateError(prID, err)
+		}
+		return nil, err
+	}
+
+	reviewers := make([]*domain.User, 0, len(pr.AssignReviewers))
+	for _, u := range pr.AssignReviewers {
+		if u.UserID != userID {
+			reviewers = append(reviewers, u)
+		}
+	}
+	pr.AssignReviewers = reviewers
+	return pr, nil
+}
+
+// maxReviewers возвращает предел числа ревьюверов PR по настройкам его команды
+func (s *PullRequestService) maxReviewers(teamName string) (int, error) {
+	settings, err := s.teams.GetSettings(teamName)
+	if err != nil {
+		return 0, err
+	}
+	if settings.MaxReviewers > 0 {
+		return settings.MaxReviewers, nil
+	}
+	return MaxReviewersCount, nil
+}
+
+// ReassignReviewsOfUser передает открытые ревью пользователя другим участникам его команды,
+// PR без свободных кандидатов остаются за ним; возвращает число переназначенных ревью
+func (s *PullRequestService) ReassignReviewsOfUser(userID string) (int, error) {
+	prs, err := s.userRepo.GetReviewPR(userID)
+	if err != nil {
+		return 0, err
+	}
+
+	reassigned := 0
+	for _, pr := range prs {
+		if pr.Status != domain.StatusOpen {
+			continue
+		}
+
+		_, _, err := s.ReassignReviewer(pr.PRID, userID)
+		switch {
+		case err == nil:
+			reassigned++
+		case errors.Is(err, ErrNoCandidate) || errors.Is(err, ErrReviewersAtCapacity):
+			s.logger.Warnf("no replacement for %s on PR %s: %v", userID, pr.PRID, err)
+		case isStaleReview(err):
+			s.logger.Infof("review of %s on PR %s changed concurrently, skipped: %v", userID, pr.PRID, err)
+		default:
+			return reassigned, err
+		}
+	}
+	return reassigned, nil
+}
+
+// isStaleReview проверяет, что ревью перестало нуждаться в переназначении после чтения списка:
+// PR слит, закрыт или черновик, ревьювер уже снят или удален
+func isStaleReview(err error) bool {
+	return errors.Is(err, ErrPRMerged) || errors.Is(err, ErrPRClosed) || errors.Is(err, ErrPRDraft) ||
+		errors.Is(err, ErrNotAssigned) || errors.Is(err, ErrReviewerNotFound)
+}
+
+// ReassignReviewsOfRemovedUsers передает участникам команды PR открытые ревью пользователей,
+// выведенных из команды teamName: в PR этой команды, а если других команд у пользователя
+// не осталось - во всех PR; PR без свободных кандидатов остаются за ними
+func (s *PullRequestService) ReassignReviewsOfRemovedUsers(teamName string, userIDs []string) (int, error) {
+	reassigned := 0
+	for _, userID := range userIDs {
+		user, err := s.userRepo.GetByID(userID)
+		if err != nil {
+			return reassigned, err
+		}
+		prs, err := s.userRepo.GetReviewPR(userID)
+		if err != nil {
+			return reassigned, err
+		}
+
+		for _, short := range prs {
+			if short.Status != domain.StatusOpen {
+				continue
+			}
+
+			pr, _, err := s.prepareReassign(short.PRID, userID)
+			if err != nil {
+				if errors.Is(err, ErrPRDraft) || errors.Is(err, ErrNotAssigned) {
+					continue
+				}
+				return reassigned, err
+			}
+			if len(user.Teams) > 0 && pr.TeamName != teamName {
+				continue
+			}
+			if pr.TeamName == "" {
+				s.logger.Warnf("no team to pick replacement for %s on PR %s", userID, pr.PRID)
+				continue
+			}
+
+			selected, err := s.pickReplacement(pr, pr.TeamName, userID, nil)
+			if err != nil {
+				if errors.Is(err, ErrNoCandidate) || errors.Is(err, ErrReviewersAtCapacity) {
+					s.logger.Warnf("no replacement for %s on PR %s: %v", userID, pr.PRID, err)
+					continue
+				}
+				return reassigned, err
+			}
+			if err := s.replaceReviewer(pr, userID, selected); err != nil {
+				return reassigned, err
+			}
+			reassigned++
+		}
+	}
+	return reassigned, nil
+}
+
+// PlanReviewHandoff подбирает замену на каждое открытое ревью пользователя в его команде,
+// ничего не меняя; если замены нет, ToUserID остается пустым. Передачи, уже включенные в план,
+// учитываются в лимитах открытых ревью получателей
+func (s *PullRequestService) PlanReviewHandoff(userID string) ([]*domain.ReviewHandoff, error) {
+	prs, err := s.userRepo.GetReviewPR(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := []*domain.ReviewHandoff{}
+	pending := make(map[string]int)
+	for _, short := range prs {
+		if short.Status != domain.StatusOpen {
+			continue
+		}
+		pr, err := s.getPR(short.PRID)
+		if err != nil {
+			return nil, err
+		}
+
+		team, err := s.reviewerTeam(pr, userID)
+		if err != nil {
+			return nil, err
+		}
+
+		handoff := &domain.ReviewHandoff{PRID: pr.PRID, FromUserID: userID}
+		selected, err := s.pickReplacement(pr, team, userID, pending)
+		switch {
+		case err == nil:
+			limits, err := s.reviewLimits([]*domain.User{selected})
+			if err != nil {
+				return nil, err
+			}
+			handoff.ToUserID = selected.UserID
+			handoff.ToLimit = limits[selected.UserID]
+			pending[selected.UserID]++
+		case errors.Is(err, ErrNoCandidate) || errors.Is(err, ErrReviewersAtCapacity):
+			s.logger.Warnf("no replacement for %s on PR %s: %v", userID, pr.PRID, err)
+		default:
+			return nil, err
+		}
+		plan = append(plan, handoff)
+	}
+	return plan, nil
+}
+
+// ReassignExcludedReviews передает другим открытые ревью reviewerID в PR автора authorID,
+// запрещенные правилом исключения; без замены ревью снимается. Последнего лида в PR команды
+// с require_lead заменяет только лид, а без лида на замену он остается в PR.
+// Возвращает число переназначенных и снятых
+func (s *PullRequestService) ReassignExcludedReviews(reviewerID, authorID string) (int, int, error) {
+	prs, err := s.userRepo.GetReviewPR(reviewerID)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	reassigned, unassigned := 0, 0
+	for _, short := range prs {
+		if short.AuthorID != authorID || short.Status != domain.StatusOpen {
+			continue
+		}
+
+		pr, _, err := s.prepareReassign(short.PRID, reviewerID)
+		if err != nil {
+			if isStaleReview(err) {
+				continue
+			}
+			return reassigned, unassigned, err
+		}
+		lastLead := s.ensureLeadKept(pr, reviewerID)
+		if lastLead != nil && !errors.Is(lastLead, ErrLeadUnavailable) {
+			return reassigned, unassigned, lastLead
+		}
+		team, err := s.reviewerTeam(pr, reviewerID)
+		if err != nil {
+			return reassigned, unassigned, err
+		}
+
+		selected, err := s.pickReplacement(pr, team, reviewerID, nil)
+		if err == nil && lastLead != nil && selected.Role != domain.RoleLead {
+			err = ErrLeadUnavailable
+		}
+		if err == nil {
+			err = s.replaceReviewer(pr, reviewerID, selected)
+		}
+		noCandidate := errors.Is(err, ErrNoCandidate) || errors.Is(err, ErrReviewersAtCapacity)
+		switch {
+		case err == nil:
+			reassigned++
+		case errors.Is(err, ErrLeadUnavailable) || (lastLead != nil && noCandidate):
+			s.logger.Warnf("no lead to replace excluded reviewer %s on PR %s, keeping the only lead: %v", reviewerID, pr.PRID, err)
+		case noCandidate:
+			s.logger.Warnf("no replacement for excluded reviewer %s on PR %s, unassigning: %v", reviewerID, pr.PRID, err)
+			if _, err := s.RemoveReviewer(pr.PRID, reviewerID); err != nil {
+				if isStaleReview(err) || errors.Is(err, ErrLeadUnavailable) {
+					continue
+				}
+				return reassigned, unassigned, err
+			}
+			unassigned++
+		case isStaleReview(err):
+		default:
+			return reassigned, unassigned, err
+		}
+	}
+	return reassigned, unassigned, nil
+}
+
+// ReassignReviewersForTeam безопасно переназначает ревьюверов для всех открытых PR команды,
+// при require_lead неактивного лида по возможности заменяет другой лид; возвращает ревью,
+// для которых не нашлось замены (ToUserID пуст), - они остаются за неактивными ревьюверами
+func (s *PullRequestService) ReassignReviewersForTeam(teamName string) ([]*domain.ReviewHandoff, error) {
+	prs, err := s.prRepo.ListOpenPRsByTeam(teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	activeUsers, err := s.userRepo.ListActiveByTeam(teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	settings, selector, err := s.teamPolicy(teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	unstaffed := []*domain.ReviewHandoff{}
+
+	for _, pr := range prs {
+		selector := s.rotation.ForAuthor(selector, pr.AuthorID, settings.RotationWindowDays)
+		blocked, err := s.excluded.ListExcludedReviewers(pr.AuthorID)
+		if err != nil {
+			return nil, err
+		}
+		for _, oldReviewer := range pr.AssignReviewers {
+			if !oldReviewer.IsActive {
+				// загрузка меняется по ходу переназначения, поэтому лимиты проверяем каждый раз
+				candidates, _, err := s.filterByCapacity(activeUsers)
+				if err != nil {
+					return nil, err
+				}
+
+				excluded := append([]string{pr.AuthorID}, blocked...)
+				for _, u := range pr.AssignReviewers {
+					excluded = append(excluded, u.UserID)
+				}
+
+				var selected []*domain.User
+				if s.needsLead(settings, pr, oldReviewer.UserID) {
+					selected, err = selector.Select(excludeUsers(leadsOf(candidates), excluded...), 1)
+					if err != nil {
+						return nil, err
+					}
+				}
+				if len(selected) == 0 {
+					selected, _, err = s.pickReviewers(settings, selector, candidates, 1, excluded...)
+					if err != nil {
+						return nil, err
+					}
+				}
+
+				if len(selected) == 0 {
+					s.logger.Warnf("no replacement with free capacity or allowed by exclusion rules for %s on PR %s",
+						oldReviewer.UserID, pr.PRID)
+					unstaffed = append(unstaffed, &domain.ReviewHandoff{PRID: pr.PRID, FromUserID: oldReviewer.UserID})
+					continue
+				}
+				if err := s.replaceReviewer(pr, oldReviewer.UserID, selected[0]); err != nil {
+					if isStaleReview(err) {
+						s.logger.Infof("review of %s on PR %s changed concurrently, skipped: %v", oldReviewer.UserID, pr.PRID, err)
+						continue
+					}
+					return nil, err
+				}
+			}
+		}
+	}
+
+	return unstaffed, nil
+}
+
+// ReviewPairs возвращает матрицу пар автор-ревьювер по PR команды и использованное окно в днях;
+// при windowDays = 0 берется окно ротации команды, а если она не задана - DefaultRotationWindowDays
+func (s *PullRequestService) ReviewPairs(teamName string, windowDays int) ([]*domain.ReviewPair, int, error) {
+	if windowDays < 0 || windowDays > MaxRotationWindowDays {
+		return nil, 0, ErrInvalidFilter
+	}
+	if windowDays == 0 {
+		settings, err := s.teams.GetSettings(teamName)
+		if err != nil {
+			return nil, 0, err
+		}
+		windowDays = settings.RotationWindowDays
+	}
+	if windowDays == 0 {
+		windowDays = DefaultRotationWindowDays
+	}
+
+	pairs, err := s.rotation.Pairs(teamName, windowDays)
+	if err != nil {
+		return nil, 0, err
+	}
+	return pairs, windowDays, nil
 }
 
 // GetStats сервисный метод для получения статистики
@@ -216,8 +1682,9 @@ func (s *PullRequestService) GetStats() (map[string]interface{}, error) {
 
 	// количество PR по статусу
 	statusCount := map[string]int{
-		"OPEN":   0,
-		"MERGED": 0,
+		domain.StatusOpen:   0,
+		domain.StatusMerged: 0,
+		domain.StatusClosed: 0,
 	}
 	for _, pr := range prs {
 		statusCount[pr.Status]++
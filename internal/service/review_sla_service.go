@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/domain"
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/repository/interfaces"
+	"go.uber.org/zap"
+)
+
+// OverdueReassigner заменяет ревьювера, не уложившегося в SLA
+type OverdueReassigner interface {
+	ReassignReviewer(prID, oldReviewerID string) (*domain.PullRequest, string, error)
+}
+
+// ReviewSLAService - сервис контроля сроков ревью
+type ReviewSLAService struct {
+	prRepo     interfaces.PullRequestRepo
+	reassigner OverdueReassigner
+	logger     *zap.SugaredLogger
+}
+
+// NewReviewSLAService создает сервис контроля сроков ревью
+func NewReviewSLAService(prRepo interfaces.PullRequestRepo, reassigner OverdueReassigner, logger *zap.SugaredLogger) *ReviewSLAService {
+	return &ReviewSLAService{
+		prRepo:     prRepo,
+		reassigner: reassigner,
+		logger:     logger,
+	}
+}
+
+// CheckOverdue отмечает назначения, нарушившие SLA, и переназначает их, если команда это разрешила
+func (s *ReviewSLAService) CheckOverdue() error {
+	breached, err := s.prRepo.ListBreachedReviews()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, review := range breached {
+		if err := s.prRepo.MarkReviewOverdue(review.PRID, review.ReviewerID, now); err != nil {
+			return err
+		}
+		s.logger.Warnf("review of PR %s by %s is overdue since %s", review.PRID, review.ReviewerID, review.DueAt)
+
+		if !review.AutoReassign {
+			continue
+		}
+
+		// без замены ревью остается в списке просроченных
+		_, newReviewerID, err := s.reassigner.ReassignReviewer(review.PRID, review.ReviewerID)
+		if err != nil {
+			s.logger.Warnf("failed to reassign overdue review of PR %s from %s: %v", review.PRID, review.ReviewerID, err)
+			continue
+		}
+		s.logger.Infof("overdue review of PR %s reassigned from %s to %s", review.PRID, review.ReviewerID, newReviewerID)
+	}
+	return nil
+}
+
+// ListOverdue возвращает просроченные ревью, по которым еще нет вердикта
+func (s *ReviewSLAService) ListOverdue() ([]*domain.OverdueReview, error) {
+	return s.prRepo.ListOverdueReviews()
+}
+
+// Run периодически вызывает CheckOverdue до отмены контекста
+func (s *ReviewSLAService) Run(ctx context.Context, interval time.Duration) {
+	runEvery(ctx, interval, s.logger, "review SLA check", s.CheckOverdue)
+}
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/domain"
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/repository/interfaces"
+)
+
+// названия встроенных стратегий выбора ревьюверов
+const (
+	StrategyFirstN      = "first_n"
+	StrategyRandom      = "random"
+	StrategyRoundRobin  = "round_robin"
+	StrategyLeastLoaded = "least_loaded"
+)
+
+// ReviewerSelector выбирает до n ревьюверов из уже отфильтрованных кандидатов
+type ReviewerSelector interface {
+	Select(candidates []*domain.User, n int) ([]*domain.User, error)
+}
+
+// IsKnownStrategy проверяет, что стратегия с таким именем существует
+func IsKnownStrategy(strategy string) bool {
+	switch strategy {
+	case StrategyFirstN, StrategyRandom, StrategyRoundRobin, StrategyLeastLoaded:
+		return true
+	}
+	return false
+}
+
+// ReviewerSelectors хранит все стратегии и отдает нужную по имени
+type ReviewerSelectors struct {
+	byName map[string]ReviewerSelector
+	dflt   ReviewerSelector
+}
+
+// NewReviewerSelectors создает все встроенные стратегии, defaultStrategy используется,
+// если у команды стратегия не задана
+func NewReviewerSelectors(defaultStrategy string, seed int64, prRepo interfaces.PullRequestReader) (*ReviewerSelectors, error) {
+	dflt, err := NewReviewerSelector(defaultStrategy, seed, prRepo)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]ReviewerSelector)
+	for _, name := range []string{StrategyFirstN, StrategyRandom, StrategyRoundRobin, StrategyLeastLoaded} {
+		if name == defaultStrategy {
+			byName[name] = dflt
+			continue
+		}
+		selector, err := NewReviewerSelector(name, seed, prRepo)
+		if err != nil {
+			return nil, err
+		}
+		byName[name] = selector
+	}
+
+	return &ReviewerSelectors{byName: byName, dflt: dflt}, nil
+}
+
+// Get возвращает стратегию по имени или стратегию по умолчанию
+func (r *ReviewerSelectors) Get(strategy string) ReviewerSelector {
+	if selector, ok := r.byName[strategy]; ok {
+		return selector
+	}
+	return r.dflt
+}
+
+// NewReviewerSelector создает стратегию выбора по имени из конфига
+func NewReviewerSelector(strategy string, seed int64, prRepo interfaces.PullRequestReader) (ReviewerSelector, error) {
+	switch strategy {
+	case "", StrategyFirstN:
+		return &FirstNSelector{}, nil
+	case StrategyRandom:
+		return NewRandomSelector(seed), nil
+	case StrategyRoundRobin:
+		return NewRoundRobinSelector(), nil
+	case StrategyLeastLoaded:
+		return NewLeastLoadedSelector(prRepo), nil
+	default:
+		return nil, fmt.Errorf("unknown reviewer strategy %q", strategy)
+	}
+}
+
+// FirstNSelector берет первых n кандидатов в порядке выдачи БД
+type FirstNSelector struct{}
+
+// Select возвращает первых n кандидатов
+func (s *FirstNSelector) Select(candidates []*domain.User, n int) ([]*domain.User, error) {
+	n = min(max(n, 0), len(candidates))
+	return candidates[:n], nil
+}
+
+// RandomSelector выбирает n случайных кандидатов
+type RandomSelector struct {
+	mu  sync.Mutex
+	rnd *rand.Rand
+}
+
+// NewRandomSelector создает случайную стратегию, seed = 0 берется от текущего времени
+func NewRandomSelector(seed int64) *RandomSelector {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &RandomSelector{rnd: rand.New(rand.NewSource(seed))}
+}
+
+// Select перемешивает кандидатов и берет первых n
+func (s *RandomSelector) Select(candidates []*domain.User, n int) ([]*domain.User, error) {
+	shuffled := make([]*domain.User, len(candidates))
+	copy(shuffled, candidates)
+
+	s.mu.Lock()
+	s.rnd.Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+	s.mu.Unlock()
+
+	return (&FirstNSelector{}).Select(shuffled, n)
+}
+
+// RoundRobinSelector выбирает кандидатов по кругу отдельно для каждой команды PR
+type RoundRobinSelector struct {
+	mu   sync.Mutex
+	last map[string]string // последний выбранный user_id по команде
+}
+
+// NewRoundRobinSelector создает стратегию round-robin
+func NewRoundRobinSelector() *RoundRobinSelector {
+	return &RoundRobinSelector{last: make(map[string]string)}
+}
+
+// Select берет n кандидатов по общему кругу, не привязанному к команде
+func (s *RoundRobinSelector) Select(candidates []*domain.User, n int) ([]*domain.User, error) {
+	return s.SelectForTeam("", candidates, n)
+}
+
+// SelectForTeam берет n кандидатов по кругу команды teamName: круг идет по составу команды
+// в порядке user_id и продолжается с первого кандидата после последнего выбранного, поэтому
+// исключенные из выборки (автор, уже назначенные) пропускаются, не сдвигая очередь остальных
+func (s *RoundRobinSelector) SelectForTeam(teamName string, candidates []*domain.User, n int) ([]*domain.User, error) {
+	n = min(max(n, 0), len(candidates))
+	if n == 0 {
+		return []*domain.User{}, nil
+	}
+
+	// стабильный порядок, чтобы круг не зависел от выдачи БД
+	sorted := sortedByID(candidates)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	last := s.last[teamName]
+	start := sort.Search(len(sorted), func(i int) bool { return sorted[i].UserID > last })
+
+	selected := make([]*domain.User, 0, n)
+	for i := 0; i < n; i++ {
+		selected = append(selected, sorted[(start+i)%len(sorted)])
+	}
+	s.last[teamName] = selected[n-1].UserID
+	return selected, nil
+}
+
+// teamRoundRobin привязывает round-robin к команде PR
+type teamRoundRobin struct {
+	inner    *RoundRobinSelector
+	teamName string
+}
+
+// Select берет n кандидатов по кругу команды
+func (s *teamRoundRobin) Select(candidates []*domain.User, n int) ([]*domain.User, error) {
+	return s.inner.SelectForTeam(s.teamName, candidates, n)
+}
+
+// ForTeam привязывает стратегию к команде: round-robin ведет по ней отдельный круг,
+// остальные стратегии от команды не зависят и возвращаются как есть. Уже привязанная стратегия,
+// в том числе обернутая памятью ротации, перепривязывается к teamName
+func ForTeam(selector ReviewerSelector, teamName string) ReviewerSelector {
+	switch s := selector.(type) {
+	case *RoundRobinSelector:
+		return &teamRoundRobin{inner: s, teamName: teamName}
+	case *teamRoundRobin:
+		return &teamRoundRobin{inner: s.inner, teamName: teamName}
+	case *rotationSelector:
+		rebound := *s
+		rebound.inner = ForTeam(s.inner, teamName)
+		return &rebound
+	}
+	return selector
+}
+
+// LeastLoadedSelector выбирает кандидатов с наименьшим числом открытых ревью,
+// при равной загрузке побеждает меньший user_id
+type LeastLoadedSelector struct {
+	prRepo interfaces.PullRequestReader
+}
+
+// NewLeastLoadedSelector создает стратегию по наименьшей загрузке
+func NewLeastLoadedSelector(prRepo interfaces.PullRequestReader) *LeastLoadedSelector {
+	return &LeastLoadedSelector{prRepo: prRepo}
+}
+
+// Select сортирует кандидатов по числу открытых ревью и берет первых n
+func (s *LeastLoadedSelector) Select(candidates []*domain.User, n int) ([]*domain.User, error) {
+	if len(candidates) == 0 {
+		return []*domain.User{}, nil
+	}
+
+	ids := make([]string, 0, len(candidates))
+	for _, u := range candidates {
+		ids = append(ids, u.UserID)
+	}
+
+	// одним запросом получаем загрузку всех кандидатов
+	loads, err := s.prRepo.CountOpenReviews(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	sorted := sortedByID(candidates)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return loads[sorted[i].UserID] < loads[sorted[j].UserID]
+	})
+	return (&FirstNSelector{}).Select(sorted, n)
+}
+
+// sortedByID возвращает копию кандидатов, отсортированную по user_id
+func sortedByID(candidates []*domain.User) []*domain.User {
+	sorted := make([]*domain.User, len(candidates))
+	copy(sorted, candidates)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].UserID < sorted[j].UserID
+	})
+	return sorted
+}
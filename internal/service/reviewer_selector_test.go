@@ -0,0 +1,190 @@
+package service
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/domain"
+)
+
+// users создает кандидатов с указанными id
+func users(ids ...string) []*domain.User {
+	list := make([]*domain.User, 0, len(ids))
+	for _, id := range ids {
+		list = append(list, &domain.User{UserID: id, TeamName: "backend", IsActive: true})
+	}
+	return list
+}
+
+// idsOf возвращает id выбранных кандидатов
+func idsOf(list []*domain.User) []string {
+	ids := make([]string, 0, len(list))
+	for _, u := range list {
+		ids = append(ids, u.UserID)
+	}
+	return ids
+}
+
+// тестируем выбор первых n кандидатов
+func TestFirstNSelector(t *testing.T) {
+	cases := []struct {
+		name       string
+		candidates []string
+		n          int
+		want       []string
+	}{
+		{"берет_первых_n", []string{"u3", "u1", "u2"}, 2, []string{"u3", "u1"}},
+		{"n_больше_кандидатов", []string{"u1", "u2"}, 5, []string{"u1", "u2"}},
+		{"нулевое_n", []string{"u1", "u2"}, 0, []string{}},
+		{"отрицательное_n", []string{"u1", "u2"}, -1, []string{}},
+		{"нет_кандидатов", nil, 2, []string{}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := (&FirstNSelector{}).Select(users(c.candidates...), c.n)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(idsOf(got), c.want) {
+				t.Fatalf("expected %v, got %v", c.want, idsOf(got))
+			}
+		})
+	}
+}
+
+// тестируем случайный выбор с фиксированным seed
+func TestRandomSelector(t *testing.T) {
+	candidates := []string{"u1", "u2", "u3", "u4", "u5"}
+
+	cases := []struct {
+		name     string
+		n        int
+		wantSize int
+	}{
+		{"выбирает_n", 2, 2},
+		{"n_больше_кандидатов", 10, 5},
+		{"нулевое_n", 0, 0},
+		{"отрицательное_n", -3, 0},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			first, err := NewRandomSelector(42).Select(users(candidates...), c.n)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			second, err := NewRandomSelector(42).Select(users(candidates...), c.n)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(first) != c.wantSize {
+				t.Fatalf("expected %d reviewers, got %v", c.wantSize, idsOf(first))
+			}
+			// один seed - одна и та же выборка
+			if !slices.Equal(idsOf(first), idsOf(second)) {
+				t.Fatalf("same seed gave different picks: %v and %v", idsOf(first), idsOf(second))
+			}
+
+			seen := make(map[string]bool)
+			for _, id := range idsOf(first) {
+				if seen[id] || !slices.Contains(candidates, id) {
+					t.Fatalf("unexpected pick %s in %v", id, idsOf(first))
+				}
+				seen[id] = true
+			}
+		})
+	}
+}
+
+// тестируем круг round-robin: каждый вызов - кандидаты без исключенных и ожидаемый выбор
+func TestRoundRobinSelector(t *testing.T) {
+	type call struct {
+		team       string
+		candidates []string
+		n          int
+		want       []string
+	}
+
+	cases := []struct {
+		name  string
+		calls []call
+	}{
+		{"идет_по_кругу", []call{
+			{"backend", []string{"u2", "u1", "u3"}, 2, []string{"u1", "u2"}},
+			{"backend", []string{"u1", "u2", "u3"}, 2, []string{"u3", "u1"}},
+			{"backend", []string{"u1", "u2", "u3"}, 1, []string{"u2"}},
+		}},
+		{"исключенные_не_сдвигают_очередь", []call{
+			{"backend", []string{"u1", "u2", "u3", "u4"}, 1, []string{"u1"}},
+			// u2 - автор PR, следующим идет u3, а не u4
+			{"backend", []string{"u1", "u3", "u4"}, 1, []string{"u3"}},
+			{"backend", []string{"u1", "u2", "u3", "u4"}, 1, []string{"u4"}},
+			{"backend", []string{"u1", "u2", "u3", "u4"}, 1, []string{"u1"}},
+		}},
+		{"у_команд_свои_круги", []call{
+			{"backend", []string{"u1", "u2", "u3"}, 1, []string{"u1"}},
+			{"frontend", []string{"u1", "u2", "u3"}, 1, []string{"u1"}},
+			{"backend", []string{"u1", "u2", "u3"}, 1, []string{"u2"}},
+		}},
+		{"n_больше_кандидатов", []call{
+			{"backend", []string{"u1", "u2"}, 5, []string{"u1", "u2"}},
+		}},
+		{"пустой_выбор_не_двигает_круг", []call{
+			{"backend", []string{"u1", "u2"}, 0, []string{}},
+			{"backend", nil, 2, []string{}},
+			{"backend", []string{"u1", "u2"}, 1, []string{"u1"}},
+		}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rr := NewRoundRobinSelector()
+			for i, call := range c.calls {
+				got, err := ForTeam(rr, call.team).Select(users(call.candidates...), call.n)
+				if err != nil {
+					t.Fatalf("call %d: unexpected error: %v", i, err)
+				}
+				if !slices.Equal(idsOf(got), call.want) {
+					t.Fatalf("call %d: expected %v, got %v", i, call.want, idsOf(got))
+				}
+			}
+		})
+	}
+}
+
+// тестируем перепривязку стратегии к резервной команде: ее выбор не сдвигает круг команды PR
+func TestForTeamRebinds(t *testing.T) {
+	rr := NewRoundRobinSelector()
+	backend := ForTeam(rr, "backend")
+
+	cases := []struct {
+		name     string
+		selector ReviewerSelector
+		want     []string
+	}{
+		{"круг_команды_pr", backend, []string{"u1"}},
+		{"резервная_команда", ForTeam(backend, "frontend"), []string{"u1"}},
+		{"резервная_команда_повторно", ForTeam(backend, "frontend"), []string{"u2"}},
+		{"круг_команды_pr_не_сдвинут", backend, []string{"u2"}},
+	}
+	for _, c := range cases {
+		got, err := c.selector.Select(users("u1", "u2", "u3"), 1)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", c.name, err)
+		}
+		if !slices.Equal(idsOf(got), c.want) {
+			t.Fatalf("%s: expected %v, got %v", c.name, c.want, idsOf(got))
+		}
+	}
+
+	// память ротации сохраняется, перепривязывается только стратегия внутри нее
+	rotated := ForTeam(&rotationSelector{inner: backend, authorID: "u9"}, "frontend").(*rotationSelector)
+	if rotated.authorID != "u9" {
+		t.Fatalf("expected author u9, got %s", rotated.authorID)
+	}
+	if team := rotated.inner.(*teamRoundRobin).teamName; team != "frontend" {
+		t.Fatalf("expected team frontend, got %s", team)
+	}
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/domain"
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/repository/interfaces"
+)
+
+// окно матрицы пар для команды без rotation_window_days и предел окна в настройках
+const (
+	DefaultRotationWindowDays = 30
+	MaxRotationWindowDays     = 365
+)
+
+// RotationMemory помнит, кто и как недавно ревьюил PR автора, и штрафует повторные пары
+type RotationMemory struct {
+	prRepo interfaces.PullRequestReader
+}
+
+// NewRotationMemory создает память ротации пар автор-ревьювер
+func NewRotationMemory(prRepo interfaces.PullRequestReader) *RotationMemory {
+	return &RotationMemory{prRepo: prRepo}
+}
+
+// ForAuthor оборачивает стратегию команды так, чтобы она в первую очередь выбирала тех,
+// кто реже и давнее ревьюил PR автора; при windowDays = 0 стратегия не меняется
+func (m *RotationMemory) ForAuthor(selector ReviewerSelector, authorID string, windowDays int) ReviewerSelector {
+	if windowDays <= 0 {
+		return selector
+	}
+	return &rotationSelector{inner: selector, memory: m, authorID: authorID, window: windowDuration(windowDays)}
+}
+
+// Penalties возвращает штраф кандидатов за ревью PR автора за окно, кандидатов без пар в ответе нет
+func (m *RotationMemory) Penalties(authorID string, candidateIDs []string, window time.Duration) (map[string]float64, error) {
+	pairs, err := m.prRepo.ListReviewPairsByAuthor(authorID, candidateIDs, window)
+	if err != nil {
+		return nil, err
+	}
+
+	penalties := make(map[string]float64, len(pairs))
+	for _, pair := range pairs {
+		penalties[pair.ReviewerID] = pair.Penalty
+	}
+	return penalties, nil
+}
+
+// Pairs возвращает матрицу пар автор-ревьювер по PR команды за окно
+func (m *RotationMemory) Pairs(teamName string, windowDays int) ([]*domain.ReviewPair, error) {
+	return m.prRepo.ListReviewPairsByTeam(teamName, windowDuration(windowDays))
+}
+
+// windowDuration переводит окно в днях в длительность
+func windowDuration(days int) time.Duration {
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// rotationSelector делит кандидатов на группы по округленному штрафу и заполняет места,
+// начиная с группы с наименьшим штрафом; внутри группы выбирает стратегия команды
+type rotationSelector struct {
+	inner    ReviewerSelector
+	memory   *RotationMemory
+	authorID string
+	window   time.Duration
+}
+
+// Select выбирает до n кандидатов с учетом штрафа за повторные пары
+func (s *rotationSelector) Select(candidates []*domain.User, n int) ([]*domain.User, error) {
+	if len(candidates) == 0 || n <= 0 {
+		return s.inner.Select(candidates, n)
+	}
+
+	ids := make([]string, 0, len(candidates))
+	for _, u := range candidates {
+		ids = append(ids, u.UserID)
+	}
+	penalties, err := s.memory.Penalties(s.authorID, ids, s.window)
+	if err != nil {
+		return nil, err
+	}
+
+	// давнее единичное ревью весит меньше 0.5 и не отделяет кандидата от новых пар
+	tiers := make(map[int][]*domain.User)
+	levels := []int{}
+	for _, u := range candidates {
+		level := int(math.Round(penalties[u.UserID]))
+		if _, ok := tiers[level]; !ok {
+			levels = append(levels, level)
+		}
+		tiers[level] = append(tiers[level], u)
+	}
+	sort.Ints(levels)
+
+	selected := make([]*domain.User, 0, n)
+	for _, level := range levels {
+		if len(selected) >= n {
+			break
+		}
+		picked, err := s.inner.Select(tiers[level], n-len(selected))
+		if err != nil {
+			return nil, err
+		}
+		selected = append(selected, picked...)
+	}
+	return selected, nil
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/domain"
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/repository/interfaces"
+	"go.uber.org/zap"
+)
+
+// ошибки восстановления снимка
+var (
+	ErrUnsupportedSnapshot = errors.New("UNSUPPORTED_SNAPSHOT_VERSION")
+	ErrInvalidSnapshot     = errors.New("INVALID_SNAPSHOT")
+)
+
+// SnapshotService - выгрузка и восстановление полного состояния сервиса
+type SnapshotService struct {
+	repo   interfaces.SnapshotRepo
+	logger *zap.SugaredLogger
+}
+
+// NewSnapshotService создает сервис снимков
+func NewSnapshotService(repo interfaces.SnapshotRepo, logger *zap.SugaredLogger) *SnapshotService {
+	return &SnapshotService{repo: repo, logger: logger}
+}
+
+// Export возвращает согласованный снимок команд, пользователей, PR и назначений
+func (s *SnapshotService) Export() (*domain.Snapshot, error) {
+	snapshot, err := s.repo.Export()
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Infof("snapshot exported: %d teams, %d users, %d pull requests",
+		len(snapshot.Teams), len(snapshot.Users), len(snapshot.PullRequests))
+	return snapshot, nil
+}
+
+// Restore проверяет снимок целиком и атомарно заменяет им текущее состояние
+func (s *SnapshotService) Restore(snapshot *domain.Snapshot) (*domain.SnapshotRestore, error) {
+	if snapshot.Version != domain.SnapshotVersion {
+		return nil, fmt.Errorf("%w: got %d, supported %d", ErrUnsupportedSnapshot, snapshot.Version, domain.SnapshotVersion)
+	}
+	if problems := validateSnapshot(snapshot); len(problems) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSnapshot, strings.Join(problems, "; "))
+	}
+
+	result, err := s.repo.Restore(snapshot)
+	if err != nil {
+		s.logger.Errorf("failed to restore snapshot: %v", err)
+		return nil, err
+	}
+	s.logger.Infof("snapshot from %s restored: %d teams, %d users, %d pull requests, %d users retired",
+		snapshot.CreatedAt.Format(time.RFC3339), result.Teams, result.Users, result.PullRequests, result.RetiredUsers)
+	return result, nil
+}
+
+// validateSnapshot проверяет уникальность и ссылки между частями снимка, чтобы восстановление
+// не падало на ограничениях БД посреди транзакции; заполняет пропущенные списки и время
+func validateSnapshot(snapshot *domain.Snapshot) []string {
+	problems := []string{}
+	fail := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	users := map[string]*domain.SnapshotUser{}
+	for i, u := range snapshot.Users {
+		switch {
+		case u == nil || u.UserID == "" || u.Username == "":
+			fail("users[%d]: user_id and username are required", i)
+			continue
+		case users[u.UserID] != nil:
+			fail("users[%d]: user %q is listed twice", i, u.UserID)
+			continue
+		case u.MaxOpenReviews != nil && *u.MaxOpenReviews < 0:
+			fail("users[%d]: max_open_reviews must be non-negative", i)
+		}
+		users[u.UserID] = u
+	}
+
+	teams := map[string]bool{}
+	for i, t := range snapshot.Teams {
+		if t == nil || t.TeamName == "" {
+			fail("teams[%d]: team_name is required", i)
+			continue
+		}
+		if teams[t.TeamName] {
+			fail("teams[%d]: team %q is listed twice", i, t.TeamName)
+		}
+		teams[t.TeamName] = true
+	}
+	for i, t := range snapshot.Teams {
+		if t == nil || t.TeamName == "" {
+			continue
+		}
+		if t.Settings != nil {
+			t.Settings.TeamName = t.TeamName
+			if err := validateSettings(t.Settings); err != nil {
+				fail("teams[%d]: invalid settings", i)
+			}
+			for _, fallback := range t.Settings.FallbackTeams {
+				if !teams[fallback] {
+					fail("teams[%d]: unknown fallback team %q", i, fallback)
+				}
+			}
+		}
+		members := map[string]bool{}
+		for _, m := range t.Members {
+			switch {
+			case m == nil || users[m.UserID] == nil:
+				fail("teams[%d]: member is not in users", i)
+			case users[m.UserID].DeletedAt != nil:
+				fail("teams[%d]: deleted user %q cannot be a member", i, m.UserID)
+			case members[m.UserID]:
+				fail("teams[%d]: member %q is listed twice", i, m.UserID)
+			case !domain.IsValidRole(m.Role):
+				fail("teams[%d]: unknown role %q", i, m.Role)
+			default:
+				members[m.UserID] = true
+			}
+		}
+	}
+	for _, u := range users {
+		if u.TeamName != "" && !teams[u.TeamName] {
+			fail("user %q: unknown team %q", u.UserID, u.TeamName)
+		}
+	}
+
+	prs := map[string]bool{}
+	for i, pr := range snapshot.PullRequests {
+		if pr == nil || pr.PRID == "" || pr.PRName == "" {
+			fail("pull_requests[%d]: pull_request_id and pull_request_name are required", i)
+			continue
+		}
+		if prs[pr.PRID] {
+			fail("pull_requests[%d]: pull request %q is listed twice", i, pr.PRID)
+		}
+		prs[pr.PRID] = true
+
+		if users[pr.AuthorID] == nil {
+			fail("pull_requests[%d]: author %q is not in users", i, pr.AuthorID)
+		}
+		if pr.TeamName != "" && !teams[pr.TeamName] {
+			fail("pull_requests[%d]: unknown team %q", i, pr.TeamName)
+		}
+		switch pr.Status {
+		case domain.StatusOpen, domain.StatusMerged, domain.StatusClosed:
+		default:
+			fail("pull_requests[%d]: unknown status %q", i, pr.Status)
+		}
+		if pr.CreatedAt.IsZero() {
+			pr.CreatedAt = snapshot.CreatedAt
+		}
+		if slices.Contains(pr.ChangedFiles, "") {
+			fail("pull_requests[%d]: changed_files must not contain empty paths", i)
+		}
+		if slices.Contains(pr.Labels, "") {
+			fail("pull_requests[%d]: labels must not be empty", i)
+		}
+
+		reviewers := map[string]bool{}
+		for _, r := range pr.Reviewers {
+			switch {
+			case r == nil || users[r.UserID] == nil:
+				fail("pull_requests[%d]: reviewer is not in users", i)
+				continue
+			case reviewers[r.UserID]:
+				fail("pull_requests[%d]: reviewer %q is listed twice", i, r.UserID)
+			case r.Verdict != "" && !domain.IsValidVerdict(r.Verdict):
+				fail("pull_requests[%d]: unknown verdict %q", i, r.Verdict)
+			}
+			reviewers[r.UserID] = true
+			if r.AssignedAt.IsZero() {
+				r.AssignedAt = pr.CreatedAt
+			}
+		}
+	}
+	return problems
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/domain"
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/teamfile"
+)
+
+// ошибки импорта и экспорта команд
+var (
+	ErrInvalidTeamFile = errors.New("INVALID_TEAM_FILE")
+	ErrUnknownFormat   = errors.New("UNKNOWN_FORMAT")
+)
+
+// ImportTeams разбирает и целиком проверяет файл команд, после чего применяет его одной транзакцией;
+// при dryRun возвращает отчет о создании, обновлении и переводе пользователей без сохранения
+func (s *TeamService) ImportTeams(format string, content []byte, dryRun bool) (*domain.TeamImportReport, error) {
+	if !teamfile.IsValidFormat(format) {
+		return nil, ErrUnknownFormat
+	}
+	teams, err := teamfile.Parse(format, content)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTeamFile, err)
+	}
+
+	report, err := s.repo.ImportTeams(teams, dryRun)
+	if err != nil {
+		s.logger.Errorf("failed to import teams: %v", err)
+		return nil, err
+	}
+	s.logger.Infof("teams imported (dry run: %v): %d teams created, %d users created, %d updated, %d moved",
+		dryRun, len(report.TeamsCreated), len(report.UsersCreated), len(report.UsersUpdated), len(report.UsersMoved))
+	return report, nil
+}
+
+// ExportTeams выгружает все команды в формате, который принимает ImportTeams
+func (s *TeamService) ExportTeams(format string) ([]byte, error) {
+	if !teamfile.IsValidFormat(format) {
+		return nil, ErrUnknownFormat
+	}
+	teams, err := s.repo.ExportTeams()
+	if err != nil {
+		return nil, err
+	}
+	return teamfile.Encode(format, teams)
+}
@@ -5,12 +5,24 @@ import (
 	"errors"
 
 	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/domain"
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/repository"
 	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/repository/interfaces"
 	"go.uber.org/zap"
 )
 
 var ErrTeamExists = errors.New("TEAM_EXISTS")
 var ErrTeamNotFound = errors.New("NOT_FOUND")
+var ErrInvalidSettings = errors.New("INVALID_SETTINGS")
+
+// ошибки управления составом команды
+var (
+	ErrNotTeamMember     = errors.New("NOT_TEAM_MEMBER")
+	ErrAlreadyTeamMember = errors.New("ALREADY_MEMBER")
+	ErrInvalidRole       = errors.New("INVALID_ROLE")
+)
+
+// максимальное число ревьюверов, которое можно задать команде
+const MaxReviewersCount = 10
 
 // TeamService - сервис пользователей
 type TeamService struct {
@@ -33,6 +45,9 @@ func (s *TeamService) CreateTeam(team *domain.Team, members []*domain.User) erro
 	if exists {
 		return ErrTeamExists
 	}
+	if err := validateRoles(members); err != nil {
+		return err
+	}
 
 	return s.repo.CreateTeamWithUsers(team.TeamName, members)
 }
@@ -65,6 +80,47 @@ func (s *TeamService) GetTeam(teamName string) (*domain.Team, error) {
 	}, nil
 }
 
+// GetSettings возвращает настройки команды или настройки по умолчанию
+func (s *TeamService) GetSettings(teamName string) (*domain.TeamSettings, error) {
+	settings, err := s.repo.GetSettings(teamName)
+	if err != nil {
+		if errors.Is(err, repository.ErrTeamSettingsNotFound) {
+			return domain.DefaultTeamSettings(teamName), nil
+		}
+		s.logger.Errorf("failed to get settings for team %s: %v", teamName, err)
+		return nil, err
+	}
+	return settings, nil
+}
+
+// UpdateSettings проверяет и сохраняет настройки команды
+func (s *TeamService) UpdateSettings(settings *domain.TeamSettings) error {
+	if err := validateSettings(settings); err != nil {
+		return err
+	}
+
+	exists, err := s.TeamExists(settings.TeamName)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrTeamNotFound
+	}
+
+	// резервные команды должны существовать
+	for _, fallback := range settings.FallbackTeams {
+		exists, err := s.TeamExists(fallback)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrInvalidSettings
+		}
+	}
+
+	return s.repo.UpsertSettings(settings)
+}
+
 // GetStats получает статистику по созданным командам
 func (s *TeamService) GetStats() (map[string]interface{}, error) {
 	stats := make(map[string]interface{})
@@ -89,3 +145,140 @@ func (s *TeamService) GetStats() (map[string]interface{}, error) {
 
 	return stats, nil
 }
+
+// AddMembers добавляет в существующую команду новых пользователей или участников других команд
+func (s *TeamService) AddMembers(teamName string, members []*domain.User) error {
+	if err := s.ensureExists(teamName); err != nil {
+		return err
+	}
+	if err := validateRoles(members); err != nil {
+		return err
+	}
+	return mapMembershipError(s.repo.AddMembers(teamName, members))
+}
+
+// RemoveMember выводит пользователя из команды, его открытые ревью переназначает вызывающий
+func (s *TeamService) RemoveMember(teamName, userID string) error {
+	if err := s.ensureExists(teamName); err != nil {
+		return err
+	}
+	return mapMembershipError(s.repo.RemoveMember(teamName, userID))
+}
+
+// MoveMember переводит пользователя из основной команды в другую существующую и возвращает прежнюю
+func (s *TeamService) MoveMember(userID, teamName string) (string, error) {
+	if err := s.ensureExists(teamName); err != nil {
+		return "", err
+	}
+	previous, err := s.repo.MoveMember(userID, teamName)
+	return previous, mapMembershipError(err)
+}
+
+// SetMemberRole назначает участнику команды роль и возвращает прежнюю
+func (s *TeamService) SetMemberRole(teamName, userID, role string) (string, error) {
+	if role == "" || !domain.IsValidRole(role) {
+		return "", ErrInvalidRole
+	}
+	if err := s.ensureExists(teamName); err != nil {
+		return "", err
+	}
+	previous, err := s.repo.SetMemberRole(teamName, userID, role)
+	return previous, mapMembershipError(err)
+}
+
+// RenameTeam переименовывает команду вместе с ее участниками и настройками
+func (s *TeamService) RenameTeam(oldName, newName string) error {
+	if err := s.ensureExists(oldName); err != nil {
+		return err
+	}
+	exists, err := s.TeamExists(newName)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrTeamExists
+	}
+	return mapMembershipError(s.repo.RenameTeam(oldName, newName))
+}
+
+// DeleteTeam удаляет команду и возвращает ее бывших участников, их открытые ревью переназначает вызывающий
+func (s *TeamService) DeleteTeam(teamName string) ([]string, error) {
+	if err := s.ensureExists(teamName); err != nil {
+		return nil, err
+	}
+	removed, err := s.repo.DeleteTeam(teamName)
+	return removed, mapMembershipError(err)
+}
+
+// validateSettings проверяет значения настроек без обращения к БД, повторы резервных команд тоже
+func validateSettings(settings *domain.TeamSettings) error {
+	if settings.ReviewersCount < 0 || settings.ReviewersCount > MaxReviewersCount ||
+		settings.MinReviewers < 0 || settings.MinReviewers > settings.ReviewersCount ||
+		settings.MaxReviewers < 0 || settings.MaxReviewers > MaxReviewersCount ||
+		(settings.MaxReviewers > 0 && settings.MaxReviewers < settings.ReviewersCount) ||
+		settings.ApprovalsRequired < 0 || settings.ApprovalsRequired > MaxReviewersCount ||
+		settings.MaxOpenReviews < 0 || settings.ReviewSLAHours < 0 ||
+		settings.RotationWindowDays < 0 || settings.RotationWindowDays > MaxRotationWindowDays {
+		return ErrInvalidSettings
+	}
+	if settings.Strategy != "" && !IsKnownStrategy(settings.Strategy) {
+		return ErrInvalidSettings
+	}
+
+	// одобрений не может требоваться больше, чем ревьюверов может оказаться в PR
+	ceiling := settings.MaxReviewers
+	if ceiling == 0 {
+		ceiling = settings.ReviewersCount
+	}
+	if settings.ApprovalsRequired > ceiling {
+		return ErrInvalidSettings
+	}
+
+	seen := map[string]bool{settings.TeamName: true}
+	for _, fallback := range settings.FallbackTeams {
+		if seen[fallback] {
+			return ErrInvalidSettings
+		}
+		seen[fallback] = true
+	}
+	return nil
+}
+
+// validateRoles проверяет роли участников, пустая роль означает member
+func validateRoles(members []*domain.User) error {
+	for _, m := range members {
+		if !domain.IsValidRole(m.Role) {
+			return ErrInvalidRole
+		}
+	}
+	return nil
+}
+
+// ensureExists возвращает ErrTeamNotFound, если команды нет
+func (s *TeamService) ensureExists(teamName string) error {
+	exists, err := s.TeamExists(teamName)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrTeamNotFound
+	}
+	return nil
+}
+
+// mapMembershipError переводит ошибки репозитория в ошибки сервиса
+func mapMembershipError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, repository.ErrTeamNotFound):
+		return ErrTeamNotFound
+	case errors.Is(err, repository.ErrMemberNotFound):
+		return ErrUserNotFound
+	case errors.Is(err, repository.ErrNotTeamMember):
+		return ErrNotTeamMember
+	case errors.Is(err, repository.ErrAlreadyTeamMember):
+		return ErrAlreadyTeamMember
+	}
+	return err
+}
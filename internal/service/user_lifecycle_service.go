@@ -0,0 +1,251 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/domain"
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/repository"
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/repository/interfaces"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrUserExists             = errors.New("USER_EXISTS")
+	ErrInvalidUser            = errors.New("INVALID_USER")
+	ErrTransferTargetNotFound = errors.New("TRANSFER_TARGET_NOT_FOUND")
+
+	// план передачи ревью устаревал на каждой попытке из-за параллельных изменений
+	ErrHandoffConflict = errors.New("HANDOFF_CONFLICT")
+
+	errStaleHandoff = errors.New("review handoff plan is stale")
+)
+
+// сколько раз Delete перестраивает устаревший план передачи ревью
+const handoffAttempts = 3
+
+// TeamLookup проверяет существование команды
+type TeamLookup interface {
+	TeamExists(teamName string) (bool, error)
+}
+
+// ReviewHandoffPlanner подбирает замены на открытые ревью пользователя и переназначает
+// ревью пользователей, выведенных из команды
+type ReviewHandoffPlanner interface {
+	PlanReviewHandoff(userID string) ([]*domain.ReviewHandoff, error)
+	ReassignReviewsOfRemovedUsers(teamName string, userIDs []string) (int, error)
+}
+
+// UserLifecycleService создает, изменяет и удаляет пользователей
+type UserLifecycleService struct {
+	db      *sql.DB
+	users   interfaces.UserRepo
+	prs     interfaces.PullRequestRepo
+	teams   TeamLookup
+	planner ReviewHandoffPlanner
+	logger  *zap.SugaredLogger
+}
+
+// NewUserLifecycleService создает сервис жизненного цикла пользователей
+func NewUserLifecycleService(
+	db *sql.DB,
+	users interfaces.UserRepo,
+	prs interfaces.PullRequestRepo,
+	teams TeamLookup,
+	planner ReviewHandoffPlanner,
+	logger *zap.SugaredLogger,
+) *UserLifecycleService {
+	return &UserLifecycleService{db: db, users: users, prs: prs, teams: teams, planner: planner, logger: logger}
+}
+
+// Create создает пользователя в существующей команде
+func (s *UserLifecycleService) Create(user *domain.User) (*domain.User, error) {
+	if user.UserID == "" || user.Username == "" {
+		return nil, ErrInvalidUser
+	}
+	if err := s.ensureTeam(user.TeamName); err != nil {
+		return nil, err
+	}
+
+	err := repository.RunInTx(s.db, s.logger, func(tx *sql.Tx) error {
+		return s.users.Insert(tx, user)
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrUserExists) {
+			return nil, ErrUserExists
+		}
+		return nil, err
+	}
+	return s.users.GetByID(user.UserID)
+}
+
+// Update меняет имя и команду пользователя, nil оставляет поле прежним. При смене команды его
+// открытые ревью в PR прежней команды после сохранения переназначаются, как при выводе из команды;
+// ошибка переназначения не отменяет изменение и возвращается в ReassignErr
+func (s *UserLifecycleService) Update(userID string, username, teamName *string) (*domain.UserUpdate, error) {
+	user, err := s.getExisting(userID)
+	if err != nil {
+		return nil, err
+	}
+	previous := user.TeamName
+
+	if username != nil {
+		if *username == "" {
+			return nil, ErrInvalidUser
+		}
+		user.Username = *username
+	}
+	if teamName != nil && *teamName != user.TeamName {
+		if err := s.ensureTeam(*teamName); err != nil {
+			return nil, err
+		}
+		user.TeamName = *teamName
+	}
+
+	err = repository.RunInTx(s.db, s.logger, func(tx *sql.Tx) error {
+		return s.users.Update(tx, user)
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	result := &domain.UserUpdate{}
+	if previous != "" && previous != user.TeamName {
+		result.ReassignedReviews, result.ReassignErr = s.planner.ReassignReviewsOfRemovedUsers(previous, []string{userID})
+		if result.ReassignErr != nil {
+			s.logger.Errorf("failed to reassign reviews of %s moved from team %s: %v", userID, previous, result.ReassignErr)
+		}
+	}
+	if result.User, err = s.users.GetByID(userID); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Delete удаляет пользователя одной транзакцией: его открытые ревью переходят к участникам команды
+// или снимаются, незавершенные PR переходят к transferTo либо помечаются orphaned. Если план передачи
+// ревью устарел из-за параллельных изменений, он строится заново
+func (s *UserLifecycleService) Delete(userID, transferTo string) (*domain.UserDeletion, error) {
+	if _, err := s.getExisting(userID); err != nil {
+		return nil, err
+	}
+	if transferTo != "" {
+		if transferTo == userID {
+			return nil, ErrInvalidUser
+		}
+		if _, err := s.getExisting(transferTo); err != nil {
+			return nil, ErrTransferTargetNotFound
+		}
+	}
+
+	for attempt := 1; ; attempt++ {
+		result, err := s.delete(userID, transferTo)
+		if !errors.Is(err, errStaleHandoff) {
+			return result, err
+		}
+		if attempt == handoffAttempts {
+			return nil, ErrHandoffConflict
+		}
+		s.logger.Warnf("review handoff plan for %s went stale, replanning (attempt %d)", userID, attempt)
+	}
+}
+
+// delete подбирает замены заранее и применяет их вместе с удалением; каждая передача
+// перепроверяется под блокировкой PR и получателя, устаревший план дает errStaleHandoff
+func (s *UserLifecycleService) delete(userID, transferTo string) (*domain.UserDeletion, error) {
+	plan, err := s.planner.PlanReviewHandoff(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.UserDeletion{UserID: userID}
+	err = repository.RunInTx(s.db, s.logger, func(tx *sql.Tx) error {
+		for _, handoff := range plan {
+			assigned, err := s.prs.LockReviewForHandoff(tx, handoff.PRID, userID)
+			if err != nil {
+				return err
+			}
+			// PR закрыт или ревью уже передано - делать нечего
+			if !assigned {
+				continue
+			}
+
+			if handoff.ToUserID == "" {
+				if err := s.prs.UnassignReviewer(tx, handoff.PRID, userID); err != nil {
+					return err
+				}
+				result.UnassignedReviews++
+				continue
+			}
+
+			// загрузка получателя включает передачи, уже сделанные в этой транзакции
+			available, openReviews, err := s.prs.LockHandoffTarget(tx, handoff.PRID, handoff.ToUserID)
+			if err != nil {
+				return err
+			}
+			if !available || (handoff.ToLimit > 0 && openReviews >= handoff.ToLimit) {
+				return errStaleHandoff
+			}
+			if err := s.prs.HandOffReviewer(tx, handoff.PRID, userID, handoff.ToUserID); err != nil {
+				return err
+			}
+			result.ReassignedReviews++
+		}
+
+		// пока план строился, пользователю могли назначить новые ревью
+		remaining, err := s.prs.CountOpenReviewsOf(tx, userID)
+		if err != nil {
+			return err
+		}
+		if remaining > 0 {
+			return errStaleHandoff
+		}
+
+		if transferTo != "" {
+			result.TransferredPRs, err = s.prs.TransferAuthorship(tx, userID, transferTo)
+		} else {
+			result.OrphanedPRs, err = s.prs.MarkOrphaned(tx, userID)
+		}
+		if err != nil {
+			return err
+		}
+
+		return s.users.Delete(tx, userID)
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	s.logger.Infof("user %s deleted: %+v", userID, result)
+	return result, nil
+}
+
+// getExisting возвращает неудаленного пользователя или ErrUserNotFound
+func (s *UserLifecycleService) getExisting(userID string) (*domain.User, error) {
+	user, err := s.users.GetByID(userID)
+	if err != nil || user.Deleted {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
+
+// ensureTeam проверяет, что команда указана и существует
+func (s *UserLifecycleService) ensureTeam(teamName string) error {
+	if teamName == "" {
+		return ErrInvalidUser
+	}
+	exists, err := s.teams.TeamExists(teamName)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrTeamNotFound
+	}
+	return nil
+}
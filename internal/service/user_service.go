@@ -11,6 +11,9 @@ import (
 // ErrUserNotFound возвращается, если пользователь не найден
 var ErrUserNotFound = fmt.Errorf("user not found")
 
+// ErrInvalidCapacity возвращается при отрицательном лимите открытых ревью
+var ErrInvalidCapacity = fmt.Errorf("INVALID_CAPACITY")
+
 // UserService для работы с пользователями
 type UserService struct {
 	repo   interfaces.UserRepo
@@ -51,6 +54,78 @@ func (s *UserService) SetIsActive(userID string, isActive bool) (*domain.User, e
 	return user, nil
 }
 
+// SetTags заменяет теги пользователя и возвращает его вместе с новыми тегами
+func (s *UserService) SetTags(userID string, tags []string) (*domain.User, error) {
+	user, err := s.repo.GetByID(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	tags = domain.NormalizeTags(tags)
+	if err := s.repo.SetTags(userID, tags); err != nil {
+		s.logger.Warnf("failed to set tags for user %s: %v", userID, err)
+		return nil, err
+	}
+	user.Tags = tags
+
+	s.logger.Infof("user %s tags updated to %v", userID, tags)
+	return user, nil
+}
+
+// SetMaxOpenReviews задает личный лимит открытых ревью пользователя, nil - лимит команды
+func (s *UserService) SetMaxOpenReviews(userID string, limit *int) (*domain.User, error) {
+	if limit != nil && *limit < 0 {
+		return nil, ErrInvalidCapacity
+	}
+	user, err := s.repo.GetByID(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	if err := s.repo.SetMaxOpenReviews(userID, limit); err != nil {
+		s.logger.Warnf("failed to set max_open_reviews for user %s: %v", userID, err)
+		return nil, err
+	}
+	user.MaxOpenReviews = limit
+
+	s.logger.Infof("user %s max_open_reviews updated to %v", userID, limit)
+	return user, nil
+}
+
+// ListReviewLimits возвращает личные лимиты открытых ревью пользователей
+func (s *UserService) ListReviewLimits(userIDs []string) (map[string]int, error) {
+	limits, err := s.repo.ListReviewLimits(userIDs)
+	if err != nil {
+		s.logger.Warnf("failed to list review limits: %v", err)
+		return nil, err
+	}
+	return limits, nil
+}
+
+// ListTagsByUsers возвращает теги пользователей
+func (s *UserService) ListTagsByUsers(userIDs []string) (map[string][]string, error) {
+	tags, err := s.repo.ListTagsByUsers(userIDs)
+	if err != nil {
+		s.logger.Warnf("failed to list tags of users: %v", err)
+		return nil, err
+	}
+	return tags, nil
+}
+
+// GetHistory возвращает историю пользователя
+func (s *UserService) GetHistory(userID string) ([]*domain.PREvent, error) {
+	if _, err := s.repo.GetByID(userID); err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	events, err := s.repo.ListEvents(userID)
+	if err != nil {
+		s.logger.Warnf("failed to get history of user %s: %v", userID, err)
+		return nil, err
+	}
+	return events, nil
+}
+
 // DeactivateTeam массово деактивирует всех пользователей команды
 func (s *UserService) DeactivateTeam(teamName string) error {
 	return s.repo.SetIsActiveByTeam(teamName, false)
@@ -76,6 +151,16 @@ func (s *UserService) ListActiveByTeam(teamName string) ([]*domain.User, error)
 	return users, nil
 }
 
+// ListAvailableByIDs возвращает активных и не отсутствующих пользователей из списка
+func (s *UserService) ListAvailableByIDs(userIDs []string) ([]*domain.User, error) {
+	users, err := s.repo.ListAvailableByIDs(userIDs)
+	if err != nil {
+		s.logger.Warnf("failed to list available users: %v", err)
+		return nil, err
+	}
+	return users, nil
+}
+
 // ListReviewPR возвращает PR, где пользователь является ревьюером
 func (s *UserService) ListReviewPR(userID string) ([]*domain.PullRequestShort, error) {
 	// сначала проверяем, что пользователь существует
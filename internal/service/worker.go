@@ -0,0 +1,25 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// runEvery вызывает job раз в interval до отмены контекста, ошибки только логируются
+func runEvery(ctx context.Context, interval time.Duration, logger *zap.SugaredLogger, name string, job func() error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := job(); err != nil {
+				logger.Warnf("%s failed: %v", name, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package teamfile
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/domain"
+	"github.com/goccy/go-yaml"
+)
+
+// форматы файла команд
+const (
+	FormatCSV  = "csv"
+	FormatYAML = "yaml"
+)
+
+// заголовок CSV: одна строка на участника основной команды
+var csvHeader = []string{"team", "user_id", "username", "is_active"}
+
+// yamlFile - YAML представление: список команд с участниками
+type yamlFile struct {
+	Teams []yamlTeam `yaml:"teams"`
+}
+
+type yamlTeam struct {
+	TeamName string       `yaml:"team_name"`
+	Members  []yamlMember `yaml:"members"`
+}
+
+type yamlMember struct {
+	UserID   string `yaml:"user_id"`
+	Username string `yaml:"username"`
+	IsActive *bool  `yaml:"is_active"`
+}
+
+// IsValidFormat проверяет, что формат поддерживается
+func IsValidFormat(format string) bool {
+	return format == FormatCSV || format == FormatYAML
+}
+
+// Parse разбирает файл команд и проверяет его целиком: ошибки всех строк возвращаются сразу.
+// CSV: `team,user_id,username,is_active`, строка заголовка необязательна.
+// YAML: `teams: [ { team_name, members: [ { user_id, username, is_active } ] } ]`.
+// Каждый пользователь может встречаться в файле один раз.
+func Parse(format string, content []byte) ([]*domain.Team, error) {
+	switch format {
+	case FormatCSV:
+		return parseCSV(content)
+	case FormatYAML:
+		return parseYAML(content)
+	}
+	return nil, fmt.Errorf("unsupported format %q", format)
+}
+
+// Encode записывает команды в формате, который принимает Parse
+func Encode(format string, teams []*domain.Team) ([]byte, error) {
+	switch format {
+	case FormatCSV:
+		return encodeCSV(teams)
+	case FormatYAML:
+		return encodeYAML(teams)
+	}
+	return nil, fmt.Errorf("unsupported format %q", format)
+}
+
+func parseCSV(content []byte) ([]*domain.Team, error) {
+	reader := csv.NewReader(bytes.NewReader(content))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	b := newBuilder()
+	first := true
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		lineNum, _ := reader.FieldPos(0)
+
+		header := first
+		first = false
+		if header && strings.EqualFold(strings.TrimSpace(record[0]), csvHeader[0]) {
+			continue
+		}
+		if len(record) != len(csvHeader) {
+			b.fail("line %d: expected %d fields, got %d", lineNum, len(csvHeader), len(record))
+			continue
+		}
+
+		isActive, err := strconv.ParseBool(strings.TrimSpace(record[3]))
+		if err != nil {
+			b.fail("line %d: is_active must be true or false, got %q", lineNum, record[3])
+			continue
+		}
+		b.add(fmt.Sprintf("line %d", lineNum), record[0], &domain.User{
+			UserID:   strings.TrimSpace(record[1]),
+			Username: strings.TrimSpace(record[2]),
+			IsActive: isActive,
+		})
+	}
+	return b.result()
+}
+
+func parseYAML(content []byte) ([]*domain.Team, error) {
+	var file yamlFile
+	if err := yaml.UnmarshalWithOptions(content, &file, yaml.Strict()); err != nil {
+		return nil, err
+	}
+
+	b := newBuilder()
+	for i, t := range file.Teams {
+		where := fmt.Sprintf("teams[%d]", i)
+		if b.seenTeams[strings.TrimSpace(t.TeamName)] {
+			b.fail("%s: team %q is listed twice", where, t.TeamName)
+			continue
+		}
+		if b.team(where, t.TeamName) == nil {
+			continue
+		}
+
+		for j, m := range t.Members {
+			memberWhere := fmt.Sprintf("%s.members[%d]", where, j)
+			if m.IsActive == nil {
+				b.fail("%s: is_active is required", memberWhere)
+				continue
+			}
+			b.add(memberWhere, t.TeamName, &domain.User{
+				UserID:   strings.TrimSpace(m.UserID),
+				Username: strings.TrimSpace(m.Username),
+				IsActive: *m.IsActive,
+			})
+		}
+	}
+	return b.result()
+}
+
+func encodeCSV(teams []*domain.Team) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.Write(csvHeader); err != nil {
+		return nil, err
+	}
+	for _, t := range teams {
+		for _, u := range t.Members {
+			if err := writer.Write([]string{t.TeamName, u.UserID, u.Username, strconv.FormatBool(u.IsActive)}); err != nil {
+				return nil, err
+			}
+		}
+	}
+	writer.Flush()
+	return buf.Bytes(), writer.Error()
+}
+
+func encodeYAML(teams []*domain.Team) ([]byte, error) {
+	file := yamlFile{Teams: make([]yamlTeam, 0, len(teams))}
+	for _, t := range teams {
+		team := yamlTeam{TeamName: t.TeamName, Members: make([]yamlMember, 0, len(t.Members))}
+		for _, u := range t.Members {
+			isActive := u.IsActive
+			team.Members = append(team.Members, yamlMember{UserID: u.UserID, Username: u.Username, IsActive: &isActive})
+		}
+		file.Teams = append(file.Teams, team)
+	}
+	return yaml.Marshal(file)
+}
+
+// builder собирает команды в порядке первого упоминания и копит ошибки проверки
+type builder struct {
+	teams     []*domain.Team
+	byName    map[string]*domain.Team
+	seenTeams map[string]bool
+	seenUsers map[string]string
+	problems  []string
+}
+
+func newBuilder() *builder {
+	return &builder{byName: map[string]*domain.Team{}, seenTeams: map[string]bool{}, seenUsers: map[string]string{}}
+}
+
+func (b *builder) fail(format string, args ...any) {
+	b.problems = append(b.problems, fmt.Sprintf(format, args...))
+}
+
+// team возвращает команду по имени, создавая ее при первом упоминании
+func (b *builder) team(where, name string) *domain.Team {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		b.fail("%s: team is required", where)
+		return nil
+	}
+	b.seenTeams[name] = true
+	if t, ok := b.byName[name]; ok {
+		return t
+	}
+	t := &domain.Team{TeamName: name, Members: []*domain.User{}}
+	b.byName[name] = t
+	b.teams = append(b.teams, t)
+	return t
+}
+
+func (b *builder) add(where, teamName string, u *domain.User) {
+	t := b.team(where, teamName)
+	switch {
+	case t == nil:
+		return
+	case u.UserID == "":
+		b.fail("%s: user_id is required", where)
+		return
+	case u.Username == "":
+		b.fail("%s: username is required", where)
+		return
+	}
+	if first, ok := b.seenUsers[u.UserID]; ok {
+		b.fail("%s: user %q is already listed at %s", where, u.UserID, first)
+		return
+	}
+	b.seenUsers[u.UserID] = where
+
+	u.TeamName = t.TeamName
+	t.Members = append(t.Members, u)
+}
+
+func (b *builder) result() ([]*domain.Team, error) {
+	if len(b.problems) > 0 {
+		return nil, errors.New(strings.Join(b.problems, "; "))
+	}
+	if len(b.teams) == 0 {
+		return nil, errors.New("file contains no teams")
+	}
+	return b.teams, nil
+}
@@ -0,0 +1,139 @@
+package teamfile
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/domain"
+)
+
+// тестируем разбор файла команд и ошибки проверки
+func TestParse(t *testing.T) {
+	cases := []struct {
+		name    string
+		format  string
+		content string
+		want    map[string][]string
+		wantErr string
+	}{
+		{"csv_с_заголовком", FormatCSV, "team,user_id,username,is_active\nbackend,u1,Alice,true\nbackend,u2,Bob,false\n",
+			map[string][]string{"backend": {"u1", "u2"}}, ""},
+		{"csv_без_заголовка", FormatCSV, "backend,u1,Alice,true\nfrontend,u2,Bob,true\n",
+			map[string][]string{"backend": {"u1"}, "frontend": {"u2"}}, ""},
+		{"csv_заголовок_в_другом_регистре", FormatCSV, "Team,user_id,username,is_active\nbackend,u1,Alice,true\n",
+			map[string][]string{"backend": {"u1"}}, ""},
+		{"csv_повтор_пользователя", FormatCSV, "backend,u1,Alice,true\nfrontend,u1,Alice,true\n",
+			nil, `line 2: user "u1" is already listed at line 1`},
+		{"csv_неверный_is_active", FormatCSV, "backend,u1,Alice,yes\n",
+			nil, `line 1: is_active must be true or false, got "yes"`},
+		{"csv_лишние_поля", FormatCSV, "backend,u1,Alice,true,extra\n",
+			nil, "line 1: expected 4 fields, got 5"},
+		{"csv_пустой_user_id", FormatCSV, "backend,,Alice,true\n",
+			nil, "line 1: user_id is required"},
+		{"csv_все_ошибки_сразу", FormatCSV, "backend,u1,Alice,maybe\n,u2,Bob,true\n",
+			nil, "line 1: is_active must be true or false, got \"maybe\"; line 2: team is required"},
+		{"csv_пустой_файл", FormatCSV, "team,user_id,username,is_active\n",
+			nil, "file contains no teams"},
+		{"yaml_команды", FormatYAML, `
+teams:
+  - team_name: backend
+    members:
+      - { user_id: u1, username: Alice, is_active: true }
+      - { user_id: u2, username: Bob, is_active: false }
+  - team_name: frontend
+    members: []
+`, map[string][]string{"backend": {"u1", "u2"}, "frontend": {}}, ""},
+		{"yaml_повтор_команды", FormatYAML, `
+teams:
+  - team_name: backend
+    members:
+      - { user_id: u1, username: Alice, is_active: true }
+  - team_name: backend
+    members:
+      - { user_id: u2, username: Bob, is_active: true }
+`, nil, `teams[1]: team "backend" is listed twice`},
+		{"yaml_повтор_пользователя", FormatYAML, `
+teams:
+  - team_name: backend
+    members:
+      - { user_id: u1, username: Alice, is_active: true }
+  - team_name: frontend
+    members:
+      - { user_id: u1, username: Alice, is_active: true }
+`, nil, `teams[1].members[0]: user "u1" is already listed at teams[0].members[0]`},
+		{"yaml_без_is_active", FormatYAML, `
+teams:
+  - team_name: backend
+    members:
+      - { user_id: u1, username: Alice }
+`, nil, "teams[0].members[0]: is_active is required"},
+		{"yaml_неизвестное_поле", FormatYAML, `
+teams:
+  - team_name: backend
+    lead: u1
+    members: []
+`, nil, "unknown field"},
+		{"неизвестный_формат", "json", "{}", nil, `unsupported format "json"`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			teams, err := Parse(c.format, []byte(c.content))
+			if c.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), c.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", c.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := membersByTeam(teams); !reflect.DeepEqual(got, c.want) {
+				t.Fatalf("expected teams %v, got %v", c.want, got)
+			}
+		})
+	}
+}
+
+// тестируем, что Parse читает то, что записал Encode
+func TestEncodeParseRoundTrip(t *testing.T) {
+	teams := []*domain.Team{
+		{TeamName: "backend", Members: []*domain.User{
+			{UserID: "u1", Username: "Alice", TeamName: "backend", IsActive: true},
+			{UserID: "u2", Username: "Bob, Jr.", TeamName: "backend", IsActive: false},
+		}},
+		{TeamName: "frontend", Members: []*domain.User{
+			{UserID: "u3", Username: "Carol", TeamName: "frontend", IsActive: true},
+		}},
+	}
+
+	for _, format := range []string{FormatCSV, FormatYAML} {
+		t.Run(format, func(t *testing.T) {
+			content, err := Encode(format, teams)
+			if err != nil {
+				t.Fatalf("unexpected encode error: %v", err)
+			}
+			got, err := Parse(format, content)
+			if err != nil {
+				t.Fatalf("unexpected parse error: %v\n%s", err, content)
+			}
+			if !reflect.DeepEqual(got, teams) {
+				t.Fatalf("round trip mismatch, file:\n%s", content)
+			}
+		})
+	}
+}
+
+// membersByTeam возвращает id участников по командам
+func membersByTeam(teams []*domain.Team) map[string][]string {
+	result := map[string][]string{}
+	for _, team := range teams {
+		ids := []string{}
+		for _, u := range team.Members {
+			ids = append(ids, u.UserID)
+		}
+		result[team.TeamName] = ids
+	}
+	return result
+}
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS team_settings (
+    team_name TEXT PRIMARY KEY REFERENCES teams(team_name) ON DELETE CASCADE,
+    reviewers_count INT NOT NULL DEFAULT 2 CHECK (reviewers_count >= 0),
+    min_reviewers INT NOT NULL DEFAULT 0 CHECK (min_reviewers >= 0),
+    strategy TEXT NOT NULL DEFAULT '',
+    allow_cross_team BOOLEAN NOT NULL DEFAULT FALSE,
+    CHECK (min_reviewers <= reviewers_count)
+);
\ No newline at end of file
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS team_fallbacks (
+    team_name TEXT REFERENCES teams(team_name) ON DELETE CASCADE,
+    fallback_team TEXT REFERENCES teams(team_name) ON DELETE CASCADE,
+    position INT NOT NULL,
+    PRIMARY KEY (team_name, fallback_team),
+    CHECK (team_name <> fallback_team)
+);
\ No newline at end of file
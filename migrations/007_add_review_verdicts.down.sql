@@ -0,0 +1,5 @@
+ALTER TABLE team_settings DROP COLUMN IF EXISTS approvals_required;
+
+ALTER TABLE pull_request_reviewers
+    DROP COLUMN IF EXISTS reviewed_at,
+    DROP COLUMN IF EXISTS verdict;
\ No newline at end of file
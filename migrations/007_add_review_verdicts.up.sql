@@ -0,0 +1,6 @@
+ALTER TABLE pull_request_reviewers
+    ADD COLUMN IF NOT EXISTS verdict TEXT CHECK (verdict IN ('APPROVED', 'CHANGES_REQUESTED', 'COMMENTED')),
+    ADD COLUMN IF NOT EXISTS reviewed_at TIMESTAMP;
+
+ALTER TABLE team_settings
+    ADD COLUMN IF NOT EXISTS approvals_required INT NOT NULL DEFAULT 0 CHECK (approvals_required >= 0);
\ No newline at end of file
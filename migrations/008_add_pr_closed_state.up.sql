@@ -0,0 +1,2 @@
+ALTER TABLE pull_requests
+    ADD COLUMN IF NOT EXISTS closed_at TIMESTAMP;
\ No newline at end of file
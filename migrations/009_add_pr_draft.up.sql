@@ -0,0 +1,2 @@
+ALTER TABLE pull_requests
+    ADD COLUMN IF NOT EXISTS is_draft BOOLEAN NOT NULL DEFAULT FALSE;
\ No newline at end of file
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS code_owner_rules;
+DROP TABLE IF EXISTS pull_request_files;
+
+ALTER TABLE pull_requests
+    DROP COLUMN IF EXISTS repository;
\ No newline at end of file
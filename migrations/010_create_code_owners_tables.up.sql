@@ -0,0 +1,17 @@
+ALTER TABLE pull_requests
+    ADD COLUMN IF NOT EXISTS repository TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS pull_request_files (
+    pull_request_id TEXT REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+    path TEXT NOT NULL,
+    PRIMARY KEY (pull_request_id, path)
+);
+
+CREATE TABLE IF NOT EXISTS code_owner_rules (
+    repository TEXT NOT NULL,
+    position INT NOT NULL,
+    pattern TEXT NOT NULL,
+    users TEXT[] NOT NULL DEFAULT '{}',
+    teams TEXT[] NOT NULL DEFAULT '{}',
+    PRIMARY KEY (repository, position)
+);
\ No newline at end of file
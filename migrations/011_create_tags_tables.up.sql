@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS user_tags (
+    user_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    tag TEXT NOT NULL CHECK (tag <> ''),
+    PRIMARY KEY (user_id, tag)
+);
+
+CREATE TABLE IF NOT EXISTS pull_request_labels (
+    pull_request_id TEXT NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+    label TEXT NOT NULL CHECK (label <> ''),
+    PRIMARY KEY (pull_request_id, label)
+);
\ No newline at end of file
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS user_absences (
+    absence_id BIGSERIAL PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    starts_at TIMESTAMPTZ NOT NULL,
+    ends_at TIMESTAMPTZ NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    reviews_reassigned BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK (ends_at > starts_at)
+);
+
+-- проверка отсутствия при выборе ревьюверов идет по user_id и окну
+CREATE INDEX IF NOT EXISTS idx_user_absences_user_window
+    ON user_absences (user_id, starts_at, ends_at);
+
+-- воркер ищет начавшиеся отсутствия без переназначения
+CREATE INDEX IF NOT EXISTS idx_user_absences_pending
+    ON user_absences (starts_at)
+    WHERE NOT reviews_reassigned;
\ No newline at end of file
@@ -0,0 +1,5 @@
+ALTER TABLE team_settings
+    DROP COLUMN IF EXISTS max_open_reviews;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS max_open_reviews;
\ No newline at end of file
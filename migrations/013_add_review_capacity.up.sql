@@ -0,0 +1,6 @@
+-- NULL - действует лимит команды, 0 - без ограничения
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS max_open_reviews INT CHECK (max_open_reviews >= 0);
+
+ALTER TABLE team_settings
+    ADD COLUMN IF NOT EXISTS max_open_reviews INT NOT NULL DEFAULT 0 CHECK (max_open_reviews >= 0);
\ No newline at end of file
@@ -0,0 +1,7 @@
+ALTER TABLE team_settings
+    DROP COLUMN IF EXISTS auto_reassign_overdue,
+    DROP COLUMN IF EXISTS review_sla_hours;
+
+ALTER TABLE pull_request_reviewers
+    DROP COLUMN IF EXISTS overdue_at,
+    DROP COLUMN IF EXISTS assigned_at;
\ No newline at end of file
@@ -0,0 +1,20 @@
+ALTER TABLE pull_request_reviewers
+    ADD COLUMN IF NOT EXISTS assigned_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS overdue_at TIMESTAMP;
+
+-- уже назначенные ревьюверы считаются назначенными при создании PR
+UPDATE pull_request_reviewers prr
+SET assigned_at = pr.created_at
+FROM pull_requests pr
+WHERE pr.pull_request_id = prr.pull_request_id AND prr.assigned_at IS NULL;
+
+UPDATE pull_request_reviewers SET assigned_at = now() WHERE assigned_at IS NULL;
+
+ALTER TABLE pull_request_reviewers
+    ALTER COLUMN assigned_at SET DEFAULT now(),
+    ALTER COLUMN assigned_at SET NOT NULL;
+
+-- 0 - SLA не отслеживается
+ALTER TABLE team_settings
+    ADD COLUMN IF NOT EXISTS review_sla_hours INT NOT NULL DEFAULT 0 CHECK (review_sla_hours >= 0),
+    ADD COLUMN IF NOT EXISTS auto_reassign_overdue BOOLEAN NOT NULL DEFAULT FALSE;
\ No newline at end of file
@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS pr_events (
+    event_id BIGSERIAL PRIMARY KEY,
+    event_type TEXT NOT NULL,
+    pull_request_id TEXT,
+    user_id TEXT,
+    old_user_id TEXT,
+    details JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+-- без внешних ключей: история должна переживать удаление PR и пользователей
+CREATE INDEX IF NOT EXISTS idx_pr_events_pr ON pr_events (pull_request_id, event_id);
+CREATE INDEX IF NOT EXISTS idx_pr_events_user ON pr_events (user_id, event_id);
+CREATE INDEX IF NOT EXISTS idx_pr_events_old_user ON pr_events (old_user_id, event_id)
+    WHERE old_user_id IS NOT NULL;
+
+-- журнал только дописывается
+CREATE OR REPLACE FUNCTION pr_events_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'pr_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS pr_events_append_only ON pr_events;
+CREATE TRIGGER pr_events_append_only
+    BEFORE UPDATE OR DELETE ON pr_events
+    FOR EACH ROW EXECUTE FUNCTION pr_events_append_only();
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE team_settings
+    DROP COLUMN IF EXISTS max_reviewers;
\ No newline at end of file
@@ -0,0 +1,3 @@
+-- 0 - ограничение только общим пределом сервиса
+ALTER TABLE team_settings
+    ADD COLUMN IF NOT EXISTS max_reviewers INT NOT NULL DEFAULT 0 CHECK (max_reviewers >= 0);
\ No newline at end of file
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_pull_requests_author;
+DROP INDEX IF EXISTS idx_pull_requests_created;
\ No newline at end of file
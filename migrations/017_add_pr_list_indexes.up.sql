@@ -0,0 +1,3 @@
+-- список PR сортируется по дате создания и id
+CREATE INDEX IF NOT EXISTS idx_pull_requests_created ON pull_requests (created_at DESC, pull_request_id DESC);
+CREATE INDEX IF NOT EXISTS idx_pull_requests_author ON pull_requests (author_id);
\ No newline at end of file
@@ -0,0 +1,13 @@
+-- переименование команды переносится в настройки и резервные команды
+ALTER TABLE team_settings
+    DROP CONSTRAINT IF EXISTS team_settings_team_name_fkey,
+    ADD CONSTRAINT team_settings_team_name_fkey
+        FOREIGN KEY (team_name) REFERENCES teams(team_name) ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE team_fallbacks
+    DROP CONSTRAINT IF EXISTS team_fallbacks_team_name_fkey,
+    DROP CONSTRAINT IF EXISTS team_fallbacks_fallback_team_fkey,
+    ADD CONSTRAINT team_fallbacks_team_name_fkey
+        FOREIGN KEY (team_name) REFERENCES teams(team_name) ON DELETE CASCADE ON UPDATE CASCADE,
+    ADD CONSTRAINT team_fallbacks_fallback_team_fkey
+        FOREIGN KEY (fallback_team) REFERENCES teams(team_name) ON DELETE CASCADE ON UPDATE CASCADE;
\ No newline at end of file
@@ -0,0 +1,5 @@
+ALTER TABLE pull_requests
+    DROP COLUMN IF EXISTS orphaned;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS deleted_at;
\ No newline at end of file
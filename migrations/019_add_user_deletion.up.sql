@@ -0,0 +1,7 @@
+-- удаленный пользователь остается в таблице ради истории и ссылок из ревью
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+-- PR удаленного автора без нового владельца
+ALTER TABLE pull_requests
+    ADD COLUMN IF NOT EXISTS orphaned BOOLEAN NOT NULL DEFAULT FALSE;
\ No newline at end of file
@@ -0,0 +1,4 @@
+ALTER TABLE pull_requests
+    DROP COLUMN IF EXISTS team_name;
+
+DROP TABLE IF EXISTS team_members;
\ No newline at end of file
@@ -0,0 +1,22 @@
+-- участие пользователей в командах, users.team_name остается основной командой
+CREATE TABLE IF NOT EXISTS team_members (
+    team_name TEXT NOT NULL REFERENCES teams(team_name) ON DELETE CASCADE ON UPDATE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    PRIMARY KEY (team_name, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_team_members_user ON team_members(user_id);
+
+INSERT INTO team_members(team_name, user_id)
+SELECT u.team_name, u.user_id FROM users u
+JOIN teams t ON t.team_name = u.team_name
+WHERE u.deleted_at IS NULL
+ON CONFLICT DO NOTHING;
+
+-- команда, из которой выбираются ревьюверы PR
+ALTER TABLE pull_requests
+    ADD COLUMN IF NOT EXISTS team_name TEXT NOT NULL DEFAULT '';
+
+UPDATE pull_requests pr SET team_name = COALESCE(u.team_name, '')
+FROM users u
+WHERE u.user_id = pr.author_id;
\ No newline at end of file
@@ -0,0 +1,5 @@
+ALTER TABLE team_settings
+    DROP COLUMN IF EXISTS require_lead;
+
+ALTER TABLE team_members
+    DROP COLUMN IF EXISTS role;
\ No newline at end of file
@@ -0,0 +1,7 @@
+-- роль участника в команде
+ALTER TABLE team_members
+    ADD COLUMN IF NOT EXISTS role TEXT NOT NULL DEFAULT 'member' CHECK (role IN ('member', 'lead', 'maintainer'));
+
+-- среди ревьюверов PR команды должен быть лид
+ALTER TABLE team_settings
+    ADD COLUMN IF NOT EXISTS require_lead BOOLEAN NOT NULL DEFAULT FALSE;
\ No newline at end of file
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_pull_request_reviewers_assigned;
+
+ALTER TABLE team_settings
+    DROP COLUMN IF EXISTS rotation_window_days;
\ No newline at end of file
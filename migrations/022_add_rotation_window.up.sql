@@ -0,0 +1,6 @@
+-- окно памяти ротации пар автор-ревьювер, 0 - не учитывается
+ALTER TABLE team_settings
+    ADD COLUMN IF NOT EXISTS rotation_window_days INT NOT NULL DEFAULT 0 CHECK (rotation_window_days >= 0);
+
+-- пары автор-ревьювер считаются по назначениям за окно
+CREATE INDEX IF NOT EXISTS idx_pull_request_reviewers_assigned ON pull_request_reviewers (assigned_at);
\ No newline at end of file
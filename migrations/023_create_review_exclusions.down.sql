@@ -0,0 +1 @@
+DROP TABLE IF EXISTS review_exclusions;
\ No newline at end of file
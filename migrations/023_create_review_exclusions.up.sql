@@ -0,0 +1,11 @@
+-- правила конфликта интересов: reviewer_id никогда не ревьюит PR author_id
+CREATE TABLE IF NOT EXISTS review_exclusions (
+    reviewer_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    author_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (reviewer_id, author_id),
+    CHECK (reviewer_id <> author_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_review_exclusions_author ON review_exclusions(author_id);
\ No newline at end of file
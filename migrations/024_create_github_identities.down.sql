@@ -0,0 +1 @@
+DROP TABLE IF EXISTS github_identities;
\ No newline at end of file
@@ -0,0 +1,8 @@
+-- соответствие логинов GitHub пользователям сервиса для приема вебхуков
+CREATE TABLE IF NOT EXISTS github_identities (
+    github_login TEXT PRIMARY KEY, -- в нижнем регистре: логины GitHub регистронезависимы
+    user_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_github_identities_user ON github_identities(user_id);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS github_deliveries;
\ No newline at end of file
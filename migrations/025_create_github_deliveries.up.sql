@@ -0,0 +1,6 @@
+-- принятые доставки вебхуков GitHub: повтор доставки с тем же X-GitHub-Delivery не применяется снова
+CREATE TABLE IF NOT EXISTS github_deliveries (
+    delivery_id TEXT PRIMARY KEY,
+    event TEXT NOT NULL,
+    received_at TIMESTAMP NOT NULL DEFAULT now()
+);
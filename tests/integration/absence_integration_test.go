@@ -0,0 +1,85 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestAbsenceReassignsAndExcludes(t *testing.T) {
+	// чистим бд
+	ResetDB()
+
+	postJSON(t, "/team/add", map[string]interface{}{
+		"team_name": "absence_team",
+		"members": []map[string]interface{}{
+			{"user_id": "u1", "username": "Author", "is_active": true},
+			{"user_id": "u2", "username": "Vacationer", "is_active": true},
+			{"user_id": "u3", "username": "Backup", "is_active": true},
+		},
+	})
+	postJSON(t, "/team/settings", map[string]interface{}{
+		"team_name": "absence_team", "reviewers_count": 1,
+	})
+
+	// u2 назначается ревьювером до отпуска
+	status, body := postJSON(t, "/pullRequest/create", map[string]interface{}{
+		"pull_request_id": "pr-before", "pull_request_name": "before", "author_id": "u1",
+	})
+	if status != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d, body: %s", http.StatusCreated, status, string(body))
+	}
+
+	// некорректный период
+	now := time.Now().UTC()
+	status, body = postJSON(t, "/users/absence", map[string]interface{}{
+		"user_id": "u2", "starts_at": now, "ends_at": now.Add(-time.Hour),
+	})
+	if status != http.StatusBadRequest || errorCode(t, body) != "INVALID_INPUT" {
+		t.Fatalf("expected 400 INVALID_INPUT, got %d, body: %s", status, string(body))
+	}
+
+	status, body = postJSON(t, "/users/absence", map[string]interface{}{
+		"user_id": "u2", "ends_at": now.Add(24 * time.Hour), "reason": "vacation",
+	})
+	if status != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d, body: %s", http.StatusCreated, status, string(body))
+	}
+
+	// открытое ревью ушло от u2
+	status, body = getJSON(t, "/users/getReview?user_id=u2")
+	if status != http.StatusOK {
+		t.Fatalf("expected status %d, got %d, body: %s", http.StatusOK, status, string(body))
+	}
+	var reviews struct {
+		PullRequests []map[string]interface{} `json:"pull_requests"`
+	}
+	if err := json.Unmarshal(body, &reviews); err != nil {
+		t.Fatalf("failed to decode json: %v", err)
+	}
+	if len(reviews.PullRequests) != 0 {
+		t.Fatalf("expected no reviews for absent user, got %v", reviews.PullRequests)
+	}
+
+	// отсутствующий не выбирается для новых PR
+	for _, id := range []string{"pr-after-1", "pr-after-2"} {
+		status, body = postJSON(t, "/pullRequest/create", map[string]interface{}{
+			"pull_request_id": id, "pull_request_name": id, "author_id": "u1",
+		})
+		if status != http.StatusCreated {
+			t.Fatalf("expected status %d, got %d, body: %s", http.StatusCreated, status, string(body))
+		}
+		var resp struct {
+			PR struct {
+				AssignedReviewers []string `json:"assigned_reviewers"`
+			} `json:"pr"`
+		}
+		if err := json.Unmarshal(body, &resp); err != nil {
+			t.Fatalf("failed to decode json: %v", err)
+		}
+		if len(resp.PR.AssignedReviewers) != 1 || resp.PR.AssignedReviewers[0] != "u3" {
+			t.Fatalf("expected reviewer u3, got %v", resp.PR.AssignedReviewers)
+		}
+	}
+}
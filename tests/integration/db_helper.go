@@ -73,6 +73,15 @@ func ResetDB() {
         TRUNCATE TABLE users RESTART IDENTITY CASCADE;
         TRUNCATE TABLE teams RESTART IDENTITY CASCADE;
 		TRUNCATE TABLE pull_request_reviewers RESTART IDENTITY CASCADE;
+		TRUNCATE TABLE team_settings RESTART IDENTITY CASCADE;
+		TRUNCATE TABLE team_fallbacks RESTART IDENTITY CASCADE;
+		TRUNCATE TABLE pull_request_files RESTART IDENTITY CASCADE;
+		TRUNCATE TABLE code_owner_rules RESTART IDENTITY CASCADE;
+		TRUNCATE TABLE user_tags RESTART IDENTITY CASCADE;
+		TRUNCATE TABLE user_absences RESTART IDENTITY CASCADE;
+		TRUNCATE TABLE pr_events RESTART IDENTITY CASCADE;
+		TRUNCATE TABLE pull_request_labels RESTART IDENTITY CASCADE;
+		TRUNCATE TABLE github_deliveries RESTART IDENTITY CASCADE;
     `)
 	if err != nil {
 		log.Fatalf("failed to truncate tables: %v", err)
@@ -0,0 +1,171 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+// тестируем правила исключения пар автор-ревьювер
+func TestReviewExclusions(t *testing.T) {
+	// чистим бд
+	ResetDB()
+
+	postJSON(t, "/team/add", map[string]interface{}{
+		"team_name": "exclusion_team",
+		"members": []map[string]interface{}{
+			{"user_id": "u1", "username": "Author", "is_active": true},
+			{"user_id": "u2", "username": "Manager", "is_active": true},
+			{"user_id": "u3", "username": "Partner", "is_active": true},
+		},
+	})
+	postJSON(t, "/team/settings", map[string]interface{}{"team_name": "exclusion_team", "reviewers_count": 1})
+
+	postJSON(t, "/pullRequest/create", map[string]string{"pull_request_id": "pr-ex-1", "pull_request_name": "first", "author_id": "u1"})
+	if reviewers := reviewersOf(t, "pr-ex-1"); len(reviewers) != 1 || reviewers[0] != "u2" {
+		t.Fatalf("expected reviewer u2, got %v", reviewers)
+	}
+
+	// новое правило переназначает уже назначенное ревью
+	status, body := postJSON(t, "/rules/exclusions", map[string]interface{}{"reviewer_id": "u2", "author_id": "u1", "reason": "manager"})
+	if status != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d, body: %s", http.StatusCreated, status, string(body))
+	}
+	var created struct {
+		Exclusions []struct {
+			ReviewerID string `json:"reviewer_id"`
+			AuthorID   string `json:"author_id"`
+			Reason     string `json:"reason"`
+		} `json:"exclusions"`
+		ReassignedReviews int `json:"reassigned_reviews"`
+		UnassignedReviews int `json:"unassigned_reviews"`
+	}
+	if err := json.Unmarshal(body, &created); err != nil {
+		t.Fatalf("failed to decode json: %v", err)
+	}
+	if len(created.Exclusions) != 1 || created.Exclusions[0].Reason != "manager" || created.ReassignedReviews != 1 {
+		t.Fatalf("unexpected create response: %s", string(body))
+	}
+	if reviewers := reviewersOf(t, "pr-ex-1"); len(reviewers) != 1 || reviewers[0] != "u3" {
+		t.Fatalf("expected reviewer u3 after exclusion, got %v", reviewers)
+	}
+
+	// ручное назначение исключенного ревьювера запрещено
+	status, body = postJSON(t, "/pullRequest/reviewers/add", map[string]string{"pull_request_id": "pr-ex-1", "user_id": "u2"})
+	if status != http.StatusConflict || errorCode(t, body) != "REVIEWER_EXCLUDED" {
+		t.Fatalf("expected 409 REVIEWER_EXCLUDED, got %d, body: %s", status, string(body))
+	}
+
+	// без замены ревью снимается
+	status, body = postJSON(t, "/rules/exclusions", map[string]interface{}{"reviewer_id": "u3", "author_id": "u1"})
+	if status != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d, body: %s", http.StatusCreated, status, string(body))
+	}
+	if err := json.Unmarshal(body, &created); err != nil {
+		t.Fatalf("failed to decode json: %v", err)
+	}
+	if created.ReassignedReviews != 0 || created.UnassignedReviews != 1 || len(reviewersOf(t, "pr-ex-1")) != 0 {
+		t.Fatalf("expected review to be unassigned, got %s", string(body))
+	}
+
+	// правила не оставляют кандидатов
+	status, body = postJSON(t, "/pullRequest/create", map[string]string{"pull_request_id": "pr-ex-2", "pull_request_name": "second", "author_id": "u1"})
+	var noCandidate struct {
+		Error struct {
+			Code   string `json:"code"`
+			Reason string `json:"reason"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &noCandidate); err != nil {
+		t.Fatalf("failed to decode json: %v", err)
+	}
+	if status != http.StatusConflict || noCandidate.Error.Code != "NO_CANDIDATE" || noCandidate.Error.Reason != "EXCLUSION_RULES" {
+		t.Fatalf("expected 409 NO_CANDIDATE with reason, got %d, body: %s", status, string(body))
+	}
+
+	// ошибки создания
+	cases := []struct {
+		payload  map[string]interface{}
+		want     int
+		wantCode string
+	}{
+		{map[string]interface{}{"reviewer_id": "u2", "author_id": "u2"}, http.StatusBadRequest, "INVALID_INPUT"},
+		{map[string]interface{}{"reviewer_id": "u2", "author_id": "missing"}, http.StatusNotFound, "NOT_FOUND"},
+	}
+	for _, tc := range cases {
+		status, body := postJSON(t, "/rules/exclusions", tc.payload)
+		if status != tc.want || errorCode(t, body) != tc.wantCode {
+			t.Fatalf("%v: expected %d %s, got %d, body: %s", tc.payload, tc.want, tc.wantCode, status, string(body))
+		}
+	}
+
+	status, body = getJSON(t, "/rules/exclusions?user_id=u1")
+	var list struct {
+		Exclusions []struct {
+			ReviewerID string `json:"reviewer_id"`
+		} `json:"exclusions"`
+	}
+	if err := json.Unmarshal(body, &list); err != nil {
+		t.Fatalf("failed to decode json: %v", err)
+	}
+	if status != http.StatusOK || len(list.Exclusions) != 2 {
+		t.Fatalf("expected 2 exclusions, got %d, body: %s", status, string(body))
+	}
+
+	// после удаления правила ревьювер снова доступен
+	status, body = postJSON(t, "/rules/exclusions/delete", map[string]string{"reviewer_id": "u3", "author_id": "u1"})
+	if status != http.StatusOK {
+		t.Fatalf("expected status %d, got %d, body: %s", http.StatusOK, status, string(body))
+	}
+	status, body = postJSON(t, "/rules/exclusions/delete", map[string]string{"reviewer_id": "u3", "author_id": "u1"})
+	if status != http.StatusNotFound || errorCode(t, body) != "NOT_FOUND" {
+		t.Fatalf("expected 404 NOT_FOUND, got %d, body: %s", status, string(body))
+	}
+	postJSON(t, "/pullRequest/create", map[string]string{"pull_request_id": "pr-ex-3", "pull_request_name": "third", "author_id": "u1"})
+	if reviewers := reviewersOf(t, "pr-ex-3"); len(reviewers) != 1 || reviewers[0] != "u3" {
+		t.Fatalf("expected reviewer u3 after deletion, got %v", reviewers)
+	}
+}
+
+// правило сохранилось, а переназначение прошлого вызова не завершилось: повтор доделывает его
+func TestExistingExclusionReassignsReviews(t *testing.T) {
+	// чистим бд
+	ResetDB()
+
+	postJSON(t, "/team/add", map[string]interface{}{
+		"team_name": "exclusion_retry_team",
+		"members": []map[string]interface{}{
+			{"user_id": "u1", "username": "Author", "is_active": true},
+			{"user_id": "u2", "username": "Manager", "is_active": true},
+			{"user_id": "u3", "username": "Partner", "is_active": true},
+		},
+	})
+	postJSON(t, "/team/settings", map[string]interface{}{"team_name": "exclusion_retry_team", "reviewers_count": 1})
+
+	postJSON(t, "/pullRequest/create", map[string]string{"pull_request_id": "pr-ex-retry", "pull_request_name": "retry", "author_id": "u1"})
+	if reviewers := reviewersOf(t, "pr-ex-retry"); len(reviewers) != 1 || reviewers[0] != "u2" {
+		t.Fatalf("expected reviewer u2, got %v", reviewers)
+	}
+
+	if _, err := testDB.Exec(`INSERT INTO review_exclusions(reviewer_id, author_id) VALUES('u2', 'u1')`); err != nil {
+		t.Fatalf("failed to insert exclusion: %v", err)
+	}
+
+	status, body := postJSON(t, "/rules/exclusions", map[string]interface{}{"reviewer_id": "u2", "author_id": "u1"})
+	if status != http.StatusOK {
+		t.Fatalf("expected status %d, got %d, body: %s", http.StatusOK, status, string(body))
+	}
+	var resp struct {
+		Exclusions        []interface{} `json:"exclusions"`
+		ReassignedReviews int           `json:"reassigned_reviews"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatalf("failed to decode json: %v", err)
+	}
+	if resp.Exclusions == nil || len(resp.Exclusions) != 0 || resp.ReassignedReviews != 1 {
+		t.Fatalf("expected empty exclusions and 1 reassigned review, got %+v", resp)
+	}
+	if reviewers := reviewersOf(t, "pr-ex-retry"); len(reviewers) != 1 || reviewers[0] != "u3" {
+		t.Fatalf("expected reviewer u3 after retry, got %v", reviewers)
+	}
+}
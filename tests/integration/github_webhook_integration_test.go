@@ -0,0 +1,229 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/IlyaChern12/PR-Reviewer-Service-Avito/internal/github"
+)
+
+type webhookResp struct {
+	Event  string `json:"event"`
+	Action string `json:"action"`
+	Result string `json:"result"`
+	PR     struct {
+		PRID       string   `json:"pull_request_id"`
+		AuthorID   string   `json:"author_id"`
+		Status     string   `json:"status"`
+		IsDraft    bool     `json:"is_draft"`
+		Repository string   `json:"repository"`
+		Labels     []string `json:"labels"`
+		Reviewers  []string `json:"assigned_reviewers"`
+	} `json:"pr"`
+}
+
+// deliverWebhook отправляет записанное событие GitHub из testdata/github, подписывая его
+// тестовым секретом (пустой secret - без подписи)
+func deliverWebhook(t *testing.T, event, fixture, secret string) (int, []byte) {
+	t.Helper()
+	return redeliverWebhook(t, event, fixture, secret, "")
+}
+
+// redeliverWebhook отправляет событие с заголовком X-GitHub-Delivery (пустой deliveryID - без него)
+func redeliverWebhook(t *testing.T, event, fixture, secret, deliveryID string) (int, []byte) {
+	t.Helper()
+
+	payload, err := os.ReadFile(filepath.Join("testdata", "github", fixture))
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	req, err := http.NewRequest(http.MethodPost, baseURL+"/webhooks/github", bytes.NewReader(payload))
+	if err != nil {
+		t.Fatalf("failed to build request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(github.HeaderEvent, event)
+	if secret != "" {
+		req.Header.Set(github.HeaderSignature, github.Sign([]byte(secret), payload))
+	}
+	if deliveryID != "" {
+		req.Header.Set(github.HeaderDelivery, deliveryID)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed read body: %v", err)
+	}
+	return resp.StatusCode, respBody
+}
+
+// deliverPullRequest отправляет подписанное событие pull_request и разбирает ответ
+func deliverPullRequest(t *testing.T, fixture string, wantStatus int, wantResult string) webhookResp {
+	t.Helper()
+
+	status, body := deliverWebhook(t, github.EventPullRequest, fixture, testWebhookSecret)
+	var resp webhookResp
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatalf("failed to decode json: %v", err)
+	}
+	if status != wantStatus || resp.Result != wantResult {
+		t.Fatalf("%s: expected %d %s, got %d, body: %s", fixture, wantStatus, wantResult, status, string(body))
+	}
+	return resp
+}
+
+// тестируем ведение PR по записанным вебхукам GitHub
+func TestGitHubWebhooks(t *testing.T) {
+	// чистим бд
+	ResetDB()
+
+	postJSON(t, "/team/add", map[string]interface{}{
+		"team_name": "payments",
+		"members": []map[string]interface{}{
+			{"user_id": "u1", "username": "Author", "is_active": true},
+			{"user_id": "u2", "username": "Bob", "is_active": true},
+			{"user_id": "u3", "username": "Carol", "is_active": true},
+		},
+	})
+	postJSON(t, "/team/settings", map[string]interface{}{"team_name": "payments", "reviewers_count": 1, "approvals_required": 1})
+
+	// подпись обязательна и должна совпадать
+	status, body := deliverWebhook(t, github.EventPullRequest, "pull_request_opened.json", "")
+	if status != http.StatusUnauthorized || errorCode(t, body) != "INVALID_SIGNATURE" {
+		t.Fatalf("expected 401 INVALID_SIGNATURE, got %d, body: %s", status, string(body))
+	}
+	status, body = deliverWebhook(t, github.EventPullRequest, "pull_request_opened.json", "wrong-secret")
+	if status != http.StatusUnauthorized || errorCode(t, body) != "INVALID_SIGNATURE" {
+		t.Fatalf("expected 401 INVALID_SIGNATURE, got %d, body: %s", status, string(body))
+	}
+
+	// логин автора еще не привязан, неудачная доставка не запоминается
+	status, body = redeliverWebhook(t, github.EventPullRequest, "pull_request_opened.json", testWebhookSecret, "delivery-opened")
+	if status != http.StatusUnprocessableEntity || errorCode(t, body) != "UNKNOWN_GITHUB_LOGIN" {
+		t.Fatalf("expected 422 UNKNOWN_GITHUB_LOGIN, got %d, body: %s", status, string(body))
+	}
+
+	status, body = postJSON(t, "/identities/github", map[string]string{"github_login": "Octo-Author", "user_id": "u1"})
+	var identity struct {
+		GitHubLogin string `json:"github_login"`
+		UserID      string `json:"user_id"`
+	}
+	if err := json.Unmarshal(body, &identity); err != nil {
+		t.Fatalf("failed to decode json: %v", err)
+	}
+	if status != http.StatusOK || identity.GitHubLogin != "octo-author" || identity.UserID != "u1" {
+		t.Fatalf("unexpected link response %d: %s", status, string(body))
+	}
+	status, body = postJSON(t, "/identities/github", map[string]string{"github_login": "ghost", "user_id": "missing"})
+	if status != http.StatusNotFound || errorCode(t, body) != "NOT_FOUND" {
+		t.Fatalf("expected 404 NOT_FOUND, got %d, body: %s", status, string(body))
+	}
+
+	// ping и прочие события
+	status, body = deliverWebhook(t, github.EventPing, "ping.json", testWebhookSecret)
+	if status != http.StatusOK {
+		t.Fatalf("expected ping status %d, got %d, body: %s", http.StatusOK, status, string(body))
+	}
+	status, body = deliverWebhook(t, "push", "ping.json", testWebhookSecret)
+	if status != http.StatusAccepted {
+		t.Fatalf("expected push status %d, got %d, body: %s", http.StatusAccepted, status, string(body))
+	}
+
+	// PR, открытый до подключения вебхука, сервису неизвестен
+	deliverPullRequest(t, "pull_request_closed_merged.json", http.StatusAccepted, "ignored")
+
+	status, body = redeliverWebhook(t, github.EventPullRequest, "pull_request_opened.json", testWebhookSecret, "delivery-opened")
+	var opened webhookResp
+	if err := json.Unmarshal(body, &opened); err != nil {
+		t.Fatalf("failed to decode json: %v", err)
+	}
+	if status != http.StatusOK || opened.Result != "created" {
+		t.Fatalf("expected 200 created, got %d, body: %s", status, string(body))
+	}
+	if opened.PR.PRID != "acme/payments#42" || opened.PR.AuthorID != "u1" || opened.PR.Repository != "acme/payments" ||
+		len(opened.PR.Labels) != 1 || len(opened.PR.Reviewers) != 1 || opened.PR.Reviewers[0] != "u2" {
+		t.Fatalf("unexpected created PR: %+v", opened.PR)
+	}
+	// повторная доставка ничего не меняет
+	deliverPullRequest(t, "pull_request_opened.json", http.StatusOK, "duplicate")
+	deliverPullRequest(t, "pull_request_labeled.json", http.StatusAccepted, "ignored")
+
+	// черновик получает ревьюверов после ready_for_review
+	draft := deliverPullRequest(t, "pull_request_opened_draft.json", http.StatusOK, "created")
+	if !draft.PR.IsDraft || len(draft.PR.Reviewers) != 0 {
+		t.Fatalf("expected draft without reviewers, got %+v", draft.PR)
+	}
+	deliverPullRequest(t, "pull_request_ready_for_review.json", http.StatusOK, "ready")
+	if reviewers := reviewersOf(t, "acme/payments#43"); len(reviewers) != 1 {
+		t.Fatalf("expected 1 reviewer after ready_for_review, got %v", reviewers)
+	}
+
+	// слияние на GitHub уже произошло: кворум и CHANGES_REQUESTED его не блокируют
+	postJSON(t, "/pullRequest/review", map[string]string{"pull_request_id": "acme/payments#42", "reviewer_id": "u2", "verdict": "CHANGES_REQUESTED"})
+	merged := deliverPullRequest(t, "pull_request_closed_merged.json", http.StatusOK, "merged")
+	if merged.PR.Status != "MERGED" {
+		t.Fatalf("expected MERGED, got %+v", merged.PR)
+	}
+	// повторная доставка идемпотентна
+	deliverPullRequest(t, "pull_request_closed_merged.json", http.StatusOK, "merged")
+
+	status, body = redeliverWebhook(t, github.EventPullRequest, "pull_request_closed.json", testWebhookSecret, "delivery-closed")
+	var closed webhookResp
+	if err := json.Unmarshal(body, &closed); err != nil {
+		t.Fatalf("failed to decode json: %v", err)
+	}
+	if status != http.StatusOK || closed.Result != "closed" || closed.PR.Status != "CLOSED" {
+		t.Fatalf("expected closed PR, got %d, body: %s", status, string(body))
+	}
+	reopened := deliverPullRequest(t, "pull_request_reopened.json", http.StatusOK, "reopened")
+	if reopened.PR.Status != "OPEN" {
+		t.Fatalf("expected OPEN, got %+v", reopened.PR)
+	}
+
+	// повтор старой доставки closed не закрывает переоткрытый PR
+	status, body = redeliverWebhook(t, github.EventPullRequest, "pull_request_closed.json", testWebhookSecret, "delivery-closed")
+	var redelivered webhookResp
+	if err := json.Unmarshal(body, &redelivered); err != nil {
+		t.Fatalf("failed to decode json: %v", err)
+	}
+	if status != http.StatusOK || redelivered.Result != "redelivered" {
+		t.Fatalf("expected 200 redelivered, got %d, body: %s", status, string(body))
+	}
+	status, body = getJSON(t, "/pullRequest/list?status=OPEN&author_id=u1")
+	if status != http.StatusOK || !bytes.Contains(body, []byte(`"acme/payments#42"`)) {
+		t.Fatalf("expected acme/payments#42 to stay OPEN, got %d, body: %s", status, string(body))
+	}
+
+	// управление привязками
+	status, body = getJSON(t, "/identities/github?user_id=u1")
+	var list struct {
+		Identities []struct {
+			GitHubLogin string `json:"github_login"`
+		} `json:"identities"`
+	}
+	if err := json.Unmarshal(body, &list); err != nil {
+		t.Fatalf("failed to decode json: %v", err)
+	}
+	if status != http.StatusOK || len(list.Identities) != 1 || list.Identities[0].GitHubLogin != "octo-author" {
+		t.Fatalf("unexpected identities %d: %s", status, string(body))
+	}
+	status, body = postJSON(t, "/identities/github/delete", map[string]string{"github_login": "OCTO-AUTHOR"})
+	if status != http.StatusOK {
+		t.Fatalf("expected status %d, got %d, body: %s", http.StatusOK, status, string(body))
+	}
+	status, body = postJSON(t, "/identities/github/delete", map[string]string{"github_login": "octo-author"})
+	if status != http.StatusNotFound || errorCode(t, body) != "NOT_FOUND" {
+		t.Fatalf("expected 404 NOT_FOUND, got %d, body: %s", status, string(body))
+	}
+}
@@ -0,0 +1,151 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+type historyResp struct {
+	Events []struct {
+		Type      string                 `json:"event_type"`
+		UserID    string                 `json:"user_id"`
+		OldUserID string                 `json:"old_user_id"`
+		Details   map[string]interface{} `json:"details"`
+	} `json:"events"`
+}
+
+func TestPRAndUserHistory(t *testing.T) {
+	// чистим бд
+	ResetDB()
+
+	postJSON(t, "/team/add", map[string]interface{}{
+		"team_name": "history_team",
+		"members": []map[string]interface{}{
+			{"user_id": "u1", "username": "First", "is_active": true},
+			{"user_id": "u2", "username": "Second", "is_active": true},
+			{"user_id": "u3", "username": "Author", "is_active": true},
+		},
+	})
+	postJSON(t, "/team/settings", map[string]interface{}{"team_name": "history_team", "reviewers_count": 1})
+
+	// u1 назначается, затем ревью переходит к u2 и PR сливается
+	postJSON(t, "/pullRequest/create", map[string]interface{}{
+		"pull_request_id": "pr-hist", "pull_request_name": "history", "author_id": "u3",
+	})
+	status, body := postJSON(t, "/pullRequest/reassign", map[string]string{"pull_request_id": "pr-hist", "old_user_id": "u1"})
+	if status != http.StatusOK {
+		t.Fatalf("expected status %d, got %d, body: %s", http.StatusOK, status, string(body))
+	}
+	postJSON(t, "/pullRequest/merge", map[string]string{"pull_request_id": "pr-hist"})
+
+	status, body = getJSON(t, "/pullRequest/history?pull_request_id=pr-hist")
+	if status != http.StatusOK {
+		t.Fatalf("expected status %d, got %d, body: %s", http.StatusOK, status, string(body))
+	}
+	var prHistory historyResp
+	if err := json.Unmarshal(body, &prHistory); err != nil {
+		t.Fatalf("failed to decode json: %v", err)
+	}
+
+	expected := []string{"PR_CREATED", "REVIEWER_ASSIGNED", "REVIEWER_REASSIGNED", "PR_MERGED"}
+	if len(prHistory.Events) != len(expected) {
+		t.Fatalf("expected %d events, got %+v", len(expected), prHistory.Events)
+	}
+	for i, eventType := range expected {
+		if prHistory.Events[i].Type != eventType {
+			t.Fatalf("expected event %d to be %s, got %s", i, eventType, prHistory.Events[i].Type)
+		}
+	}
+	reassigned := prHistory.Events[2]
+	if reassigned.OldUserID != "u1" || reassigned.UserID != "u2" {
+		t.Fatalf("expected reassignment u1 -> u2, got %+v", reassigned)
+	}
+
+	// кто ревьюил на момент слияния
+	reviewers, _ := prHistory.Events[3].Details["reviewers"].([]interface{})
+	if len(reviewers) != 1 || reviewers[0] != "u2" {
+		t.Fatalf("expected merge snapshot [u2], got %v", prHistory.Events[3].Details)
+	}
+
+	// смена активности тоже попадает в историю пользователя
+	postJSON(t, "/users/setIsActive", map[string]interface{}{"user_id": "u1", "is_active": false})
+
+	status, body = getJSON(t, "/users/history?user_id=u1")
+	if status != http.StatusOK {
+		t.Fatalf("expected status %d, got %d, body: %s", http.StatusOK, status, string(body))
+	}
+	var userHistory historyResp
+	if err := json.Unmarshal(body, &userHistory); err != nil {
+		t.Fatalf("failed to decode json: %v", err)
+	}
+	expected = []string{"REVIEWER_ASSIGNED", "REVIEWER_REASSIGNED", "USER_DEACTIVATED"}
+	if len(userHistory.Events) != len(expected) {
+		t.Fatalf("expected %d events, got %+v", len(expected), userHistory.Events)
+	}
+	for i, eventType := range expected {
+		if userHistory.Events[i].Type != eventType {
+			t.Fatalf("expected event %d to be %s, got %s", i, eventType, userHistory.Events[i].Type)
+		}
+	}
+
+	status, body = getJSON(t, "/pullRequest/history?pull_request_id=unknown")
+	if status != http.StatusNotFound || errorCode(t, body) != "NOT_FOUND" {
+		t.Fatalf("expected 404 NOT_FOUND, got %d, body: %s", status, string(body))
+	}
+}
+
+func TestTeamAddActivityHistory(t *testing.T) {
+	// чистим бд
+	ResetDB()
+
+	postJSON(t, "/team/add", map[string]interface{}{
+		"team_name": "first_team",
+		"members": []map[string]interface{}{
+			{"user_id": "u1", "username": "First", "is_active": false},
+			{"user_id": "u2", "username": "Second", "is_active": true},
+		},
+	})
+
+	// повторное добавление в другую команду меняет активность u1, у u2 она прежняя
+	status, body := postJSON(t, "/team/add", map[string]interface{}{
+		"team_name": "second_team",
+		"members": []map[string]interface{}{
+			{"user_id": "u1", "username": "First", "is_active": true},
+			{"user_id": "u2", "username": "Second", "is_active": true},
+		},
+	})
+	if status != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d, body: %s", http.StatusCreated, status, string(body))
+	}
+
+	cases := []struct {
+		name     string
+		userID   string
+		expected []string
+	}{
+		{"активность_изменилась", "u1", []string{"USER_ACTIVATED"}},
+		{"активность_прежняя", "u2", []string{}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			status, body := getJSON(t, "/users/history?user_id="+c.userID)
+			if status != http.StatusOK {
+				t.Fatalf("expected status %d, got %d, body: %s", http.StatusOK, status, string(body))
+			}
+			var history historyResp
+			if err := json.Unmarshal(body, &history); err != nil {
+				t.Fatalf("failed to decode json: %v", err)
+			}
+			if len(history.Events) != len(c.expected) {
+				t.Fatalf("expected %v, got %+v", c.expected, history.Events)
+			}
+			for i, eventType := range c.expected {
+				if history.Events[i].Type != eventType {
+					t.Fatalf("expected event %d to be %s, got %s", i, eventType, history.Events[i].Type)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+)
+
+// postJSON отправляет POST с JSON телом и возвращает статус и тело ответа
+func postJSON(t *testing.T, path string, payload interface{}) (int, []byte) {
+	t.Helper()
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("failed to marshal payload: %v", err)
+	}
+	resp, err := http.Post(baseURL+path, "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("request error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed read body: %v", err)
+	}
+	return resp.StatusCode, respBody
+}
+
+// postRaw отправляет POST с произвольным телом (файлом) и возвращает статус и тело ответа
+func postRaw(t *testing.T, path, contentType string, payload []byte) (int, []byte) {
+	t.Helper()
+
+	resp, err := http.Post(baseURL+path, contentType, bytes.NewReader(payload))
+	if err != nil {
+		t.Fatalf("request error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed read body: %v", err)
+	}
+	return resp.StatusCode, respBody
+}
+
+// getJSON отправляет GET и возвращает статус и тело ответа
+func getJSON(t *testing.T, path string) (int, []byte) {
+	t.Helper()
+
+	resp, err := http.Get(baseURL + path)
+	if err != nil {
+		t.Fatalf("request error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed read body: %v", err)
+	}
+	return resp.StatusCode, respBody
+}
+
+// errorCode достает error.code из тела ответа
+func errorCode(t *testing.T, body []byte) string {
+	t.Helper()
+
+	var errResp struct {
+		Error struct {
+			Code string `json:"code"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &errResp); err != nil {
+		t.Fatalf("failed to decode error json: %v. raw=%s", err, string(body))
+	}
+	return errResp.Error.Code
+}
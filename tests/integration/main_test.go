@@ -19,6 +19,12 @@ import (
 
 var baseURL string
 
+// сервис SLA нужен тестам напрямую, фоновый воркер в тестах не запускается
+var slaService *service.ReviewSLAService
+
+// секрет подписи вебхуков GitHub в тестах
+const testWebhookSecret = "test-webhook-secret"
+
 // main для тестов
 func TestMain(m *testing.M) {
 	// подключение к тестовой БД
@@ -33,21 +39,48 @@ func TestMain(m *testing.M) {
 	// репозитории
 	userRepo := repository.NewUserRepo(dbConn, mockLogger)
 	teamRepo := repository.NewTeamRepo(dbConn, mockLogger)
-	prRepo := repository.NewPullRequestRepo(dbConn)
+	prRepo := repository.NewPullRequestRepo(dbConn, mockLogger)
+	ownershipRepo := repository.NewOwnershipRepo(dbConn, mockLogger)
+	absenceRepo := repository.NewAbsenceRepo(dbConn, mockLogger)
+	exclusionRepo := repository.NewExclusionRepo(dbConn, mockLogger)
+	snapshotRepo := repository.NewSnapshotRepo(dbConn, mockLogger)
+	identityRepo := repository.NewIdentityRepo(dbConn, mockLogger)
+	deliveryRepo := repository.NewDeliveryRepo(dbConn, mockLogger)
+
+	// стратегии выбора ревьюверов
+	selectors, err := service.NewReviewerSelectors(service.StrategyLeastLoaded, 1, prRepo)
+	if err != nil {
+		fmt.Printf("failed to init reviewer selectors: %v\n", err)
+		os.Exit(1)
+	}
 
 	// сервисы
 	userService := service.NewUserService(userRepo, mockLogger)
 	teamService := service.NewTeamService(teamRepo, dbConn, mockLogger)
-	prService := service.NewPullRequestService(prRepo, userService, mockLogger)
+	ownershipService := service.NewOwnershipService(ownershipRepo, mockLogger)
+	prService := service.NewPullRequestService(prRepo, userService, teamService, ownershipService, exclusionRepo, selectors, mockLogger)
+	absenceService := service.NewAbsenceService(absenceRepo, userService, prService, mockLogger)
+	exclusionService := service.NewExclusionService(exclusionRepo, userService, prService, mockLogger)
+	snapshotService := service.NewSnapshotService(snapshotRepo, mockLogger)
+	githubService := service.NewGitHubService(identityRepo, deliveryRepo, userService, prService, testWebhookSecret, mockLogger)
+	slaService = service.NewReviewSLAService(prRepo, prService, mockLogger)
+	lifecycleService := service.NewUserLifecycleService(dbConn, userRepo, prRepo, teamService, prService, mockLogger)
 
 	// хэндлеры
 	userHandler := handler.NewUserHandler(userService, mockLogger)
 	teamHandler := handler.NewTeamHandler(teamService, prService, userService, mockLogger)
 	prHandler := handler.NewPullRequestHandler(prService, mockLogger)
 	statsHandler := handler.NewStatsHandler(prService, userService, teamService, mockLogger)
+	ownershipHandler := handler.NewOwnershipHandler(ownershipService, mockLogger)
+	absenceHandler := handler.NewAbsenceHandler(absenceService, mockLogger)
+	reviewHandler := handler.NewReviewHandler(slaService, mockLogger)
+	lifecycleHandler := handler.NewUserLifecycleHandler(lifecycleService, mockLogger)
+	exclusionHandler := handler.NewExclusionHandler(exclusionService, mockLogger)
+	snapshotHandler := handler.NewSnapshotHandler(snapshotService, mockLogger)
+	githubHandler := handler.NewGitHubHandler(githubService, mockLogger)
 
 	// роутер
-	router := router.NewRouter(userHandler, teamHandler, prHandler, statsHandler)
+	router := router.NewRouter(userHandler, teamHandler, prHandler, statsHandler, ownershipHandler, absenceHandler, reviewHandler, lifecycleHandler, exclusionHandler, snapshotHandler, githubHandler)
 
 	// сервер
 	addr := ":8081"
@@ -0,0 +1,74 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestCreatePRWithCodeOwners(t *testing.T) {
+	// чистим бд
+	ResetDB()
+
+	postJSON(t, "/team/add", map[string]interface{}{
+		"team_name": "app",
+		"members": []map[string]interface{}{
+			{"user_id": "u1", "username": "Author", "is_active": true},
+			{"user_id": "u2", "username": "Teammate", "is_active": true},
+		},
+	})
+	postJSON(t, "/team/add", map[string]interface{}{
+		"team_name": "docs",
+		"members": []map[string]interface{}{
+			{"user_id": "u3", "username": "Writer", "is_active": true},
+		},
+	})
+
+	// битый файл отклоняется
+	status, body := postJSON(t, "/ownership/upload", map[string]string{
+		"repository": "monorepo", "content": "*.md owner-without-at\n",
+	})
+	if status != http.StatusBadRequest || errorCode(t, body) != "INVALID_INPUT" {
+		t.Fatalf("expected 400 INVALID_INPUT, got %d, body: %s", status, string(body))
+	}
+
+	status, body = postJSON(t, "/ownership/upload", map[string]string{
+		"repository": "monorepo", "content": "# docs\n/docs/ @org/docs\n",
+	})
+	if status != http.StatusOK {
+		t.Fatalf("expected status %d, got %d, body: %s", http.StatusOK, status, string(body))
+	}
+
+	// владелец docs назначается вместе с ревьювером из команды автора
+	status, body = postJSON(t, "/pullRequest/create", map[string]interface{}{
+		"pull_request_id":   "pr-owners",
+		"pull_request_name": "docs update",
+		"author_id":         "u1",
+		"repository":        "monorepo",
+		"changed_files":     []string{"docs/readme.md", "main.go"},
+	})
+	if status != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d, body: %s", http.StatusCreated, status, string(body))
+	}
+
+	var resp struct {
+		PR struct {
+			AssignedReviewers []string `json:"assigned_reviewers"`
+			ChangedFiles      []string `json:"changed_files"`
+		} `json:"pr"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatalf("failed to decode json: %v", err)
+	}
+
+	reviewers := map[string]bool{}
+	for _, id := range resp.PR.AssignedReviewers {
+		reviewers[id] = true
+	}
+	if !reviewers["u3"] || !reviewers["u2"] || len(reviewers) != 2 {
+		t.Fatalf("expected reviewers u2 and u3, got %v", resp.PR.AssignedReviewers)
+	}
+	if len(resp.PR.ChangedFiles) != 2 {
+		t.Fatalf("expected 2 changed files, got %v", resp.PR.ChangedFiles)
+	}
+}
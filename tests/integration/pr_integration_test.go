@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"io"
 	"net/http"
+	"slices"
+	"sync"
 	"testing"
 )
 
@@ -119,3 +121,734 @@ func TestCreatePR(t *testing.T) {
 		})
 	}
 }
+
+// тестируем выбор наименее загруженных ревьюверов
+func TestCreatePRLeastLoaded(t *testing.T) {
+	// чистим бд
+	ResetDB()
+
+	// создаём команду
+	teamPayload := map[string]interface{}{
+		"team_name": "team_least_loaded",
+		"members": []map[string]interface{}{
+			{"user_id": "u1", "username": "Author", "is_active": true},
+			{"user_id": "u2", "username": "Second", "is_active": true},
+			{"user_id": "u3", "username": "Third", "is_active": true},
+			{"user_id": "u4", "username": "Fourth", "is_active": true},
+		},
+	}
+	body, _ := json.Marshal(teamPayload)
+	resp, err := http.Post(baseURL+"/team/add", "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("failed to create team: %v", err)
+	}
+	resp.Body.Close()
+
+	// кейсы: при равной загрузке берется меньший user_id
+	cases := []struct {
+		name string
+		prID string
+		want []string
+	}{
+		{"все_свободны", "pr-ll-1", []string{"u2", "u3"}},
+		{"u4_свободнее_остальных", "pr-ll-2", []string{"u4", "u2"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			payload := map[string]string{"pull_request_id": c.prID, "pull_request_name": c.name, "author_id": "u1"}
+			body, _ := json.Marshal(payload)
+			resp, err := http.Post(baseURL+"/pullRequest/create", "application/json", bytes.NewBuffer(body))
+			if err != nil {
+				t.Fatalf("request error: %v", err)
+			}
+			defer resp.Body.Close()
+
+			bodyBytes, _ := io.ReadAll(resp.Body)
+			if resp.StatusCode != http.StatusCreated {
+				t.Fatalf("expected status %d, got %d, body: %s", http.StatusCreated, resp.StatusCode, string(bodyBytes))
+			}
+
+			var okResp struct {
+				PR struct {
+					AssignedReviewers []string `json:"assigned_reviewers"`
+				} `json:"pr"`
+			}
+			if err := json.Unmarshal(bodyBytes, &okResp); err != nil {
+				t.Fatalf("failed to decode success json: %v. raw=%s", err, string(bodyBytes))
+			}
+
+			// assert
+			got := okResp.PR.AssignedReviewers
+			if len(got) != len(c.want) {
+				t.Fatalf("expected reviewers %v, got %v", c.want, got)
+			}
+			for i := range c.want {
+				if got[i] != c.want[i] {
+					t.Errorf("expected reviewers %v, got %v", c.want, got)
+					break
+				}
+			}
+		})
+	}
+}
+
+// тестируем добор ревьюверов из резервной команды
+func TestCreatePRFallbackTeam(t *testing.T) {
+	// чистим бд
+	ResetDB()
+
+	// команда автора без кандидатов и резервная команда
+	teams := []map[string]interface{}{
+		{
+			"team_name": "tiny_team",
+			"members":   []map[string]interface{}{{"user_id": "u1", "username": "Author", "is_active": true}},
+		},
+		{
+			"team_name": "partner_team",
+			"members":   []map[string]interface{}{{"user_id": "u9", "username": "Partner", "is_active": true}},
+		},
+	}
+	for _, team := range teams {
+		body, _ := json.Marshal(team)
+		resp, err := http.Post(baseURL+"/team/add", "application/json", bytes.NewBuffer(body))
+		if err != nil {
+			t.Fatalf("failed to create team: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	settings, _ := json.Marshal(map[string]interface{}{
+		"team_name":        "tiny_team",
+		"allow_cross_team": true,
+		"fallback_teams":   []string{"partner_team"},
+	})
+	resp, err := http.Post(baseURL+"/team/settings", "application/json", bytes.NewBuffer(settings))
+	if err != nil {
+		t.Fatalf("failed to update settings: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	body, _ := json.Marshal(map[string]string{"pull_request_id": "pr-fallback", "pull_request_name": "fallback", "author_id": "u1"})
+	resp, err = http.Post(baseURL+"/pullRequest/create", "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("request error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var okResp struct {
+		PR struct {
+			AssignedReviewers []string `json:"assigned_reviewers"`
+			FallbackReviewers []string `json:"fallback_reviewers"`
+		} `json:"pr"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&okResp); err != nil {
+		t.Fatalf("failed to decode success json: %v", err)
+	}
+
+	// assert
+	if len(okResp.PR.AssignedReviewers) != 1 || okResp.PR.AssignedReviewers[0] != "u9" {
+		t.Errorf("expected reviewers [u9], got %v", okResp.PR.AssignedReviewers)
+	}
+	if len(okResp.PR.FallbackReviewers) != 1 || okResp.PR.FallbackReviewers[0] != "u9" {
+		t.Errorf("expected fallback reviewers [u9], got %v", okResp.PR.FallbackReviewers)
+	}
+}
+
+// тестируем закрытие и повторное открытие PR
+func TestCloseReopenPR(t *testing.T) {
+	// чистим бд
+	ResetDB()
+
+	postJSON(t, "/team/add", map[string]interface{}{
+		"team_name": "close_team",
+		"members": []map[string]interface{}{
+			{"user_id": "u1", "username": "Author", "is_active": true},
+			{"user_id": "u2", "username": "Reviewer", "is_active": true},
+		},
+	})
+	postJSON(t, "/pullRequest/create", map[string]string{"pull_request_id": "pr-close", "pull_request_name": "close", "author_id": "u1"})
+
+	// шаги выполняются последовательно
+	steps := []struct {
+		name       string
+		path       string
+		wantStatus int
+		wantPR     string
+		wantCode   string
+	}{
+		{"закрытие", "/pullRequest/close", http.StatusOK, "CLOSED", ""},
+		{"повторное_закрытие", "/pullRequest/close", http.StatusOK, "CLOSED", ""},
+		{"merge_закрытого", "/pullRequest/merge", http.StatusConflict, "", "PR_CLOSED"},
+		{"открытие", "/pullRequest/reopen", http.StatusOK, "OPEN", ""},
+		{"merge", "/pullRequest/merge", http.StatusOK, "MERGED", ""},
+		{"закрытие_слитого", "/pullRequest/close", http.StatusConflict, "", "PR_MERGED"},
+		{"открытие_слитого", "/pullRequest/reopen", http.StatusConflict, "", "PR_MERGED"},
+	}
+
+	for _, step := range steps {
+		status, body := postJSON(t, step.path, map[string]string{"pull_request_id": "pr-close"})
+
+		// assert
+		if status != step.wantStatus {
+			t.Fatalf("%s: expected status %d, got %d, body: %s", step.name, step.wantStatus, status, string(body))
+		}
+		if step.wantCode != "" {
+			if code := errorCode(t, body); code != step.wantCode {
+				t.Fatalf("%s: expected code %s, got %s", step.name, step.wantCode, code)
+			}
+			continue
+		}
+
+		var okResp struct {
+			PR struct {
+				Status string `json:"status"`
+			} `json:"pr"`
+		}
+		if err := json.Unmarshal(body, &okResp); err != nil {
+			t.Fatalf("%s: failed to decode json: %v", step.name, err)
+		}
+		if okResp.PR.Status != step.wantPR {
+			t.Fatalf("%s: expected status %s, got %s", step.name, step.wantPR, okResp.PR.Status)
+		}
+
+		// закрытый PR пропадает из очереди ревьювера
+		if step.wantPR == "CLOSED" {
+			_, reviewBody := getJSON(t, "/users/getReview?user_id=u2")
+			var reviewResp struct {
+				PullRequests []interface{} `json:"pull_requests"`
+			}
+			if err := json.Unmarshal(reviewBody, &reviewResp); err != nil {
+				t.Fatalf("failed to decode review json: %v", err)
+			}
+			if len(reviewResp.PullRequests) != 0 {
+				t.Fatalf("expected empty review queue, got %v", reviewResp.PullRequests)
+			}
+		}
+	}
+}
+
+// тестируем замену при повторном открытии ревьюверов, выбывших, пока PR был закрыт
+func TestReopenRestaffsReviewers(t *testing.T) {
+	// чистим бд
+	ResetDB()
+
+	postJSON(t, "/team/add", map[string]interface{}{
+		"team_name": "reopen_team",
+		"members": []map[string]interface{}{
+			{"user_id": "u1", "username": "Author", "is_active": true},
+			{"user_id": "u2", "username": "Reviewer", "is_active": true},
+			{"user_id": "u3", "username": "Reviewer", "is_active": true},
+			{"user_id": "u4", "username": "Reviewer", "is_active": true},
+		},
+	})
+	postJSON(t, "/pullRequest/create", map[string]string{"pull_request_id": "pr-reopen", "pull_request_name": "reopen", "author_id": "u1"})
+	before := reviewersOf(t, "pr-reopen")
+	if len(before) != 2 {
+		t.Fatalf("expected 2 reviewers, got %v", before)
+	}
+
+	postJSON(t, "/pullRequest/close", map[string]string{"pull_request_id": "pr-reopen"})
+	// закрытый PR не переназначается при деактивации ревьювера
+	postJSON(t, "/users/setIsActive", map[string]interface{}{"user_id": before[0], "is_active": false})
+	if got := reviewersOf(t, "pr-reopen"); !slices.Contains(got, before[0]) {
+		t.Fatalf("expected %s to stay on closed PR, got %v", before[0], got)
+	}
+
+	status, body := postJSON(t, "/pullRequest/reopen", map[string]string{"pull_request_id": "pr-reopen"})
+	if status != http.StatusOK {
+		t.Fatalf("expected status 200, got %d, body: %s", status, string(body))
+	}
+
+	got := reviewersOf(t, "pr-reopen")
+	if len(got) != 2 || slices.Contains(got, before[0]) || !slices.Contains(got, before[1]) {
+		t.Fatalf("expected %s replaced and %s kept, got %v", before[0], before[1], got)
+	}
+}
+
+// тестируем черновик с отложенным назначением ревьюверов
+func TestDraftPR(t *testing.T) {
+	// чистим бд
+	ResetDB()
+
+	postJSON(t, "/team/add", map[string]interface{}{
+		"team_name": "draft_team",
+		"members": []map[string]interface{}{
+			{"user_id": "u1", "username": "Author", "is_active": true},
+			{"user_id": "u2", "username": "Reviewer", "is_active": true},
+		},
+	})
+
+	type prResp struct {
+		PR struct {
+			IsDraft           bool     `json:"is_draft"`
+			AssignedReviewers []string `json:"assigned_reviewers"`
+		} `json:"pr"`
+	}
+
+	// черновик создается без ревьюверов
+	status, body := postJSON(t, "/pullRequest/create", map[string]interface{}{
+		"pull_request_id": "pr-draft", "pull_request_name": "draft", "author_id": "u1", "is_draft": true,
+	})
+	if status != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d, body: %s", http.StatusCreated, status, string(body))
+	}
+	var created prResp
+	if err := json.Unmarshal(body, &created); err != nil {
+		t.Fatalf("failed to decode json: %v", err)
+	}
+	if !created.PR.IsDraft || len(created.PR.AssignedReviewers) != 0 {
+		t.Fatalf("expected draft without reviewers, got %+v", created.PR)
+	}
+
+	// черновик нельзя слить
+	status, body = postJSON(t, "/pullRequest/merge", map[string]string{"pull_request_id": "pr-draft"})
+	if status != http.StatusConflict || errorCode(t, body) != "PR_DRAFT" {
+		t.Fatalf("expected 409 PR_DRAFT, got %d, body: %s", status, string(body))
+	}
+
+	// после ready назначается ревьювер
+	status, body = postJSON(t, "/pullRequest/ready", map[string]string{"pull_request_id": "pr-draft"})
+	if status != http.StatusOK {
+		t.Fatalf("expected status %d, got %d, body: %s", http.StatusOK, status, string(body))
+	}
+	var ready prResp
+	if err := json.Unmarshal(body, &ready); err != nil {
+		t.Fatalf("failed to decode json: %v", err)
+	}
+	if ready.PR.IsDraft || len(ready.PR.AssignedReviewers) != 1 || ready.PR.AssignedReviewers[0] != "u2" {
+		t.Fatalf("expected ready PR with reviewer u2, got %+v", ready.PR)
+	}
+}
+
+func TestCreatePRPrefersTaggedReviewers(t *testing.T) {
+	// чистим бд
+	ResetDB()
+
+	postJSON(t, "/team/add", map[string]interface{}{
+		"team_name": "tags_team",
+		"members": []map[string]interface{}{
+			{"user_id": "u1", "username": "Author", "is_active": true},
+			{"user_id": "u2", "username": "Frontend", "is_active": true},
+			{"user_id": "u3", "username": "Backend", "is_active": true},
+		},
+	})
+	postJSON(t, "/team/settings", map[string]interface{}{
+		"team_name": "tags_team", "reviewers_count": 1, "strategy": "first_n",
+	})
+
+	status, body := postJSON(t, "/users/setTags", map[string]interface{}{
+		"user_id": "u3", "tags": []string{" DB ", "go", "go"},
+	})
+	if status != http.StatusOK {
+		t.Fatalf("expected status %d, got %d, body: %s", http.StatusOK, status, string(body))
+	}
+	var userResp struct {
+		User struct {
+			Tags []string `json:"tags"`
+		} `json:"user"`
+	}
+	if err := json.Unmarshal(body, &userResp); err != nil {
+		t.Fatalf("failed to decode json: %v", err)
+	}
+	if len(userResp.User.Tags) != 2 || userResp.User.Tags[0] != "db" {
+		t.Fatalf("expected normalized tags [db go], got %v", userResp.User.Tags)
+	}
+
+	type prResp struct {
+		PR struct {
+			AssignedReviewers []string `json:"assigned_reviewers"`
+			Labels            []string `json:"labels"`
+		} `json:"pr"`
+	}
+
+	// first_n без меток взял бы u2, метка db отдает PR u3
+	status, body = postJSON(t, "/pullRequest/create", map[string]interface{}{
+		"pull_request_id": "pr-db", "pull_request_name": "migration", "author_id": "u1", "labels": []string{"db"},
+	})
+	if status != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d, body: %s", http.StatusCreated, status, string(body))
+	}
+	var tagged prResp
+	if err := json.Unmarshal(body, &tagged); err != nil {
+		t.Fatalf("failed to decode json: %v", err)
+	}
+	if len(tagged.PR.AssignedReviewers) != 1 || tagged.PR.AssignedReviewers[0] != "u3" {
+		t.Fatalf("expected tagged reviewer u3, got %v", tagged.PR.AssignedReviewers)
+	}
+
+	// без совпадений берется любой активный участник
+	status, body = postJSON(t, "/pullRequest/create", map[string]interface{}{
+		"pull_request_id": "pr-ui", "pull_request_name": "button", "author_id": "u1", "labels": []string{"frontend"},
+	})
+	if status != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d, body: %s", http.StatusCreated, status, string(body))
+	}
+	var untagged prResp
+	if err := json.Unmarshal(body, &untagged); err != nil {
+		t.Fatalf("failed to decode json: %v", err)
+	}
+	if len(untagged.PR.AssignedReviewers) != 1 {
+		t.Fatalf("expected fallback to any teammate, got %v", untagged.PR.AssignedReviewers)
+	}
+
+	// метки можно заменить
+	status, body = postJSON(t, "/pullRequest/setLabels", map[string]interface{}{
+		"pull_request_id": "pr-ui", "labels": []string{"Frontend", "go"},
+	})
+	if status != http.StatusOK {
+		t.Fatalf("expected status %d, got %d, body: %s", http.StatusOK, status, string(body))
+	}
+	var relabeled prResp
+	if err := json.Unmarshal(body, &relabeled); err != nil {
+		t.Fatalf("failed to decode json: %v", err)
+	}
+	if len(relabeled.PR.Labels) != 2 || relabeled.PR.Labels[0] != "frontend" {
+		t.Fatalf("expected labels [frontend go], got %v", relabeled.PR.Labels)
+	}
+
+	// метки закрытого PR не меняются
+	postJSON(t, "/pullRequest/close", map[string]interface{}{"pull_request_id": "pr-ui"})
+	status, body = postJSON(t, "/pullRequest/setLabels", map[string]interface{}{
+		"pull_request_id": "pr-ui", "labels": []string{"db"},
+	})
+	if status != http.StatusConflict {
+		t.Fatalf("expected status %d, got %d, body: %s", http.StatusConflict, status, string(body))
+	}
+}
+
+func TestReviewerCapacity(t *testing.T) {
+	// чистим бд
+	ResetDB()
+
+	postJSON(t, "/team/add", map[string]interface{}{
+		"team_name": "capacity_team",
+		"members": []map[string]interface{}{
+			{"user_id": "u1", "username": "Author", "is_active": true},
+			{"user_id": "u2", "username": "Senior", "is_active": true},
+			{"user_id": "u3", "username": "Junior", "is_active": true},
+		},
+	})
+	postJSON(t, "/team/settings", map[string]interface{}{
+		"team_name": "capacity_team", "reviewers_count": 2, "max_open_reviews": 1,
+	})
+
+	// личный лимит перекрывает командный
+	status, body := postJSON(t, "/users/setCapacity", map[string]interface{}{"user_id": "u3", "max_open_reviews": 2})
+	if status != http.StatusOK {
+		t.Fatalf("expected status %d, got %d, body: %s", http.StatusOK, status, string(body))
+	}
+
+	type prResp struct {
+		PR struct {
+			AssignedReviewers []string `json:"assigned_reviewers"`
+			Warnings          []string `json:"warnings"`
+		} `json:"pr"`
+	}
+	create := func(id string) prResp {
+		status, body := postJSON(t, "/pullRequest/create", map[string]interface{}{
+			"pull_request_id": id, "pull_request_name": id, "author_id": "u1",
+		})
+		if status != http.StatusCreated {
+			t.Fatalf("expected status %d, got %d, body: %s", http.StatusCreated, status, string(body))
+		}
+		var resp prResp
+		if err := json.Unmarshal(body, &resp); err != nil {
+			t.Fatalf("failed to decode json: %v", err)
+		}
+		return resp
+	}
+
+	first := create("pr-cap-1")
+	if len(first.PR.AssignedReviewers) != 2 || len(first.PR.Warnings) != 0 {
+		t.Fatalf("expected full staffing, got %+v", first.PR)
+	}
+
+	// u2 уперся в лимит команды, остается только u3
+	second := create("pr-cap-2")
+	if len(second.PR.AssignedReviewers) != 1 || second.PR.AssignedReviewers[0] != "u3" {
+		t.Fatalf("expected only u3, got %v", second.PR.AssignedReviewers)
+	}
+	if len(second.PR.Warnings) != 1 || second.PR.Warnings[0] != "REVIEWERS_AT_CAPACITY" {
+		t.Fatalf("expected capacity warning, got %v", second.PR.Warnings)
+	}
+
+	// свободных нет: переназначение отказывает отдельной ошибкой
+	status, body = postJSON(t, "/pullRequest/reassign", map[string]string{
+		"pull_request_id": "pr-cap-2", "old_user_id": "u3",
+	})
+	if status != http.StatusConflict || errorCode(t, body) != "REVIEWERS_AT_CAPACITY" {
+		t.Fatalf("expected 409 REVIEWERS_AT_CAPACITY, got %d, body: %s", status, string(body))
+	}
+}
+
+// тестируем переназначение на выбранного пользователя и исключение автора при автоматической замене
+func TestReassignToExplicitReviewer(t *testing.T) {
+	// чистим бд
+	ResetDB()
+
+	postJSON(t, "/team/add", map[string]interface{}{
+		"team_name": "alpha",
+		"members": []map[string]interface{}{
+			{"user_id": "u1", "username": "Author", "is_active": true},
+			{"user_id": "u2", "username": "Bob", "is_active": true},
+			{"user_id": "u3", "username": "Carol", "is_active": true},
+			{"user_id": "u4", "username": "Dave", "is_active": true},
+		},
+	})
+	postJSON(t, "/team/add", map[string]interface{}{
+		"team_name": "beta",
+		"members":   []map[string]interface{}{{"user_id": "u9", "username": "Outsider", "is_active": true}},
+	})
+
+	status, body := postJSON(t, "/pullRequest/create", map[string]interface{}{
+		"pull_request_id": "pr-explicit", "pull_request_name": "explicit", "author_id": "u1",
+	})
+	if status != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d, body: %s", http.StatusCreated, status, string(body))
+	}
+
+	type reassignResp struct {
+		PR struct {
+			AssignedReviewers []string `json:"assigned_reviewers"`
+		} `json:"pr"`
+		ReplacedBy string `json:"replaced_by"`
+	}
+	decode := func(body []byte) reassignResp {
+		var resp reassignResp
+		if err := json.Unmarshal(body, &resp); err != nil {
+			t.Fatalf("failed to decode json: %v", err)
+		}
+		return resp
+	}
+
+	// автоматическая замена не берет автора и второго ревьювера
+	status, body = postJSON(t, "/pullRequest/reassign", map[string]string{
+		"pull_request_id": "pr-explicit", "old_user_id": "u2",
+	})
+	if status != http.StatusOK {
+		t.Fatalf("expected status %d, got %d, body: %s", http.StatusOK, status, string(body))
+	}
+	auto := decode(body)
+	if auto.ReplacedBy != "u4" {
+		t.Fatalf("expected u4 as replacement, got %s", auto.ReplacedBy)
+	}
+	if !slices.Equal(slices.Sorted(slices.Values(auto.PR.AssignedReviewers)), []string{"u3", "u4"}) {
+		t.Fatalf("expected reviewers u3 and u4, got %v", auto.PR.AssignedReviewers)
+	}
+
+	postJSON(t, "/users/setIsActive", map[string]interface{}{"user_id": "u2", "is_active": false})
+
+	cases := []struct {
+		newUser    string
+		wantStatus int
+		wantCode   string
+	}{
+		{"u1", http.StatusConflict, "REVIEWER_IS_AUTHOR"},
+		{"u3", http.StatusConflict, "ALREADY_ASSIGNED"},
+		{"u2", http.StatusConflict, "REVIEWER_INACTIVE"},
+		{"u9", http.StatusConflict, "TEAM_NOT_ALLOWED"},
+		{"ghost", http.StatusNotFound, "NOT_FOUND"},
+	}
+	for _, tc := range cases {
+		status, body := postJSON(t, "/pullRequest/reassign", map[string]string{
+			"pull_request_id": "pr-explicit", "old_user_id": "u4", "new_user_id": tc.newUser,
+		})
+		if status != tc.wantStatus || errorCode(t, body) != tc.wantCode {
+			t.Errorf("%s: expected %d %s, got %d, body: %s", tc.newUser, tc.wantStatus, tc.wantCode, status, string(body))
+		}
+	}
+
+	// явное разрешение снимает ограничение по командам
+	status, body = postJSON(t, "/pullRequest/reassign", map[string]interface{}{
+		"pull_request_id": "pr-explicit", "old_user_id": "u4", "new_user_id": "u9", "allow_any_team": true,
+	})
+	if status != http.StatusOK {
+		t.Fatalf("expected status %d, got %d, body: %s", http.StatusOK, status, string(body))
+	}
+	explicit := decode(body)
+	if explicit.ReplacedBy != "u9" || !slices.Equal(slices.Sorted(slices.Values(explicit.PR.AssignedReviewers)), []string{"u3", "u9"}) {
+		t.Fatalf("expected u9 to replace u4, got %+v", explicit)
+	}
+}
+
+// тестируем ручное добавление и снятие ревьюверов
+func TestAddRemoveReviewers(t *testing.T) {
+	// чистим бд
+	ResetDB()
+
+	postJSON(t, "/team/add", map[string]interface{}{
+		"team_name": "manual_team",
+		"members": []map[string]interface{}{
+			{"user_id": "u1", "username": "Author", "is_active": true},
+			{"user_id": "u2", "username": "Bob", "is_active": true},
+			{"user_id": "u3", "username": "Carol", "is_active": true},
+			{"user_id": "u4", "username": "Dave", "is_active": true},
+			{"user_id": "u5", "username": "Eve", "is_active": true},
+		},
+	})
+	status, body := postJSON(t, "/team/settings", map[string]interface{}{"team_name": "manual_team", "max_reviewers": 3})
+	if status != http.StatusOK {
+		t.Fatalf("expected status %d, got %d, body: %s", http.StatusOK, status, string(body))
+	}
+	postJSON(t, "/pullRequest/create", map[string]interface{}{
+		"pull_request_id": "pr-manual", "pull_request_name": "manual", "author_id": "u1",
+	})
+
+	reviewers := func(body []byte) []string {
+		var resp struct {
+			PR struct {
+				AssignedReviewers []string `json:"assigned_reviewers"`
+			} `json:"pr"`
+		}
+		if err := json.Unmarshal(body, &resp); err != nil {
+			t.Fatalf("failed to decode json: %v", err)
+		}
+		return resp.PR.AssignedReviewers
+	}
+	change := func(path, userID string) (int, []byte) {
+		return postJSON(t, path, map[string]string{"pull_request_id": "pr-manual", "user_id": userID})
+	}
+
+	status, body = change("/pullRequest/reviewers/add", "u4")
+	if status != http.StatusOK {
+		t.Fatalf("expected status %d, got %d, body: %s", http.StatusOK, status, string(body))
+	}
+	if got := reviewers(body); len(got) != 3 || !slices.Contains(got, "u4") {
+		t.Fatalf("expected u4 added to reviewers, got %v", got)
+	}
+
+	cases := []struct {
+		path       string
+		userID     string
+		wantStatus int
+		wantCode   string
+	}{
+		{"/pullRequest/reviewers/add", "u5", http.StatusConflict, "TOO_MANY_REVIEWERS"},
+		{"/pullRequest/reviewers/add", "u1", http.StatusConflict, "REVIEWER_IS_AUTHOR"},
+		{"/pullRequest/reviewers/add", "u2", http.StatusConflict, "ALREADY_ASSIGNED"},
+		{"/pullRequest/reviewers/add", "ghost", http.StatusNotFound, "NOT_FOUND"},
+		{"/pullRequest/reviewers/remove", "u5", http.StatusConflict, "NOT_ASSIGNED"},
+	}
+	for _, tc := range cases {
+		status, body := change(tc.path, tc.userID)
+		if status != tc.wantStatus || errorCode(t, body) != tc.wantCode {
+			t.Errorf("%s %s: expected %d %s, got %d, body: %s", tc.path, tc.userID, tc.wantStatus, tc.wantCode, status, string(body))
+		}
+	}
+
+	status, body = change("/pullRequest/reviewers/remove", "u2")
+	if status != http.StatusOK {
+		t.Fatalf("expected status %d, got %d, body: %s", http.StatusOK, status, string(body))
+	}
+	if got := reviewers(body); len(got) != 2 || slices.Contains(got, "u2") {
+		t.Fatalf("expected u2 removed from reviewers, got %v", got)
+	}
+
+	// параллельные добавления на последнее место: лимит пересчитывается под блокировкой PR
+	statuses := make([]int, 2)
+	var wg sync.WaitGroup
+	for i, userID := range []string{"u2", "u5"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			statuses[i], _ = change("/pullRequest/reviewers/add", userID)
+		}()
+	}
+	wg.Wait()
+	slices.Sort(statuses)
+	if !slices.Equal(statuses, []int{http.StatusOK, http.StatusConflict}) {
+		t.Fatalf("expected one add to succeed and one to conflict, got %v", statuses)
+	}
+	if got := reviewersOf(t, "pr-manual"); len(got) != 3 {
+		t.Fatalf("expected 3 reviewers, got %v", got)
+	}
+
+	// после merge состав ревьюверов не меняется
+	postJSON(t, "/pullRequest/merge", map[string]string{"pull_request_id": "pr-manual"})
+	status, body = change("/pullRequest/reviewers/add", "u5")
+	if status != http.StatusConflict || errorCode(t, body) != "PR_MERGED" {
+		t.Fatalf("expected 409 PR_MERGED, got %d, body: %s", status, string(body))
+	}
+}
+
+// тестируем выбор ревьюверов из выбранной команды автора
+func TestCreatePRForAuthorTeam(t *testing.T) {
+	// чистим бд
+	ResetDB()
+
+	postJSON(t, "/team/add", map[string]interface{}{
+		"team_name": "mt_alpha",
+		"members": []map[string]interface{}{
+			{"user_id": "ma1", "username": "Author", "is_active": true},
+			{"user_id": "ma2", "username": "Bob", "is_active": true},
+			{"user_id": "ma3", "username": "Carol", "is_active": true},
+		},
+	})
+	postJSON(t, "/team/add", map[string]interface{}{
+		"team_name": "mt_beta",
+		"members": []map[string]interface{}{
+			{"user_id": "mb1", "username": "Dave", "is_active": true},
+			{"user_id": "mb2", "username": "Eve", "is_active": true},
+		},
+	})
+	status, body := postJSON(t, "/team/members/add", map[string]interface{}{
+		"team_name": "mt_beta",
+		"members":   []map[string]interface{}{{"user_id": "ma1", "username": "Author", "is_active": true}},
+	})
+	if status != http.StatusOK {
+		t.Fatalf("expected status %d, got %d, body: %s", http.StatusOK, status, string(body))
+	}
+
+	create := func(prID, teamName string) (int, []byte) {
+		return postJSON(t, "/pullRequest/create", map[string]string{
+			"pull_request_id": prID, "pull_request_name": prID, "author_id": "ma1", "team_name": teamName,
+		})
+	}
+	decode := func(body []byte) (string, []string) {
+		var resp struct {
+			PR struct {
+				TeamName          string   `json:"team_name"`
+				AssignedReviewers []string `json:"assigned_reviewers"`
+			} `json:"pr"`
+		}
+		if err := json.Unmarshal(body, &resp); err != nil {
+			t.Fatalf("failed to decode json: %v", err)
+		}
+		return resp.PR.TeamName, slices.Sorted(slices.Values(resp.PR.AssignedReviewers))
+	}
+
+	// без team_name ревьюверы из основной команды
+	status, body = create("pr-mt-alpha", "")
+	if status != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d, body: %s", http.StatusCreated, status, string(body))
+	}
+	if team, reviewers := decode(body); team != "mt_alpha" || !slices.Equal(reviewers, []string{"ma2", "ma3"}) {
+		t.Fatalf("expected mt_alpha reviewers, got %s %v", team, reviewers)
+	}
+
+	status, body = create("pr-mt-beta", "mt_beta")
+	if status != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d, body: %s", http.StatusCreated, status, string(body))
+	}
+	if team, reviewers := decode(body); team != "mt_beta" || !slices.Equal(reviewers, []string{"mb1", "mb2"}) {
+		t.Fatalf("expected mt_beta reviewers, got %s %v", team, reviewers)
+	}
+
+	status, body = create("pr-mt-other", "mt_gamma")
+	if status != http.StatusConflict || errorCode(t, body) != "AUTHOR_NOT_IN_TEAM" {
+		t.Fatalf("expected 409 AUTHOR_NOT_IN_TEAM, got %d, body: %s", status, string(body))
+	}
+
+	// список по команде PR, а не по основной команде автора
+	status, body = getJSON(t, "/pullRequest/list?team_name=mt_beta")
+	if status != http.StatusOK {
+		t.Fatalf("expected status %d, got %d, body: %s", http.StatusOK, status, string(body))
+	}
+	if !bytes.Contains(body, []byte(`"pr-mt-beta"`)) || bytes.Contains(body, []byte(`"pr-mt-alpha"`)) {
+		t.Fatalf("expected only pr-mt-beta in mt_beta list, got %s", string(body))
+	}
+}
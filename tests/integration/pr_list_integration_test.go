@@ -0,0 +1,116 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+type prListResp struct {
+	PullRequests []struct {
+		PRID              string   `json:"pull_request_id"`
+		AuthorID          string   `json:"author_id"`
+		Status            string   `json:"status"`
+		AssignedReviewers []string `json:"assigned_reviewers"`
+	} `json:"pull_requests"`
+	NextCursor string `json:"next_cursor"`
+}
+
+func listPRs(t *testing.T, query url.Values) prListResp {
+	t.Helper()
+
+	status, body := getJSON(t, "/pullRequest/list?"+query.Encode())
+	if status != http.StatusOK {
+		t.Fatalf("expected status %d, got %d, body: %s", http.StatusOK, status, string(body))
+	}
+	var resp prListResp
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatalf("failed to decode json: %v", err)
+	}
+	return resp
+}
+
+func TestListPRs(t *testing.T) {
+	// чистим бд
+	ResetDB()
+
+	for _, team := range []struct {
+		name    string
+		members []string
+	}{
+		{"list_alpha", []string{"u1", "u2", "u3"}},
+		{"list_beta", []string{"u4", "u5", "u6"}},
+	} {
+		members := []map[string]interface{}{}
+		for _, id := range team.members {
+			members = append(members, map[string]interface{}{"user_id": id, "username": id, "is_active": true})
+		}
+		postJSON(t, "/team/add", map[string]interface{}{"team_name": team.name, "members": members})
+	}
+
+	prs := []struct{ id, author string }{
+		{"pr-l1", "u1"}, {"pr-l2", "u4"}, {"pr-l3", "u1"}, {"pr-l4", "u4"}, {"pr-l5", "u1"},
+	}
+	for _, pr := range prs {
+		status, body := postJSON(t, "/pullRequest/create", map[string]string{
+			"pull_request_id": pr.id, "pull_request_name": pr.id, "author_id": pr.author,
+		})
+		if status != http.StatusCreated {
+			t.Fatalf("expected status %d, got %d, body: %s", http.StatusCreated, status, string(body))
+		}
+	}
+	postJSON(t, "/pullRequest/merge", map[string]string{"pull_request_id": "pr-l3"})
+
+	// постраничный обход возвращает каждый PR ровно один раз, от новых к старым
+	var seen []string
+	query := url.Values{"limit": {"2"}}
+	for page := 0; ; page++ {
+		if page > 5 {
+			t.Fatalf("pagination did not terminate, seen %v", seen)
+		}
+		resp := listPRs(t, query)
+		for _, pr := range resp.PullRequests {
+			seen = append(seen, pr.PRID)
+		}
+		if resp.NextCursor == "" {
+			break
+		}
+		query.Set("cursor", resp.NextCursor)
+	}
+	want := []string{"pr-l5", "pr-l4", "pr-l3", "pr-l2", "pr-l1"}
+	if len(seen) != len(want) {
+		t.Fatalf("expected %v, got %v", want, seen)
+	}
+	for i := range want {
+		if seen[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, seen)
+		}
+	}
+
+	filters := []struct {
+		name  string
+		query url.Values
+		want  int
+	}{
+		{"team", url.Values{"team_name": {"list_beta"}}, 2},
+		{"author", url.Values{"author_id": {"u1"}}, 3},
+		{"status", url.Values{"status": {"MERGED"}}, 1},
+		{"reviewer", url.Values{"reviewer_id": {"u2"}, "status": {"OPEN"}}, 2},
+		{"merged since yesterday", url.Values{"merged_from": {time.Now().Add(-24 * time.Hour).UTC().Format(time.RFC3339)}}, 1},
+		{"created before yesterday", url.Values{"created_to": {time.Now().Add(-24 * time.Hour).UTC().Format(time.RFC3339)}}, 0},
+	}
+	for _, f := range filters {
+		if got := listPRs(t, f.query); len(got.PullRequests) != f.want {
+			t.Errorf("%s: expected %d PRs, got %d", f.name, f.want, len(got.PullRequests))
+		}
+	}
+
+	for _, bad := range []string{"status=DONE", "limit=1000", "cursor=broken", "created_from=yesterday"} {
+		status, body := getJSON(t, "/pullRequest/list?"+bad)
+		if status != http.StatusBadRequest || errorCode(t, body) != "INVALID_INPUT" {
+			t.Errorf("%s: expected 400 INVALID_INPUT, got %d, body: %s", bad, status, string(body))
+		}
+	}
+}
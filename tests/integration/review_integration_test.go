@@ -0,0 +1,181 @@
+package integration
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+)
+
+// тестируем вердикты ревьюверов и кворум перед merge
+func TestMergeQuorum(t *testing.T) {
+	// чистим бд
+	ResetDB()
+
+	// команда с одним ревьювером и кворумом в одно одобрение
+	postJSON(t, "/team/add", map[string]interface{}{
+		"team_name": "quorum_team",
+		"members": []map[string]interface{}{
+			{"user_id": "u1", "username": "Author", "is_active": true},
+			{"user_id": "u2", "username": "Reviewer", "is_active": true},
+		},
+	})
+	postJSON(t, "/team/settings", map[string]interface{}{"team_name": "quorum_team", "approvals_required": 1})
+	postJSON(t, "/pullRequest/create", map[string]string{"pull_request_id": "pr-quorum", "pull_request_name": "quorum", "author_id": "u1"})
+
+	// шаги выполняются последовательно и зависят друг от друга
+	steps := []struct {
+		name     string
+		path     string
+		payload  map[string]string
+		want     int
+		wantCode string
+	}{
+		{"merge_без_одобрений", "/pullRequest/merge", map[string]string{"pull_request_id": "pr-quorum"}, http.StatusConflict, "QUORUM_NOT_MET"},
+		{"ревью_от_не_ревьювера", "/pullRequest/review", map[string]string{"pull_request_id": "pr-quorum", "reviewer_id": "u1", "verdict": "APPROVED"}, http.StatusConflict, "NOT_ASSIGNED"},
+		{"неизвестный_вердикт", "/pullRequest/review", map[string]string{"pull_request_id": "pr-quorum", "reviewer_id": "u2", "verdict": "LGTM"}, http.StatusBadRequest, "INVALID_INPUT"},
+		{"запрос_изменений", "/pullRequest/review", map[string]string{"pull_request_id": "pr-quorum", "reviewer_id": "u2", "verdict": "CHANGES_REQUESTED"}, http.StatusOK, ""},
+		{"merge_с_запросом_изменений", "/pullRequest/merge", map[string]string{"pull_request_id": "pr-quorum"}, http.StatusConflict, "CHANGES_REQUESTED"},
+		{"одобрение", "/pullRequest/review", map[string]string{"pull_request_id": "pr-quorum", "reviewer_id": "u2", "verdict": "APPROVED"}, http.StatusOK, ""},
+		{"merge_с_кворумом", "/pullRequest/merge", map[string]string{"pull_request_id": "pr-quorum"}, http.StatusOK, ""},
+		{"ревью_после_merge", "/pullRequest/review", map[string]string{"pull_request_id": "pr-quorum", "reviewer_id": "u2", "verdict": "COMMENTED"}, http.StatusConflict, "PR_MERGED"},
+	}
+
+	for _, step := range steps {
+		status, body := postJSON(t, step.path, step.payload)
+
+		// assert
+		if status != step.want {
+			t.Fatalf("%s: expected status %d, got %d, body: %s", step.name, step.want, status, string(body))
+		}
+		if step.wantCode != "" {
+			if code := errorCode(t, body); code != step.wantCode {
+				t.Fatalf("%s: expected code %s, got %s", step.name, step.wantCode, code)
+			}
+		}
+	}
+}
+
+// тестируем роли участников и требование одобрения лида
+func TestLeadApproval(t *testing.T) {
+	// чистим бд
+	ResetDB()
+
+	// без лида least_loaded выбрал бы u2 и u3
+	postJSON(t, "/team/add", map[string]interface{}{
+		"team_name": "lead_team",
+		"members": []map[string]interface{}{
+			{"user_id": "u1", "username": "Author", "is_active": true},
+			{"user_id": "u2", "username": "Member", "is_active": true},
+			{"user_id": "u3", "username": "Maintainer", "is_active": true, "role": "maintainer"},
+			{"user_id": "u4", "username": "Lead", "is_active": true, "role": "lead"},
+		},
+	})
+	status, body := postJSON(t, "/team/settings", map[string]interface{}{
+		"team_name": "lead_team", "strategy": "least_loaded", "approvals_required": 1, "require_lead": true,
+	})
+	if status != http.StatusOK {
+		t.Fatalf("expected status %d, got %d, body: %s", http.StatusOK, status, string(body))
+	}
+
+	postJSON(t, "/pullRequest/create", map[string]string{"pull_request_id": "pr-lead", "pull_request_name": "lead", "author_id": "u1"})
+	if reviewers := slices.Sorted(slices.Values(reviewersOf(t, "pr-lead"))); !slices.Equal(reviewers, []string{"u2", "u4"}) {
+		t.Fatalf("expected lead u4 among reviewers, got %v", reviewers)
+	}
+
+	// шаги выполняются последовательно и зависят друг от друга
+	steps := []struct {
+		name     string
+		path     string
+		payload  map[string]string
+		want     int
+		wantCode string
+	}{
+		{"одобрение_участника", "/pullRequest/review", map[string]string{"pull_request_id": "pr-lead", "reviewer_id": "u2", "verdict": "APPROVED"}, http.StatusOK, ""},
+		{"merge_без_лида", "/pullRequest/merge", map[string]string{"pull_request_id": "pr-lead"}, http.StatusConflict, "LEAD_APPROVAL_REQUIRED"},
+		{"одобрение_лида", "/pullRequest/review", map[string]string{"pull_request_id": "pr-lead", "reviewer_id": "u4", "verdict": "APPROVED"}, http.StatusOK, ""},
+		{"merge_с_лидом", "/pullRequest/merge", map[string]string{"pull_request_id": "pr-lead"}, http.StatusOK, ""},
+		{"неизвестная_роль", "/team/members/setRole", map[string]string{"team_name": "lead_team", "user_id": "u4", "role": "owner"}, http.StatusBadRequest, "INVALID_INPUT"},
+		{"роль_не_участника", "/team/members/setRole", map[string]string{"team_name": "lead_team", "user_id": "ghost", "role": "lead"}, http.StatusConflict, "NOT_TEAM_MEMBER"},
+		{"снятие_лида", "/team/members/setRole", map[string]string{"team_name": "lead_team", "user_id": "u4", "role": "member"}, http.StatusOK, ""},
+		{"pr_без_лидов", "/pullRequest/create", map[string]string{"pull_request_id": "pr-no-lead", "pull_request_name": "no lead", "author_id": "u1"}, http.StatusConflict, "LEAD_UNAVAILABLE"},
+		{"новый_лид", "/team/members/setRole", map[string]string{"team_name": "lead_team", "user_id": "u3", "role": "lead"}, http.StatusOK, ""},
+		{"pr_с_новым_лидом", "/pullRequest/create", map[string]string{"pull_request_id": "pr-no-lead", "pull_request_name": "no lead", "author_id": "u1"}, http.StatusCreated, ""},
+	}
+
+	for _, step := range steps {
+		status, body := postJSON(t, step.path, step.payload)
+
+		// assert
+		if status != step.want {
+			t.Fatalf("%s: expected status %d, got %d, body: %s", step.name, step.want, status, string(body))
+		}
+		if step.wantCode != "" {
+			if code := errorCode(t, body); code != step.wantCode {
+				t.Fatalf("%s: expected code %s, got %s", step.name, step.wantCode, code)
+			}
+		}
+	}
+
+	if reviewers := reviewersOf(t, "pr-no-lead"); !slices.Contains(reviewers, "u3") {
+		t.Fatalf("expected new lead u3 among reviewers, got %v", reviewers)
+	}
+}
+
+// тестируем, что последний лид в PR команды с require_lead не теряется при ручной смене ревьюверов
+func TestLastLeadKept(t *testing.T) {
+	// чистим бд
+	ResetDB()
+
+	postJSON(t, "/team/add", map[string]interface{}{
+		"team_name": "lead_team",
+		"members": []map[string]interface{}{
+			{"user_id": "u1", "username": "Author", "is_active": true},
+			{"user_id": "u2", "username": "Member", "is_active": true},
+			{"user_id": "u3", "username": "Lead", "is_active": true, "role": "lead"},
+			{"user_id": "u4", "username": "Member2", "is_active": true},
+			{"user_id": "u5", "username": "Lead2", "is_active": true, "role": "lead"},
+		},
+	})
+	postJSON(t, "/team/settings", map[string]interface{}{"team_name": "lead_team", "require_lead": true})
+	postJSON(t, "/pullRequest/create", map[string]string{"pull_request_id": "pr-lead", "pull_request_name": "lead", "author_id": "u1"})
+	reviewers := reviewersOf(t, "pr-lead")
+	if len(reviewers) != 2 || !slices.Contains(reviewers, "u3") || slices.Contains(reviewers, "u5") {
+		t.Fatalf("expected lead u3 as the only lead among reviewers, got %v", reviewers)
+	}
+	member := reviewers[0]
+	if member == "u3" {
+		member = reviewers[1]
+	}
+
+	// шаги выполняются последовательно и зависят друг от друга
+	steps := []struct {
+		name     string
+		path     string
+		payload  map[string]string
+		want     int
+		wantCode string
+	}{
+		{"снятие_последнего_лида", "/pullRequest/reviewers/remove", map[string]string{"pull_request_id": "pr-lead", "user_id": "u3"}, http.StatusConflict, "LEAD_UNAVAILABLE"},
+		{"замена_лида_участником", "/pullRequest/reassign", map[string]string{"pull_request_id": "pr-lead", "old_user_id": "u3", "new_user_id": "u4"}, http.StatusConflict, "LEAD_UNAVAILABLE"},
+		{"замена_лида_лидом", "/pullRequest/reassign", map[string]string{"pull_request_id": "pr-lead", "old_user_id": "u3", "new_user_id": "u5"}, http.StatusOK, ""},
+		{"снятие_участника", "/pullRequest/reviewers/remove", map[string]string{"pull_request_id": "pr-lead", "user_id": member}, http.StatusOK, ""},
+	}
+
+	for _, step := range steps {
+		status, body := postJSON(t, step.path, step.payload)
+
+		// assert
+		if status != step.want {
+			t.Fatalf("%s: expected status %d, got %d, body: %s", step.name, step.want, status, string(body))
+		}
+		if step.wantCode != "" {
+			if code := errorCode(t, body); code != step.wantCode {
+				t.Fatalf("%s: expected code %s, got %s", step.name, step.wantCode, code)
+			}
+		}
+	}
+
+	if reviewers := reviewersOf(t, "pr-lead"); !slices.Equal(reviewers, []string{"u5"}) {
+		t.Fatalf("expected only lead u5 to remain, got %v", reviewers)
+	}
+}
@@ -0,0 +1,119 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestOverdueReviews(t *testing.T) {
+	// чистим бд
+	ResetDB()
+
+	postJSON(t, "/team/add", map[string]interface{}{
+		"team_name": "sla_team",
+		"members": []map[string]interface{}{
+			{"user_id": "u1", "username": "Author", "is_active": true},
+			{"user_id": "u2", "username": "Slow", "is_active": true},
+		},
+	})
+	postJSON(t, "/team/settings", map[string]interface{}{
+		"team_name": "sla_team", "reviewers_count": 1, "review_sla_hours": 24,
+	})
+	postJSON(t, "/pullRequest/create", map[string]interface{}{
+		"pull_request_id": "pr-sla", "pull_request_name": "slow review", "author_id": "u1",
+	})
+
+	type overdueResp struct {
+		Reviews []struct {
+			PRID       string `json:"pull_request_id"`
+			ReviewerID string `json:"reviewer_id"`
+		} `json:"reviews"`
+	}
+	listOverdue := func() overdueResp {
+		status, body := getJSON(t, "/reviews/overdue")
+		if status != http.StatusOK {
+			t.Fatalf("expected status %d, got %d, body: %s", http.StatusOK, status, string(body))
+		}
+		var resp overdueResp
+		if err := json.Unmarshal(body, &resp); err != nil {
+			t.Fatalf("failed to decode json: %v", err)
+		}
+		return resp
+	}
+
+	// в пределах SLA просрочек нет
+	if err := slaService.CheckOverdue(); err != nil {
+		t.Fatalf("check overdue failed: %v", err)
+	}
+	if resp := listOverdue(); len(resp.Reviews) != 0 {
+		t.Fatalf("expected no overdue reviews, got %+v", resp.Reviews)
+	}
+
+	// сдвигаем назначение на двое суток назад
+	if _, err := testDB.Exec(`UPDATE pull_request_reviewers SET assigned_at = NOW() - interval '48 hours'`); err != nil {
+		t.Fatalf("failed to backdate assignment: %v", err)
+	}
+	if err := slaService.CheckOverdue(); err != nil {
+		t.Fatalf("check overdue failed: %v", err)
+	}
+	resp := listOverdue()
+	if len(resp.Reviews) != 1 || resp.Reviews[0].PRID != "pr-sla" || resp.Reviews[0].ReviewerID != "u2" {
+		t.Fatalf("expected overdue review of u2 on pr-sla, got %+v", resp.Reviews)
+	}
+
+	// вердикт снимает ревью из списка
+	postJSON(t, "/pullRequest/review", map[string]string{
+		"pull_request_id": "pr-sla", "reviewer_id": "u2", "verdict": "COMMENTED",
+	})
+	if resp := listOverdue(); len(resp.Reviews) != 0 {
+		t.Fatalf("expected no overdue reviews after verdict, got %+v", resp.Reviews)
+	}
+}
+
+// тестируем новый отсчет SLA ревью без вердикта при повторном открытии PR
+func TestReopenRestartsReviewSLA(t *testing.T) {
+	// чистим бд
+	ResetDB()
+
+	postJSON(t, "/team/add", map[string]interface{}{
+		"team_name": "sla_team",
+		"members": []map[string]interface{}{
+			{"user_id": "u1", "username": "Author", "is_active": true},
+			{"user_id": "u2", "username": "Slow", "is_active": true},
+		},
+	})
+	postJSON(t, "/team/settings", map[string]interface{}{
+		"team_name": "sla_team", "reviewers_count": 1, "review_sla_hours": 24,
+	})
+	postJSON(t, "/pullRequest/create", map[string]interface{}{
+		"pull_request_id": "pr-sla", "pull_request_name": "slow review", "author_id": "u1",
+	})
+
+	// ревью просрочено до закрытия, а PR пролежал закрытым двое суток
+	if _, err := testDB.Exec(`UPDATE pull_request_reviewers SET assigned_at = NOW() - interval '48 hours', overdue_at = NOW()`); err != nil {
+		t.Fatalf("failed to backdate assignment: %v", err)
+	}
+	postJSON(t, "/pullRequest/close", map[string]string{"pull_request_id": "pr-sla"})
+	status, body := postJSON(t, "/pullRequest/reopen", map[string]string{"pull_request_id": "pr-sla"})
+	if status != http.StatusOK {
+		t.Fatalf("expected status %d, got %d, body: %s", http.StatusOK, status, string(body))
+	}
+
+	if err := slaService.CheckOverdue(); err != nil {
+		t.Fatalf("check overdue failed: %v", err)
+	}
+	status, body = getJSON(t, "/reviews/overdue")
+	if status != http.StatusOK {
+		t.Fatalf("expected status %d, got %d, body: %s", http.StatusOK, status, string(body))
+	}
+	var resp struct {
+		Reviews []interface{} `json:"reviews"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatalf("failed to decode json: %v", err)
+	}
+	if len(resp.Reviews) != 0 {
+		t.Fatalf("expected no overdue reviews after reopen, got %+v", resp.Reviews)
+	}
+}
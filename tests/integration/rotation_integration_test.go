@@ -0,0 +1,88 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+// тестируем память ротации пар автор-ревьювер и матрицу пар
+func TestRotationMemory(t *testing.T) {
+	// чистим бд
+	ResetDB()
+
+	postJSON(t, "/team/add", map[string]interface{}{
+		"team_name": "rotation_team",
+		"members": []map[string]interface{}{
+			{"user_id": "u1", "username": "Author", "is_active": true},
+			{"user_id": "u2", "username": "First", "is_active": true},
+			{"user_id": "u3", "username": "Second", "is_active": true},
+			{"user_id": "u4", "username": "Third", "is_active": true},
+		},
+	})
+	status, body := postJSON(t, "/team/settings", map[string]interface{}{
+		"team_name": "rotation_team", "reviewers_count": 1, "strategy": "least_loaded", "rotation_window_days": 30,
+	})
+	if status != http.StatusOK {
+		t.Fatalf("expected status %d, got %d, body: %s", http.StatusOK, status, string(body))
+	}
+
+	// PR закрываются, чтобы загрузка не влияла на выбор: без памяти каждый раз выбирался бы u2
+	for i, want := range []string{"u2", "u3", "u4"} {
+		prID := "pr-rotation-" + want
+		postJSON(t, "/pullRequest/create", map[string]string{"pull_request_id": prID, "pull_request_name": prID, "author_id": "u1"})
+		if reviewers := reviewersOf(t, prID); len(reviewers) != 1 || reviewers[0] != want {
+			t.Fatalf("PR %d: expected reviewer %s, got %v", i+1, want, reviewers)
+		}
+		postJSON(t, "/pullRequest/close", map[string]string{"pull_request_id": prID})
+	}
+
+	// у другого автора своя история
+	postJSON(t, "/pullRequest/create", map[string]string{"pull_request_id": "pr-rotation-other", "pull_request_name": "other", "author_id": "u3"})
+	if reviewers := reviewersOf(t, "pr-rotation-other"); len(reviewers) != 1 || reviewers[0] != "u1" {
+		t.Fatalf("expected reviewer u1 for another author, got %v", reviewers)
+	}
+
+	status, body = getJSON(t, "/stats/pairs?team_name=rotation_team")
+	if status != http.StatusOK {
+		t.Fatalf("expected status %d, got %d, body: %s", http.StatusOK, status, string(body))
+	}
+	var resp struct {
+		WindowDays int `json:"window_days"`
+		Pairs      []struct {
+			AuthorID   string  `json:"author_id"`
+			ReviewerID string  `json:"reviewer_id"`
+			Reviews    int     `json:"reviews"`
+			Penalty    float64 `json:"penalty"`
+		} `json:"pairs"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatalf("failed to decode json: %v", err)
+	}
+	if resp.WindowDays != 30 || len(resp.Pairs) != 4 {
+		t.Fatalf("unexpected pairs: %s", string(body))
+	}
+	for _, pair := range resp.Pairs {
+		if pair.Reviews != 1 || pair.Penalty <= 0.9 || pair.Penalty > 1 {
+			t.Fatalf("unexpected pair %+v", pair)
+		}
+	}
+
+	// ошибки запроса
+	cases := []struct {
+		url      string
+		want     int
+		wantCode string
+	}{
+		{"/stats/pairs", http.StatusBadRequest, "INVALID_INPUT"},
+		{"/stats/pairs?team_name=rotation_team&window_days=abc", http.StatusBadRequest, "INVALID_INPUT"},
+		{"/stats/pairs?team_name=rotation_team&window_days=1000", http.StatusBadRequest, "INVALID_INPUT"},
+		{"/stats/pairs?team_name=missing", http.StatusNotFound, "NOT_FOUND"},
+	}
+	for _, tc := range cases {
+		status, body := getJSON(t, tc.url)
+		if status != tc.want || errorCode(t, body) != tc.wantCode {
+			t.Fatalf("%s: expected %d %s, got %d, body: %s", tc.url, tc.want, tc.wantCode, status, string(body))
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+// snapshotState - части снимка, которые должны пережить выгрузку и восстановление без изменений
+type snapshotState struct {
+	Teams        json.RawMessage `json:"teams"`
+	Users        json.RawMessage `json:"users"`
+	PullRequests json.RawMessage `json:"pull_requests"`
+}
+
+// takeSnapshot выгружает снимок и возвращает его как есть и разобранным
+func takeSnapshot(t *testing.T) ([]byte, snapshotState) {
+	t.Helper()
+
+	status, body := getJSON(t, "/admin/snapshot")
+	if status != http.StatusOK {
+		t.Fatalf("expected status %d, got %d, body: %s", http.StatusOK, status, string(body))
+	}
+	var state snapshotState
+	if err := json.Unmarshal(body, &state); err != nil {
+		t.Fatalf("failed to decode json: %v", err)
+	}
+	return body, state
+}
+
+// тестируем выгрузку и восстановление снимка состояния
+func TestSnapshotRestore(t *testing.T) {
+	// чистим бд
+	ResetDB()
+
+	postJSON(t, "/team/add", map[string]interface{}{
+		"team_name": "snapshot_team",
+		"members": []map[string]interface{}{
+			{"user_id": "u1", "username": "Author", "is_active": true},
+			{"user_id": "u2", "username": "Lead", "is_active": true, "role": "lead"},
+			{"user_id": "u3", "username": "Member", "is_active": true},
+		},
+	})
+	postJSON(t, "/team/settings", map[string]interface{}{"team_name": "snapshot_team", "reviewers_count": 1})
+	postJSON(t, "/pullRequest/create", map[string]interface{}{
+		"pull_request_id": "pr-snap", "pull_request_name": "snapshot", "author_id": "u1",
+		"repository": "monorepo", "changed_files": []string{"api/handler.go"}, "labels": []string{"go"},
+	})
+	postJSON(t, "/pullRequest/review", map[string]string{"pull_request_id": "pr-snap", "reviewer_id": "u2", "verdict": "APPROVED"})
+
+	snapshot, before := takeSnapshot(t)
+
+	// неудачная массовая операция
+	postJSON(t, "/team/delete", map[string]string{"team_name": "snapshot_team"})
+	postJSON(t, "/team/add", map[string]interface{}{
+		"team_name": "stray_team",
+		"members":   []map[string]interface{}{{"user_id": "u9", "username": "Stray", "is_active": true}},
+	})
+
+	status, body := postRaw(t, "/admin/restore", "application/json", snapshot)
+	if status != http.StatusOK {
+		t.Fatalf("expected status %d, got %d, body: %s", http.StatusOK, status, string(body))
+	}
+	var resp struct {
+		Restored struct {
+			Teams        int `json:"teams"`
+			Users        int `json:"users"`
+			PullRequests int `json:"pull_requests"`
+			Reviewers    int `json:"reviewers"`
+			RetiredUsers int `json:"retired_users"`
+		} `json:"restored"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatalf("failed to decode json: %v", err)
+	}
+	if r := resp.Restored; r.Teams != 1 || r.Users != 3 || r.PullRequests != 1 || r.Reviewers != 1 || r.RetiredUsers != 1 {
+		t.Fatalf("unexpected restore result: %s", string(body))
+	}
+
+	// состояние совпадает со снимком: роли, настройки, файлы, метки, вердикты
+	_, after := takeSnapshot(t)
+	if !bytes.Equal(before.Teams, after.Teams) || !bytes.Equal(before.PullRequests, after.PullRequests) {
+		t.Fatalf("state differs after restore:\nbefore: %s %s\nafter:  %s %s",
+			string(before.Teams), string(before.PullRequests), string(after.Teams), string(after.PullRequests))
+	}
+
+	// пользователь не из снимка остается помеченным удаленным
+	var beforeUsers, afterUsers []map[string]interface{}
+	if err := json.Unmarshal(before.Users, &beforeUsers); err != nil {
+		t.Fatalf("failed to decode json: %v", err)
+	}
+	if err := json.Unmarshal(after.Users, &afterUsers); err != nil {
+		t.Fatalf("failed to decode json: %v", err)
+	}
+	kept := []map[string]interface{}{}
+	for _, u := range afterUsers {
+		if u["user_id"] == "u9" {
+			if u["deleted_at"] == nil || u["is_active"] != false {
+				t.Fatalf("expected u9 to be retired, got %v", u)
+			}
+			continue
+		}
+		kept = append(kept, u)
+	}
+	if !reflect.DeepEqual(beforeUsers, kept) {
+		t.Fatalf("users differ after restore:\nbefore: %v\nafter:  %v", beforeUsers, kept)
+	}
+	if reviewers := reviewersOf(t, "pr-snap"); len(reviewers) != 1 || reviewers[0] != "u2" {
+		t.Fatalf("expected reviewer u2 after restore, got %v", reviewers)
+	}
+	if status, body := getJSON(t, "/team/get?team_name=stray_team"); status != http.StatusNotFound {
+		t.Fatalf("team created after snapshot must be gone, got %d, body: %s", status, string(body))
+	}
+
+	// неподходящий снимок отклоняется целиком
+	cases := []struct {
+		payload  map[string]interface{}
+		wantCode string
+	}{
+		{map[string]interface{}{"version": 2}, "UNSUPPORTED_SNAPSHOT_VERSION"},
+		{map[string]interface{}{
+			"version": 1,
+			"users":   []map[string]interface{}{{"user_id": "u1", "username": "Author", "team_name": "missing"}},
+			"pull_requests": []map[string]interface{}{
+				{"pull_request_id": "pr-x", "pull_request_name": "x", "author_id": "ghost", "status": "OPEN"},
+			},
+		}, "INVALID_INPUT"},
+		{map[string]interface{}{
+			"version": 1,
+			"users":   []map[string]interface{}{{"user_id": "u1", "username": "Author"}},
+			"pull_requests": []map[string]interface{}{
+				{"pull_request_id": "pr-x", "pull_request_name": "x", "author_id": "u1", "status": "OPEN", "labels": []string{""}, "changed_files": []string{""}},
+			},
+		}, "INVALID_INPUT"},
+	}
+	for _, tc := range cases {
+		status, body := postJSON(t, "/admin/restore", tc.payload)
+		if status != http.StatusBadRequest || errorCode(t, body) != tc.wantCode {
+			t.Fatalf("expected 400 %s, got %d, body: %s", tc.wantCode, status, string(body))
+		}
+	}
+	if _, state := takeSnapshot(t); !bytes.Equal(state.PullRequests, before.PullRequests) {
+		t.Fatalf("rejected snapshot must not change state, got %s", string(state.PullRequests))
+	}
+}
@@ -0,0 +1,120 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type teamImportResp struct {
+	DryRun       bool     `json:"dry_run"`
+	TeamsCreated []string `json:"teams_created"`
+	UsersCreated []string `json:"users_created"`
+	UsersUpdated []string `json:"users_updated"`
+	UsersMoved   []struct {
+		UserID string `json:"user_id"`
+		From   string `json:"from"`
+		To     string `json:"to"`
+	} `json:"users_moved"`
+	Unchanged int `json:"unchanged"`
+}
+
+// importTeams загружает файл команд и разбирает отчет
+func importTeams(t *testing.T, query, contentType, content string) teamImportResp {
+	t.Helper()
+
+	status, body := postRaw(t, "/import/teams"+query, contentType, []byte(content))
+	if status != http.StatusOK {
+		t.Fatalf("expected status %d, got %d, body: %s", http.StatusOK, status, string(body))
+	}
+	var resp teamImportResp
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatalf("failed to decode json: %v", err)
+	}
+	return resp
+}
+
+// тестируем массовый импорт и выгрузку команд
+func TestTeamImportExport(t *testing.T) {
+	// чистим бд
+	ResetDB()
+
+	postJSON(t, "/team/add", map[string]interface{}{
+		"team_name": "alpha",
+		"members": []map[string]interface{}{
+			{"user_id": "u1", "username": "Alice", "is_active": true},
+			{"user_id": "u2", "username": "Bob", "is_active": true},
+		},
+	})
+	postJSON(t, "/team/add", map[string]interface{}{
+		"team_name": "gamma",
+		"members":   []map[string]interface{}{{"user_id": "u4", "username": "Dan", "is_active": true}},
+	})
+
+	file := "team,user_id,username,is_active\n" +
+		"alpha,u1,Alice,true\n" +
+		"alpha,u2,Robert,true\n" +
+		"beta,u3,Carol,true\n" +
+		"beta,u4,Dan,false\n"
+
+	// пробный импорт только считает изменения
+	report := importTeams(t, "?dry_run=true", "text/csv", file)
+	if !report.DryRun || len(report.TeamsCreated) != 1 || len(report.UsersCreated) != 1 || len(report.UsersUpdated) != 1 ||
+		len(report.UsersMoved) != 1 || report.Unchanged != 1 {
+		t.Fatalf("unexpected dry run report: %+v", report)
+	}
+	if move := report.UsersMoved[0]; move.UserID != "u4" || move.From != "gamma" || move.To != "beta" {
+		t.Fatalf("unexpected move: %+v", move)
+	}
+	if status, body := getJSON(t, "/team/get?team_name=beta"); status != http.StatusNotFound {
+		t.Fatalf("dry run must not create team, got %d, body: %s", status, string(body))
+	}
+
+	report = importTeams(t, "", "text/csv", file)
+	if report.DryRun || len(report.TeamsCreated) != 1 || len(report.UsersMoved) != 1 {
+		t.Fatalf("unexpected import report: %+v", report)
+	}
+	status, body := getJSON(t, "/team/get?team_name=beta")
+	var team struct {
+		Members []struct {
+			UserID   string `json:"user_id"`
+			IsActive bool   `json:"is_active"`
+		} `json:"members"`
+	}
+	if err := json.Unmarshal(body, &team); err != nil || status != http.StatusOK || len(team.Members) != 2 {
+		t.Fatalf("expected 2 members in beta, got %d, body: %s", status, string(body))
+	}
+
+	// выгрузка повторно импортируется без изменений в обоих форматах
+	for _, format := range []string{"csv", "yaml"} {
+		status, exported := getJSON(t, "/export/teams?format="+format)
+		if status != http.StatusOK {
+			t.Fatalf("expected status %d, got %d, body: %s", http.StatusOK, status, string(exported))
+		}
+		report = importTeams(t, "?format="+format, "application/octet-stream", string(exported))
+		if len(report.TeamsCreated)+len(report.UsersCreated)+len(report.UsersUpdated)+len(report.UsersMoved) != 0 || report.Unchanged != 4 {
+			t.Fatalf("%s: expected round trip without changes, got %+v, file: %s", format, report, string(exported))
+		}
+	}
+
+	// YAML по Content-Type
+	report = importTeams(t, "", "application/yaml", "teams:\n  - team_name: delta\n    members:\n      - user_id: u5\n        username: Eve\n        is_active: true\n")
+	if len(report.TeamsCreated) != 1 || len(report.UsersCreated) != 1 {
+		t.Fatalf("unexpected yaml import report: %+v", report)
+	}
+
+	// ошибки проверяются до транзакции, файл не применяется частично
+	status, body = postRaw(t, "/import/teams", "text/csv", []byte("alpha,u1,Alice,true\nomega,u6,,true\nomega,u1,Alice,maybe\n"))
+	if status != http.StatusBadRequest || errorCode(t, body) != "INVALID_INPUT" || !strings.Contains(string(body), "line 2") ||
+		!strings.Contains(string(body), "line 3") {
+		t.Fatalf("expected 400 INVALID_INPUT with all lines, got %d, body: %s", status, string(body))
+	}
+	if status, _ := getJSON(t, "/team/get?team_name=omega"); status != http.StatusNotFound {
+		t.Fatalf("invalid file must not create team, got %d", status)
+	}
+	status, body = getJSON(t, "/export/teams?format=xml")
+	if status != http.StatusBadRequest || errorCode(t, body) != "INVALID_INPUT" {
+		t.Fatalf("expected 400 INVALID_INPUT, got %d, body: %s", status, string(body))
+	}
+}
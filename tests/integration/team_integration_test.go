@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"io"
 	"net/http"
+	"slices"
 	"testing"
 )
 
@@ -232,3 +233,311 @@ func TestDeactivateTeam(t *testing.T) {
         })
     }
 }
+
+// тестируем настройки команды и их применение при создании PR
+func TestTeamSettings(t *testing.T) {
+	// сбрасываем бд
+	ResetDB()
+
+	// создаём команду заранее
+	teamPayload := map[string]interface{}{
+		"team_name": "settings_team",
+		"members": []map[string]interface{}{
+			{"user_id": "u1", "username": "Author", "is_active": true},
+			{"user_id": "u2", "username": "Second", "is_active": true},
+			{"user_id": "u3", "username": "Third", "is_active": true},
+		},
+	}
+	body, _ := json.Marshal(teamPayload)
+	resp, err := http.Post(baseURL+"/team/add", "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("failed to create team: %v", err)
+	}
+	resp.Body.Close()
+
+	// тест-кейсы
+	cases := []struct {
+		name       string
+		payload    map[string]interface{}
+		wantStatus int
+	}{
+		{"один_ревьювер", map[string]interface{}{"team_name": "settings_team", "reviewers_count": 1}, http.StatusOK},
+		{"минимум_больше_числа", map[string]interface{}{"team_name": "settings_team", "min_reviewers": 5}, http.StatusBadRequest},
+		{"неизвестная_стратегия", map[string]interface{}{"team_name": "settings_team", "strategy": "magic"}, http.StatusBadRequest},
+		{"одобрений_больше_ревьюверов", map[string]interface{}{"team_name": "settings_team", "approvals_required": 2}, http.StatusBadRequest},
+		{"не_существующая_команда", map[string]interface{}{"team_name": "unknown_team", "reviewers_count": 1}, http.StatusNotFound},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			reqBody, _ := json.Marshal(c.payload)
+			resp, err := http.Post(baseURL+"/team/settings", "application/json", bytes.NewBuffer(reqBody))
+			if err != nil {
+				t.Fatalf("request error: %v", err)
+			}
+			defer resp.Body.Close()
+
+			// assert
+			if resp.StatusCode != c.wantStatus {
+				bodyBytes, _ := io.ReadAll(resp.Body)
+				t.Fatalf("expected %d, got %d, body: %s", c.wantStatus, resp.StatusCode, string(bodyBytes))
+			}
+		})
+	}
+
+	// проверяем сохраненные настройки
+	resp, err = http.Get(baseURL + "/team/settings?team_name=settings_team")
+	if err != nil {
+		t.Fatalf("request error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var settingsResp struct {
+		Settings struct {
+			ReviewersCount int `json:"reviewers_count"`
+			MinReviewers   int `json:"min_reviewers"`
+		} `json:"settings"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&settingsResp); err != nil {
+		t.Fatalf("failed to decode settings json: %v", err)
+	}
+	if settingsResp.Settings.ReviewersCount != 1 || settingsResp.Settings.MinReviewers != 0 {
+		t.Fatalf("unexpected settings: %+v", settingsResp.Settings)
+	}
+
+	// PR должен получить ровно одного ревьювера
+	prBody, _ := json.Marshal(map[string]string{"pull_request_id": "pr-settings", "pull_request_name": "settings", "author_id": "u1"})
+	prResp, err := http.Post(baseURL+"/pullRequest/create", "application/json", bytes.NewBuffer(prBody))
+	if err != nil {
+		t.Fatalf("request error: %v", err)
+	}
+	defer prResp.Body.Close()
+
+	var prOK struct {
+		PR struct {
+			AssignedReviewers []string `json:"assigned_reviewers"`
+		} `json:"pr"`
+	}
+	if err := json.NewDecoder(prResp.Body).Decode(&prOK); err != nil {
+		t.Fatalf("failed to decode pr json: %v", err)
+	}
+	if len(prOK.PR.AssignedReviewers) != 1 {
+		t.Errorf("expected 1 reviewer, got %v", prOK.PR.AssignedReviewers)
+	}
+}
+
+// тестируем управление составом команды
+func TestTeamMembership(t *testing.T) {
+	// чистим базу
+	ResetDB()
+
+	postJSON(t, "/team/add", map[string]interface{}{
+		"team_name": "core",
+		"members": []map[string]interface{}{
+			{"user_id": "u1", "username": "Author", "is_active": true},
+			{"user_id": "u2", "username": "Bob", "is_active": true},
+			{"user_id": "u3", "username": "Carol", "is_active": true},
+		},
+	})
+	postJSON(t, "/team/add", map[string]interface{}{
+		"team_name": "ops",
+		"members": []map[string]interface{}{
+			{"user_id": "u7", "username": "Ops", "is_active": true},
+			{"user_id": "u8", "username": "Ops2", "is_active": true},
+		},
+	})
+
+	expect := func(status int, body []byte, wantStatus int, wantCode string) {
+		t.Helper()
+		if status != wantStatus || (wantCode != "" && errorCode(t, body) != wantCode) {
+			t.Fatalf("expected %d %s, got %d, body: %s", wantStatus, wantCode, status, string(body))
+		}
+	}
+	member := func(id string) []map[string]interface{} {
+		return []map[string]interface{}{{"user_id": id, "username": id, "is_active": true}}
+	}
+
+	status, body := postJSON(t, "/team/members/add", map[string]interface{}{"team_name": "core", "members": member("u4")})
+	expect(status, body, http.StatusOK, "")
+	status, body = postJSON(t, "/team/members/add", map[string]interface{}{"team_name": "core", "members": member("u4")})
+	expect(status, body, http.StatusConflict, "ALREADY_MEMBER")
+	// участник другой команды состоит в обеих, основной остается прежняя
+	status, body = postJSON(t, "/team/members/add", map[string]interface{}{"team_name": "core", "members": member("u7")})
+	expect(status, body, http.StatusOK, "")
+
+	// ревьюверы u2 и u3, после удаления u2 его ревью достается u4
+	postJSON(t, "/pullRequest/create", map[string]string{
+		"pull_request_id": "pr-members", "pull_request_name": "members", "author_id": "u1",
+	})
+	status, body = postJSON(t, "/team/members/remove", map[string]string{"team_name": "core", "user_id": "u2"})
+	expect(status, body, http.StatusOK, "")
+	var removed struct {
+		Reassigned int `json:"reassigned_reviews"`
+	}
+	if err := json.Unmarshal(body, &removed); err != nil || removed.Reassigned != 1 {
+		t.Fatalf("expected 1 reassigned review, got %s", string(body))
+	}
+	status, body = getJSON(t, "/users/getReview?user_id=u4")
+	expect(status, body, http.StatusOK, "")
+	var reviews struct {
+		PullRequests []struct {
+			PRID string `json:"pull_request_id"`
+		} `json:"pull_requests"`
+	}
+	if err := json.Unmarshal(body, &reviews); err != nil || len(reviews.PullRequests) != 1 {
+		t.Fatalf("expected u4 to review pr-members, got %s", string(body))
+	}
+	status, body = postJSON(t, "/team/members/remove", map[string]string{"team_name": "core", "user_id": "u2"})
+	expect(status, body, http.StatusConflict, "NOT_TEAM_MEMBER")
+
+	status, body = postJSON(t, "/team/members/move", map[string]string{"user_id": "u7", "team_name": "core"})
+	expect(status, body, http.StatusConflict, "ALREADY_MEMBER")
+	status, body = postJSON(t, "/team/members/move", map[string]string{"user_id": "u8", "team_name": "core"})
+	expect(status, body, http.StatusOK, "")
+	if !bytes.Contains(body, []byte(`"previous_team":"ops"`)) {
+		t.Fatalf("expected previous team ops, got %s", string(body))
+	}
+
+	status, body = postJSON(t, "/team/rename", map[string]string{"team_name": "core", "new_team_name": "ops"})
+	expect(status, body, http.StatusBadRequest, "TEAM_EXISTS")
+	status, body = postJSON(t, "/team/rename", map[string]string{"team_name": "core", "new_team_name": "platform"})
+	expect(status, body, http.StatusOK, "")
+	status, body = getJSON(t, "/team/get?team_name=platform")
+	expect(status, body, http.StatusOK, "")
+	var team struct {
+		Members []struct {
+			UserID string `json:"user_id"`
+		} `json:"members"`
+	}
+	if err := json.Unmarshal(body, &team); err != nil || len(team.Members) != 5 {
+		t.Fatalf("expected 5 members in renamed team, got %s", string(body))
+	}
+
+	status, body = postJSON(t, "/team/delete", map[string]string{"team_name": "platform"})
+	expect(status, body, http.StatusOK, "")
+	status, body = getJSON(t, "/team/get?team_name=platform")
+	expect(status, body, http.StatusNotFound, "NOT_FOUND")
+
+	// u7 остается в ops
+	status, body = getJSON(t, "/team/get?team_name=ops")
+	expect(status, body, http.StatusOK, "")
+	if !bytes.Contains(body, []byte(`"user_id":"u7"`)) || bytes.Contains(body, []byte(`"user_id":"u8"`)) {
+		t.Fatalf("expected only u7 in ops, got %s", string(body))
+	}
+
+	// смена команды попадает в историю пользователя
+	_, body = getJSON(t, "/users/history?user_id=u2")
+	if !bytes.Contains(body, []byte(`"USER_TEAM_CHANGED"`)) {
+		t.Fatalf("expected USER_TEAM_CHANGED event, got %s", string(body))
+	}
+}
+
+// деактивация команды, у которой есть открытые PR без ревьюверов (черновик, снятый ревьювер)
+func TestDeactivateTeamWithoutReviewers(t *testing.T) {
+	// чистим базу
+	ResetDB()
+
+	postJSON(t, "/team/add", map[string]interface{}{
+		"team_name": "drafts",
+		"members": []map[string]interface{}{
+			{"user_id": "u1", "username": "Author", "is_active": true},
+			{"user_id": "u2", "username": "Bob", "is_active": true},
+		},
+	})
+	postJSON(t, "/pullRequest/create", map[string]interface{}{"pull_request_id": "pr-draft", "pull_request_name": "draft", "author_id": "u1", "is_draft": true})
+	postJSON(t, "/pullRequest/create", map[string]interface{}{"pull_request_id": "pr-open", "pull_request_name": "open", "author_id": "u1"})
+	status, body := postJSON(t, "/pullRequest/reviewers/remove", map[string]string{"pull_request_id": "pr-open", "user_id": "u2"})
+	if status != http.StatusOK {
+		t.Fatalf("expected status %d, got %d, body: %s", http.StatusOK, status, string(body))
+	}
+
+	status, body = postJSON(t, "/team/deactivate", map[string]string{"team_name": "drafts"})
+	if status != http.StatusOK {
+		t.Fatalf("expected status %d, got %d, body: %s", http.StatusOK, status, string(body))
+	}
+	if reviewers := reviewersOf(t, "pr-draft"); len(reviewers) != 0 {
+		t.Fatalf("expected draft without reviewers, got %v", reviewers)
+	}
+}
+
+// деактивация команды, когда заменить неактивного ревьювера некем: ревью возвращается в ответе
+func TestDeactivateTeamReportsUnstaffed(t *testing.T) {
+	// чистим базу
+	ResetDB()
+
+	postJSON(t, "/team/add", map[string]interface{}{
+		"team_name": "unstaffed",
+		"members": []map[string]interface{}{
+			{"user_id": "u1", "username": "Author", "is_active": true},
+			{"user_id": "u2", "username": "Bob", "is_active": true},
+		},
+	})
+	postJSON(t, "/pullRequest/create", map[string]interface{}{"pull_request_id": "pr-1", "pull_request_name": "open", "author_id": "u1"})
+
+	status, body := postJSON(t, "/team/deactivate", map[string]string{"team_name": "unstaffed"})
+	if status != http.StatusOK {
+		t.Fatalf("expected status %d, got %d, body: %s", http.StatusOK, status, string(body))
+	}
+
+	var resp struct {
+		UnstaffedReviews []struct {
+			PRID   string `json:"pull_request_id"`
+			UserID string `json:"user_id"`
+		} `json:"unstaffed_reviews"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatalf("failed to decode json: %v", err)
+	}
+	if len(resp.UnstaffedReviews) != 1 || resp.UnstaffedReviews[0].PRID != "pr-1" || resp.UnstaffedReviews[0].UserID != "u2" {
+		t.Fatalf("expected unstaffed review of u2 on pr-1, got %s", string(body))
+	}
+	if reviewers := reviewersOf(t, "pr-1"); len(reviewers) != 1 || reviewers[0] != "u2" {
+		t.Fatalf("expected u2 to stay on pr-1, got %v", reviewers)
+	}
+}
+
+// перевод в другую команду передает открытые ревью в PR прежней команды ее участникам
+func TestMoveMemberReassignsReviews(t *testing.T) {
+	// чистим базу
+	ResetDB()
+
+	postJSON(t, "/team/add", map[string]interface{}{
+		"team_name": "core",
+		"members": []map[string]interface{}{
+			{"user_id": "u1", "username": "Author", "is_active": true},
+			{"user_id": "u2", "username": "Bob", "is_active": true},
+			{"user_id": "u3", "username": "Carol", "is_active": true},
+			{"user_id": "u4", "username": "Dave", "is_active": true},
+		},
+	})
+	postJSON(t, "/team/add", map[string]interface{}{
+		"team_name": "ops",
+		"members": []map[string]interface{}{
+			{"user_id": "u7", "username": "Ops", "is_active": true},
+		},
+	})
+	postJSON(t, "/pullRequest/create", map[string]string{
+		"pull_request_id": "pr-move", "pull_request_name": "move", "author_id": "u1",
+	})
+	before := reviewersOf(t, "pr-move")
+	if len(before) != 2 {
+		t.Fatalf("expected 2 reviewers, got %v", before)
+	}
+
+	status, body := postJSON(t, "/team/members/move", map[string]string{"user_id": before[0], "team_name": "ops"})
+	if status != http.StatusOK {
+		t.Fatalf("expected status %d, got %d, body: %s", http.StatusOK, status, string(body))
+	}
+	var moved struct {
+		Reassigned int      `json:"reassigned_reviews"`
+		Warnings   []string `json:"warnings"`
+	}
+	if err := json.Unmarshal(body, &moved); err != nil || moved.Reassigned != 1 || len(moved.Warnings) != 0 {
+		t.Fatalf("expected 1 reassigned review without warnings, got %s", string(body))
+	}
+
+	after := reviewersOf(t, "pr-move")
+	if len(after) != 2 || slices.Contains(after, before[0]) || !slices.Contains(after, before[1]) {
+		t.Fatalf("expected %s replaced and %s kept, got %v", before[0], before[1], after)
+	}
+}
@@ -0,0 +1,261 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"slices"
+	"testing"
+)
+
+type userDeletionResp struct {
+	ReassignedReviews int `json:"reassigned_reviews"`
+	UnassignedReviews int `json:"unassigned_reviews"`
+	TransferredPRs    int `json:"transferred_prs"`
+	OrphanedPRs       int `json:"orphaned_prs"`
+}
+
+func deleteUser(t *testing.T, payload map[string]string) userDeletionResp {
+	t.Helper()
+
+	status, body := postJSON(t, "/users/delete", payload)
+	if status != http.StatusOK {
+		t.Fatalf("expected status %d, got %d, body: %s", http.StatusOK, status, string(body))
+	}
+	var resp userDeletionResp
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatalf("failed to decode json: %v", err)
+	}
+	return resp
+}
+
+func TestUserLifecycle(t *testing.T) {
+	// чистим бд
+	ResetDB()
+
+	postJSON(t, "/team/add", map[string]interface{}{
+		"team_name": "lifecycle",
+		"members": []map[string]interface{}{
+			{"user_id": "lc1", "username": "Anna", "is_active": true},
+			{"user_id": "lc2", "username": "Boris", "is_active": true},
+			{"user_id": "lc3", "username": "Vera", "is_active": true},
+		},
+	})
+
+	// создание пользователя
+	status, body := postJSON(t, "/users/create", map[string]interface{}{
+		"user_id": "lc4", "username": "Gleb", "team_name": "lifecycle",
+	})
+	if status != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d, body: %s", http.StatusCreated, status, string(body))
+	}
+
+	status, body = postJSON(t, "/users/create", map[string]interface{}{
+		"user_id": "lc4", "username": "Gleb", "team_name": "lifecycle",
+	})
+	if status != http.StatusConflict || errorCode(t, body) != "USER_EXISTS" {
+		t.Fatalf("expected 409 USER_EXISTS, got %d, body: %s", status, string(body))
+	}
+
+	status, body = postJSON(t, "/users/create", map[string]interface{}{
+		"user_id": "lc5", "username": "Dina", "team_name": "missing",
+	})
+	if status != http.StatusNotFound || errorCode(t, body) != "NOT_FOUND" {
+		t.Fatalf("expected 404 NOT_FOUND, got %d, body: %s", status, string(body))
+	}
+
+	// изменение имени
+	status, body = postJSON(t, "/users/update", map[string]interface{}{"user_id": "lc4", "username": "Gleb K."})
+	if status != http.StatusOK {
+		t.Fatalf("expected status %d, got %d, body: %s", http.StatusOK, status, string(body))
+	}
+	var updated struct {
+		User struct {
+			Username string `json:"username"`
+			TeamName string `json:"team_name"`
+		} `json:"user"`
+	}
+	if err := json.Unmarshal(body, &updated); err != nil {
+		t.Fatalf("failed to decode json: %v", err)
+	}
+	if updated.User.Username != "Gleb K." || updated.User.TeamName != "lifecycle" {
+		t.Fatalf("unexpected user after update: %+v", updated.User)
+	}
+
+	// lc4 ревьюит PR lc1 (lc2 на это время выключен) и сам автор PR, ревьюером которого будет lc1
+	postJSON(t, "/users/setIsActive", map[string]interface{}{"user_id": "lc2", "is_active": false})
+	postJSON(t, "/pullRequest/create", map[string]string{
+		"pull_request_id": "pr-lc1", "pull_request_name": "pr-lc1", "author_id": "lc1",
+	})
+	postJSON(t, "/users/setIsActive", map[string]interface{}{"user_id": "lc2", "is_active": true})
+	postJSON(t, "/pullRequest/create", map[string]string{
+		"pull_request_id": "pr-lc4", "pull_request_name": "pr-lc4", "author_id": "lc4",
+	})
+	if reviewers := reviewersOf(t, "pr-lc1"); !slices.Contains(reviewers, "lc4") {
+		t.Fatalf("expected lc4 among reviewers of pr-lc1, got %v", reviewers)
+	}
+	if reviewers := reviewersOf(t, "pr-lc4"); !slices.Contains(reviewers, "lc1") {
+		t.Fatalf("expected lc1 among reviewers of pr-lc4, got %v", reviewers)
+	}
+
+	status, body = postJSON(t, "/users/delete", map[string]string{"user_id": "lc4", "transfer_to": "ghost"})
+	if status != http.StatusNotFound {
+		t.Fatalf("expected status %d, got %d, body: %s", http.StatusNotFound, status, string(body))
+	}
+
+	result := deleteUser(t, map[string]string{"user_id": "lc4", "transfer_to": "lc1"})
+	if result.ReassignedReviews != 1 || result.TransferredPRs != 1 || result.OrphanedPRs != 0 {
+		t.Fatalf("unexpected deletion result: %+v", result)
+	}
+
+	if reviewers := slices.Sorted(slices.Values(reviewersOf(t, "pr-lc1"))); !slices.Equal(reviewers, []string{"lc2", "lc3"}) {
+		t.Fatalf("expected lc4 replaced by lc2 on pr-lc1, got %v", reviewers)
+	}
+	transferred := listPRs(t, url.Values{"author_id": {"lc1"}})
+	if len(transferred.PullRequests) != 2 {
+		t.Fatalf("expected pr-lc4 transferred to lc1, got %+v", transferred.PullRequests)
+	}
+	if reviewers := reviewersOf(t, "pr-lc4"); slices.Contains(reviewers, "lc1") || len(reviewers) != 1 {
+		t.Fatalf("new author must be removed from reviewers, got %v", reviewers)
+	}
+
+	// повторное удаление и изменение удаленного пользователя
+	status, body = postJSON(t, "/users/delete", map[string]string{"user_id": "lc4"})
+	if status != http.StatusNotFound {
+		t.Fatalf("expected status %d, got %d, body: %s", http.StatusNotFound, status, string(body))
+	}
+	status, body = postJSON(t, "/users/update", map[string]interface{}{"user_id": "lc4", "username": "Gleb"})
+	if status != http.StatusNotFound {
+		t.Fatalf("expected status %d, got %d, body: %s", http.StatusNotFound, status, string(body))
+	}
+
+	// без transfer_to PR автора остаются без владельца
+	postJSON(t, "/pullRequest/create", map[string]string{
+		"pull_request_id": "pr-lc2", "pull_request_name": "pr-lc2", "author_id": "lc2",
+	})
+	result = deleteUser(t, map[string]string{"user_id": "lc2"})
+	if result.OrphanedPRs != 1 || result.TransferredPRs != 0 {
+		t.Fatalf("unexpected deletion result: %+v", result)
+	}
+
+	status, body = getJSON(t, "/pullRequest/list?author_id=lc2")
+	if status != http.StatusOK {
+		t.Fatalf("expected status %d, got %d, body: %s", http.StatusOK, status, string(body))
+	}
+	var orphaned struct {
+		PullRequests []struct {
+			Orphaned bool `json:"orphaned"`
+		} `json:"pull_requests"`
+	}
+	if err := json.Unmarshal(body, &orphaned); err != nil {
+		t.Fatalf("failed to decode json: %v", err)
+	}
+	if len(orphaned.PullRequests) != 1 || !orphaned.PullRequests[0].Orphaned {
+		t.Fatalf("expected orphaned pr-lc2, got %s", string(body))
+	}
+}
+
+// передачи ревью при удалении учитывают лимиты с поправкой на уже запланированные передачи
+func TestUserDeletionHandoffCapacity(t *testing.T) {
+	// чистим бд
+	ResetDB()
+
+	postJSON(t, "/team/add", map[string]interface{}{
+		"team_name": "handoff",
+		"members": []map[string]interface{}{
+			{"user_id": "h1", "username": "Author", "is_active": true},
+			{"user_id": "h9", "username": "Leaving", "is_active": true},
+		},
+	})
+	postJSON(t, "/team/settings", map[string]interface{}{"team_name": "handoff", "reviewers_count": 1})
+	for _, id := range []string{"pr-h1", "pr-h2"} {
+		postJSON(t, "/pullRequest/create", map[string]string{"pull_request_id": id, "pull_request_name": id, "author_id": "h1"})
+	}
+
+	postJSON(t, "/team/members/add", map[string]interface{}{
+		"team_name": "handoff",
+		"members": []map[string]interface{}{
+			{"user_id": "h2", "username": "Bob", "is_active": true},
+			{"user_id": "h3", "username": "Carol", "is_active": true},
+		},
+	})
+	for _, id := range []string{"h2", "h3"} {
+		status, body := postJSON(t, "/users/setCapacity", map[string]interface{}{"user_id": id, "max_open_reviews": 1})
+		if status != http.StatusOK {
+			t.Fatalf("expected status %d, got %d, body: %s", http.StatusOK, status, string(body))
+		}
+	}
+
+	result := deleteUser(t, map[string]string{"user_id": "h9"})
+	if result.ReassignedReviews != 2 || result.UnassignedReviews != 0 {
+		t.Fatalf("unexpected deletion result: %+v", result)
+	}
+	first, second := reviewersOf(t, "pr-h1"), reviewersOf(t, "pr-h2")
+	if len(first) != 1 || len(second) != 1 || first[0] == second[0] {
+		t.Fatalf("expected reviews split between h2 and h3, got %v and %v", first, second)
+	}
+}
+
+// reviewersOf возвращает текущих ревьюверов PR через список PR
+func reviewersOf(t *testing.T, prID string) []string {
+	t.Helper()
+
+	for _, pr := range listPRs(t, url.Values{"limit": {"200"}}).PullRequests {
+		if pr.PRID == prID {
+			return pr.AssignedReviewers
+		}
+	}
+	t.Fatalf("pull request %s not found", prID)
+	return nil
+}
+
+// смена команды через /users/update передает открытые ревью в PR прежней команды ее участникам
+func TestUpdateUserTeamReassignsReviews(t *testing.T) {
+	// чистим бд
+	ResetDB()
+
+	postJSON(t, "/team/add", map[string]interface{}{
+		"team_name": "lifecycle",
+		"members": []map[string]interface{}{
+			{"user_id": "lc1", "username": "Anna", "is_active": true},
+			{"user_id": "lc2", "username": "Boris", "is_active": true},
+			{"user_id": "lc3", "username": "Vera", "is_active": true},
+			{"user_id": "lc4", "username": "Gleb", "is_active": true},
+		},
+	})
+	postJSON(t, "/team/add", map[string]interface{}{
+		"team_name": "payments",
+		"members": []map[string]interface{}{
+			{"user_id": "pm1", "username": "Pavel", "is_active": true},
+		},
+	})
+	postJSON(t, "/pullRequest/create", map[string]string{
+		"pull_request_id": "pr-lc1", "pull_request_name": "pr-lc1", "author_id": "lc1",
+	})
+	before := reviewersOf(t, "pr-lc1")
+	if len(before) != 2 {
+		t.Fatalf("expected 2 reviewers, got %v", before)
+	}
+
+	status, body := postJSON(t, "/users/update", map[string]interface{}{"user_id": before[0], "team_name": "payments"})
+	if status != http.StatusOK {
+		t.Fatalf("expected status %d, got %d, body: %s", http.StatusOK, status, string(body))
+	}
+	var updated struct {
+		User struct {
+			TeamName string `json:"team_name"`
+		} `json:"user"`
+		ReassignedReviews int `json:"reassigned_reviews"`
+	}
+	if err := json.Unmarshal(body, &updated); err != nil {
+		t.Fatalf("failed to decode json: %v", err)
+	}
+	if updated.User.TeamName != "payments" || updated.ReassignedReviews != 1 {
+		t.Fatalf("expected move to payments with 1 reassigned review, got %s", string(body))
+	}
+
+	after := reviewersOf(t, "pr-lc1")
+	if len(after) != 2 || slices.Contains(after, before[0]) || !slices.Contains(after, before[1]) {
+		t.Fatalf("expected %s replaced and %s kept, got %v", before[0], before[1], after)
+	}
+}